@@ -0,0 +1,172 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/nspcc-dev/neo-go/pkg/consensus"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var (
+	errNoInput  = errors.New("no input file was found, specify an input file with the '--in or -i' flag")
+	errNoWallet = errors.New("no wallet was found, specify a wallet with the '--wallet or -w' flag")
+	errNoListen = errors.New("no address to listen on, specify it with the '--listen or -l' flag")
+	errNoSigned = errors.New("no signed blocks history file was specified, specify it with the '--signed or -s' flag")
+)
+
+// NewCommands returns 'consensus' command.
+func NewCommands() []cli.Command {
+	return []cli.Command{{
+		Name:  "consensus",
+		Usage: "consensus debugging tools",
+		Subcommands: []cli.Command{
+			{
+				Name:   "replay",
+				Usage:  "replay consensus journal printing dBFT state transitions",
+				Action: replayJournal,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "in, i",
+						Usage: "Consensus journal file",
+					},
+					cli.BoolFlag{
+						Name:  "debug, d",
+						Usage: "Print dBFT log messages",
+					},
+				},
+			},
+			{
+				Name:  "signer",
+				Usage: "run remote signer for validator keys",
+				UsageText: "neo-go consensus signer --wallet <path> --signed <path> --listen <address>\n\n" +
+					"   Address is a unix socket path in unix:/path/to/socket form, the socket\n" +
+					"   is only accessible by the user running the signer.",
+				Action: runSigner,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "wallet, w",
+						Usage: "Wallet with validator keys",
+					},
+					cli.StringFlag{
+						Name:  "signed, s",
+						Usage: "File to keep signed blocks history in (to prevent double signing after restarts)",
+					},
+					cli.StringFlag{
+						Name:  "listen, l",
+						Usage: "Unix socket to listen on (unix:/path/to/socket)",
+					},
+				},
+			},
+		},
+	}}
+}
+
+func replayJournal(ctx *cli.Context) error {
+	path := ctx.String("in")
+	if path == "" {
+		return cli.NewExitError(errNoInput, 1)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer f.Close()
+
+	entries, err := consensus.ReadJournal(f)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	log := zap.NewNop()
+	if ctx.Bool("debug") {
+		if log, err = zap.NewDevelopment(); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	if err := consensus.Replay(entries, ctx.App.Writer, log); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func runSigner(ctx *cli.Context) error {
+	path := ctx.String("wallet")
+	if path == "" {
+		return cli.NewExitError(errNoWallet, 1)
+	}
+	signed := ctx.String("signed")
+	if signed == "" {
+		return cli.NewExitError(errNoSigned, 1)
+	}
+	addr := ctx.String("listen")
+	if addr == "" {
+		return cli.NewExitError(errNoListen, 1)
+	}
+	_, address, err := consensus.ParseSignerAddress(addr)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	wall, err := wallet.NewWalletFromFile(path)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	pass, err := readPassword("Enter password > ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	log, err := zap.NewProduction()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	srv, err := consensus.NewSignerServer(consensus.NewWalletSigner(wall, pass), signed, log)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer srv.Close()
+
+	l, err := listenUnix(address)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		l.Close()
+	}()
+
+	log.Info("signer started", zap.String("address", addr))
+	if err := srv.Serve(l); err != nil && !isClosedErr(err) {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+// isClosedErr checks whether the error is caused by listener being closed.
+func isClosedErr(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
+
+func readPassword(prompt string) (string, error) {
+	fmt.Print(prompt)
+	rawPass, err := terminal.ReadPassword(syscall.Stdin)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(rawPass), "\n"), nil
+}
@@ -0,0 +1,12 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package consensus
+
+import "net"
+
+// listenUnix creates unix socket at the given path, there is no umask on
+// this platform, socket access is controlled by the directory it's in.
+func listenUnix(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,19 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package consensus
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnix creates unix socket at the given path that is only accessible
+// by the current user. Socket permissions are the only access control for
+// the signer, so they're set with umask on creation, there is no window
+// when the socket can be accessed by others.
+func listenUnix(path string) (net.Listener, error) {
+	mask := syscall.Umask(0077)
+	defer syscall.Umask(mask)
+	return net.Listen("unix", path)
+}
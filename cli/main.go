@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 
+	"github.com/nspcc-dev/neo-go/cli/consensus"
 	"github.com/nspcc-dev/neo-go/cli/server"
 	"github.com/nspcc-dev/neo-go/cli/smartcontract"
 	"github.com/nspcc-dev/neo-go/cli/vm"
@@ -21,6 +22,7 @@ func main() {
 	ctl.Commands = append(ctl.Commands, smartcontract.NewCommands()...)
 	ctl.Commands = append(ctl.Commands, wallet.NewCommands()...)
 	ctl.Commands = append(ctl.Commands, vm.NewCommands()...)
+	ctl.Commands = append(ctl.Commands, consensus.NewCommands()...)
 
 	if err := ctl.Run(os.Args); err != nil {
 		panic(err)
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"archive/zip"
+	"fmt"
+	gio "io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Block files use the format of the official NEO 2 packages. chain.acc has
+// blocks starting from the genesis: block count followed by (size, block)
+// pairs. chain.<start>.acc files have the height of the first block
+// before the count. Both can be zip-compressed (chain.acc.zip) with the only
+// entry named after the archive without the .zip suffix.
+var accStartRegexp = regexp.MustCompile(`^chain\.(\d+)\.acc(\.zip)?$`)
+
+// accStart returns the height of the first block for the file name given and
+// whether it's stored in the file.
+func accStart(path string) (uint32, bool) {
+	m := accStartRegexp.FindStringSubmatch(filepath.Base(path))
+	if m == nil {
+		return 0, false
+	}
+	start, err := strconv.ParseUint(m[1], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(start), true
+}
+
+// isZip checks whether the blocks file is compressed.
+func isZip(path string) bool {
+	return strings.HasSuffix(path, ".zip")
+}
+
+// zipEntryReader closes both the entry and the archive.
+type zipEntryReader struct {
+	gio.ReadCloser
+	archive *zip.ReadCloser
+}
+
+func (z *zipEntryReader) Close() error {
+	err := z.ReadCloser.Close()
+	if cerr := z.archive.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// openBlocksFile opens the file with blocks for reading, stdin is used if the
+// path is empty.
+func openBlocksFile(path string) (gio.ReadCloser, error) {
+	if path == "" {
+		return os.Stdin, nil
+	}
+	if !isZip(path) {
+		return os.Open(path)
+	}
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".zip")
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			_ = archive.Close()
+			return nil, err
+		}
+		return &zipEntryReader{ReadCloser: r, archive: archive}, nil
+	}
+	_ = archive.Close()
+	return nil, fmt.Errorf("no %s entry in %s", name, path)
+}
+
+// zipEntryWriter finishes the archive and closes the file on Close.
+type zipEntryWriter struct {
+	gio.Writer
+	archive *zip.Writer
+	file    *os.File
+}
+
+func (z *zipEntryWriter) Close() error {
+	err := z.archive.Close()
+	if cerr := z.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// createBlocksFile creates the file for writing blocks to, stdout is used if
+// the path is empty.
+func createBlocksFile(path string) (gio.WriteCloser, error) {
+	if path == "" {
+		return os.Stdout, nil
+	}
+	f, err := os.Create(path)
+	if err != nil || !isZip(path) {
+		return f, err
+	}
+	archive := zip.NewWriter(f)
+	w, err := archive.Create(strings.TrimSuffix(filepath.Base(path), ".zip"))
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &zipEntryWriter{Writer: w, archive: archive, file: f}, nil
+}
+
+// progressBarWidth is the width of the progress bar itself in characters.
+const progressBarWidth = 40
+
+// progress prints the import progress to the terminal.
+type progress struct {
+	out     *os.File
+	enabled bool
+	total   uint32
+	done    uint32
+	started time.Time
+	printed time.Time
+}
+
+func newProgress(total uint32) *progress {
+	now := time.Now()
+	return &progress{
+		out:     os.Stderr,
+		enabled: terminal.IsTerminal(int(os.Stderr.Fd())),
+		total:   total,
+		started: now,
+		printed: now,
+	}
+}
+
+// Add marks n more blocks as processed, the progress is printed at most once
+// a second.
+func (p *progress) Add(n uint32) {
+	p.done += n
+	if now := time.Now(); now.Sub(p.printed) >= time.Second {
+		p.printed = now
+		p.print()
+	}
+}
+
+// Finish prints the final progress state.
+func (p *progress) Finish() {
+	p.print()
+	if p.enabled {
+		fmt.Fprintln(p.out)
+	}
+}
+
+func (p *progress) print() {
+	if !p.enabled || p.total == 0 {
+		return
+	}
+	filled := int(uint64(p.done) * progressBarWidth / uint64(p.total))
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	var speed float64
+	if elapsed := time.Since(p.started).Seconds(); elapsed > 0 {
+		speed = float64(p.done) / elapsed
+	}
+	fmt.Fprintf(p.out, "\r[%s] %d/%d (%d%%) %.0f blocks/s", bar, p.done, p.total,
+		uint64(p.done)*100/uint64(p.total), speed)
+}
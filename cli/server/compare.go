@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// compareDB compares state roots of two nodes and finds the first block they
+// diverge at. State roots cover the whole chain history, so once they differ
+// they never match again and bisection can be used.
+func compareDB(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		return cli.NewExitError(errors.New("two RPC endpoints are required"), 1)
+	}
+	var clients [2]*client.Client
+	var height uint32
+	for i, endpoint := range args {
+		c, err := client.New(context.TODO(), endpoint, client.Options{})
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		count, err := c.GetBlockCount()
+		if err != nil {
+			return cli.NewExitError(errors.Wrapf(err, "failed to get %s block count", endpoint), 1)
+		}
+		if count == 0 {
+			return cli.NewExitError(fmt.Errorf("%s has no blocks", endpoint), 1)
+		}
+		if i == 0 || count-1 < height {
+			height = count - 1
+		}
+		clients[i] = c
+	}
+	if h := uint32(ctx.Uint("height")); h != 0 {
+		if h > height {
+			return cli.NewExitError(fmt.Errorf("height %d is above common height %d", h, height), 1)
+		}
+		height = h
+	}
+
+	roots, err := getStateRoots(clients, height)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if roots[0] == roots[1] {
+		fmt.Printf("States match up to block %d, state root %s\n", height, roots[1].StringLE())
+		return nil
+	}
+
+	// Roots differ at hi and match below lo.
+	var lo, hi = uint32(0), height
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		roots, err := getStateRoots(clients, mid)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		if roots[0] == roots[1] {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if roots, err = getStateRoots(clients, hi); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Printf("First diverging block: %d\n", hi)
+	for i, c := range clients {
+		hash, err := c.GetBlockHash(hi)
+		if err != nil {
+			return cli.NewExitError(errors.Wrapf(err, "failed to get %s block hash", args[i]), 1)
+		}
+		fmt.Printf("%s: block %s, state root %s\n", args[i], hash.StringLE(), roots[i].StringLE())
+	}
+	return cli.NewExitError(fmt.Errorf("states diverge at block %d", hi), 1)
+}
+
+// getStateRoots returns state roots of the block at the given height from
+// both nodes.
+func getStateRoots(clients [2]*client.Client, height uint32) ([2]util.Uint256, error) {
+	var roots [2]util.Uint256
+	for i, c := range clients {
+		res, err := c.GetStateRoot(height)
+		if err != nil {
+			return roots, errors.Wrapf(err, "failed to get state root of block %d", height)
+		}
+		roots[i] = res.StateRoot
+	}
+	return roots, nil
+}
@@ -47,7 +47,7 @@ func NewCommands() []cli.Command {
 		},
 		cli.StringFlag{
 			Name:  "out, o",
-			Usage: "Output file (stdout if not given)",
+			Usage: "Output file (stdout if not given), chain.<start>.acc names have the start height stored, .zip files are compressed",
 		},
 	)
 	var cfgCountInFlags = make([]cli.Flag, len(cfgWithCountFlags))
@@ -59,12 +59,16 @@ func NewCommands() []cli.Command {
 		},
 		cli.StringFlag{
 			Name:  "in, i",
-			Usage: "Input file (stdin if not given)",
+			Usage: "Input file (stdin if not given), chain.<start>.acc names have the start height stored, .zip files are compressed",
 		},
 		cli.StringFlag{
 			Name:  "dump",
 			Usage: "directory for storing JSON dumps",
 		},
+		cli.BoolFlag{
+			Name:  "verify",
+			Usage: "verify blocks being imported (VerifyBlocks setting is used if not given)",
+		},
 	)
 	return []cli.Command{
 		{
@@ -89,6 +93,18 @@ func NewCommands() []cli.Command {
 					Action: restoreDB,
 					Flags:  cfgCountInFlags,
 				},
+				{
+					Name:      "compare",
+					Usage:     "compare state roots of two nodes and find the first diverging block",
+					UsageText: "neo-go db compare [--height <height>] <endpoint> <endpoint>",
+					Action:    compareDB,
+					Flags: []cli.Flag{
+						cli.UintFlag{
+							Name:  "height",
+							Usage: "height to compare states at (default: the highest block both nodes have)",
+						},
+					},
+				},
 			},
 		},
 	}
@@ -179,15 +195,20 @@ func dumpDB(ctx *cli.Context) error {
 	}
 	count := uint32(ctx.Uint("count"))
 	start := uint32(ctx.Uint("start"))
-
-	var outStream = os.Stdout
-	if out := ctx.String("out"); out != "" {
-		outStream, err = os.Create(out)
-		if err != nil {
-			return cli.NewExitError(err, 1)
+	out := ctx.String("out")
+	fileStart, withStart := accStart(out)
+	if withStart {
+		if ctx.IsSet("start") && start != fileStart {
+			return cli.NewExitError(fmt.Errorf("start %d doesn't match the file name %s", start, out), 1)
 		}
+		start = fileStart
 	}
-	defer outStream.Close()
+
+	outStream, err := createBlocksFile(out)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer func() { _ = outStream.Close() }()
 	writer := io.NewBinWriterFromIO(outStream)
 
 	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log)
@@ -202,6 +223,9 @@ func dumpDB(ctx *cli.Context) error {
 	if count == 0 {
 		count = chainCount - start
 	}
+	if withStart {
+		writer.WriteU32LE(start)
+	}
 	writer.WriteU32LE(count)
 	for i := start; i < start+count; i++ {
 		bh := chain.GetHeaderHash(int(i))
@@ -215,9 +239,13 @@ func dumpDB(ctx *cli.Context) error {
 		writer.WriteU32LE(uint32(len(bytes)))
 		writer.WriteBytes(bytes)
 		if writer.Err != nil {
-			return cli.NewExitError(err, 1)
+			return cli.NewExitError(writer.Err, 1)
 		}
 	}
+	// Compressed files are only complete after closing.
+	if err := outStream.Close(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
 	pprof.ShutDown()
 	prometheus.ShutDown()
 	chain.Close()
@@ -236,12 +264,10 @@ func restoreDB(ctx *cli.Context) error {
 	count := uint32(ctx.Uint("count"))
 	skip := uint32(ctx.Uint("skip"))
 
-	var inStream = os.Stdin
-	if in := ctx.String("in"); in != "" {
-		inStream, err = os.Open(in)
-		if err != nil {
-			return cli.NewExitError(err, 1)
-		}
+	in := ctx.String("in")
+	inStream, err := openBlocksFile(in)
+	if err != nil {
+		return cli.NewExitError(err, 1)
 	}
 	defer inStream.Close()
 	reader := io.NewBinReaderFromIO(inStream)
@@ -250,6 +276,9 @@ func restoreDB(ctx *cli.Context) error {
 	if dumpDir != "" {
 		cfg.ProtocolConfiguration.SaveStorageBatch = true
 	}
+	if ctx.IsSet("verify") {
+		cfg.ProtocolConfiguration.VerifyBlocks = ctx.Bool("verify")
+	}
 
 	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log)
 	if err != nil {
@@ -259,9 +288,13 @@ func restoreDB(ctx *cli.Context) error {
 	defer prometheus.ShutDown()
 	defer pprof.ShutDown()
 
+	var start uint32
+	if _, ok := accStart(in); ok {
+		start = reader.ReadU32LE()
+	}
 	var allBlocks = reader.ReadU32LE()
 	if reader.Err != nil {
-		return cli.NewExitError(err, 1)
+		return cli.NewExitError(reader.Err, 1)
 	}
 	if skip+count > allBlocks {
 		return cli.NewExitError(fmt.Errorf("input file has only %d blocks, can't read %d starting from %d", allBlocks, count, skip), 1)
@@ -269,6 +302,12 @@ func restoreDB(ctx *cli.Context) error {
 	if count == 0 {
 		count = allBlocks - skip
 	}
+	// Blocks that are already in the chain are skipped, so an interrupted
+	// import can be restarted with the same parameters.
+	height := chain.BlockHeight()
+	if start+skip > height+1 {
+		return cli.NewExitError(fmt.Errorf("blocks starting from %d can't be added to the chain of height %d", start+skip, height), 1)
+	}
 	i := uint32(0)
 	for ; i < skip; i++ {
 		_, err := readBlock(reader)
@@ -283,6 +322,8 @@ func restoreDB(ctx *cli.Context) error {
 	defer func() {
 		_ = dump.tryPersist(dumpDir, lastIndex)
 	}()
+	bar := newProgress(count)
+	defer bar.Finish()
 
 	for ; i < skip+count; i++ {
 		select {
@@ -291,22 +332,31 @@ func restoreDB(ctx *cli.Context) error {
 		default:
 		}
 		bytes, err := readBlock(reader)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		bar.Add(1)
+		index := start + i
+		if index < height {
+			continue
+		}
 		block := &block.Block{}
 		newReader := io.NewBinReaderFromBuf(bytes)
 		block.DecodeBinary(newReader)
-		if err != nil {
-			return cli.NewExitError(err, 1)
+		if newReader.Err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to decode block %d: %s", index, newReader.Err), 1)
 		}
-		if block.Index == 0 && i == 0 && skip == 0 {
-			genesis, err := chain.GetBlock(block.Hash())
-			if err == nil && genesis.Index == 0 {
-				log.Info("skipped genesis block", zap.String("hash", block.Hash().StringLE()))
-				continue
+		if index == height {
+			// The last block we have must be the same one.
+			if !block.Hash().Equals(chain.GetHeaderHash(int(index))) {
+				return cli.NewExitError(fmt.Errorf("block %d doesn't match the one in the chain", index), 1)
 			}
+			log.Info("resuming import", zap.Uint32("height", height))
+			continue
 		}
 		err = chain.AddBlock(block)
 		if err != nil {
-			return cli.NewExitError(fmt.Errorf("failed to add block %d: %s", i, err), 1)
+			return cli.NewExitError(fmt.Errorf("failed to add block %d: %s", index, err), 1)
 		}
 
 		if dumpDir != "" {
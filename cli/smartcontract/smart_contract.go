@@ -16,7 +16,9 @@ import (
 	"github.com/go-yaml/yaml"
 	"github.com/nspcc-dev/neo-go/cli/flags"
 	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/decompiler"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/request"
@@ -53,7 +55,16 @@ var (
 	}
 	gasFlag = flags.Fixed8Flag{
 		Name:  "gas, g",
-		Usage: "gas to add to the transaction",
+		Usage: "gas to add to the transaction as a network fee (calculated by RPC node if not set)",
+	}
+	priorityFlag = cli.StringFlag{
+		Name:  "priority",
+		Value: "low",
+		Usage: "transaction priority to calculate network fee for if gas is not set (low or high)",
+	}
+	effectsFlag = cli.BoolFlag{
+		Name:  "effects",
+		Usage: "print contract calls, storage accesses, notifications and transfers of the invocation in a human-readable form",
 	}
 )
 
@@ -96,16 +107,20 @@ func NewCommands() []cli.Command {
 						Name:  "debug, d",
 						Usage: "Emit debug info in a separate file",
 					},
+					cli.StringFlag{
+						Name:  "syscalls",
+						Usage: "YAML file mapping stub package functions to custom interop names",
+					},
 				},
 			},
 			{
 				Name:  "deploy",
 				Usage: "deploy a smart contract (.avm with description)",
 				Description: `Deploys given contract into the chain. The gas parameter is for additional
-   gas to be added as a network fee to prioritize the transaction. It may also
-   be required to add that to satisfy chain's policy regarding transaction size
-   and the minimum size fee (so if transaction send fails, try adding 0.001 GAS
-   to it).
+   gas to be added as a network fee to prioritize the transaction. If it's not
+   set, network fee satisfying chain's policy regarding transaction size and
+   the minimum size fee is calculated by the RPC node for the given priority
+   (low by default).
 `,
 				Action: contractDeploy,
 				Flags: []cli.Flag{
@@ -121,12 +136,13 @@ func NewCommands() []cli.Command {
 					walletFlag,
 					addressFlag,
 					gasFlag,
+					priorityFlag,
 				},
 			},
 			{
 				Name:      "invoke",
 				Usage:     "invoke deployed contract on the blockchain",
-				UsageText: "neo-go contract invoke -e endpoint -w wallet [-a address] [-g gas] scripthash [arguments...]",
+				UsageText: "neo-go contract invoke -e endpoint -w wallet [-a address] [-g gas | --priority <low|high>] scripthash [arguments...]",
 				Description: `Executes given (as a script hash) deployed script with the given arguments.
    See testinvoke documentation for the details about parameters. It differs
    from testinvoke in that this command sends an invocation transaction to
@@ -138,12 +154,13 @@ func NewCommands() []cli.Command {
 					walletFlag,
 					addressFlag,
 					gasFlag,
+					priorityFlag,
 				},
 			},
 			{
 				Name:      "invokefunction",
 				Usage:     "invoke deployed contract on the blockchain",
-				UsageText: "neo-go contract invokefunction -e endpoint -w wallet [-a address] [-g gas] scripthash [method] [arguments...]",
+				UsageText: "neo-go contract invokefunction -e endpoint -w wallet [-a address] [-g gas | --priority <low|high>] scripthash [method] [arguments...]",
 				Description: `Executes given (as a script hash) deployed script with the given method and
    and arguments. See testinvokefunction documentation for the details about
    parameters. It differs from testinvokefunction in that this command sends an
@@ -155,6 +172,7 @@ func NewCommands() []cli.Command {
 					walletFlag,
 					addressFlag,
 					gasFlag,
+					priorityFlag,
 				},
 			},
 			{
@@ -175,6 +193,7 @@ func NewCommands() []cli.Command {
 				Action: testInvoke,
 				Flags: []cli.Flag{
 					endpointFlag,
+					effectsFlag,
 				},
 			},
 			{
@@ -246,6 +265,7 @@ func NewCommands() []cli.Command {
 				Action: testInvokeFunction,
 				Flags: []cli.Flag{
 					endpointFlag,
+					effectsFlag,
 				},
 			},
 			{
@@ -290,6 +310,22 @@ func NewCommands() []cli.Command {
 					},
 				},
 			},
+			{
+				Name:      "decompile",
+				Usage:     "convert .avm file or hex-encoded script into pseudo-Go code",
+				UsageText: "decompile {--in <file.avm> | <hex>} [--out <file.go>]",
+				Action:    decompile,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "in, i",
+						Usage: "Input .avm file",
+					},
+					cli.StringFlag{
+						Name:  "out, o",
+						Usage: "Output file (stdout if omitted)",
+					},
+				},
+			},
 		},
 	}}
 }
@@ -348,6 +384,11 @@ func contractCompile(ctx *cli.Context) error {
 	if len(src) == 0 {
 		return cli.NewExitError(errNoInput, 1)
 	}
+	if sf := ctx.String("syscalls"); sf != "" {
+		if err := registerSyscalls(sf); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
 
 	o := &compiler.Options{
 		Outfile: ctx.String("out"),
@@ -366,6 +407,27 @@ func contractCompile(ctx *cli.Context) error {
 	return nil
 }
 
+// registerSyscalls reads package -> function -> interop name mapping from
+// the given YAML file and registers it in the compiler.
+func registerSyscalls(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var syscalls map[string]map[string]string
+	if err := yaml.Unmarshal(data, &syscalls); err != nil {
+		return fmt.Errorf("bad syscalls file: %v", err)
+	}
+	for pkg, funcs := range syscalls {
+		for name, api := range funcs {
+			if err := compiler.RegisterSyscall(pkg, name, api); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func testInvoke(ctx *cli.Context) error {
 	return invokeInternal(ctx, false, false)
 }
@@ -385,7 +447,6 @@ func invokeFunction(ctx *cli.Context) error {
 func invokeInternal(ctx *cli.Context, withMethod bool, signAndPush bool) error {
 	var (
 		err         error
-		gas         util.Fixed8
 		operation   string
 		params      = make([]smartcontract.Parameter, 0)
 		paramsStart = 1
@@ -418,7 +479,6 @@ func invokeInternal(ctx *cli.Context, withMethod bool, signAndPush bool) error {
 	}
 
 	if signAndPush {
-		gas = flags.Fixed8FromContext(ctx, "gas")
 		acc, err = getAccFromContext(ctx)
 		if err != nil {
 			return err
@@ -429,9 +489,15 @@ func invokeInternal(ctx *cli.Context, withMethod bool, signAndPush bool) error {
 		return cli.NewExitError(err, 1)
 	}
 
-	if withMethod {
+	withEffects := !signAndPush && ctx.Bool("effects")
+	switch {
+	case withMethod && withEffects:
+		resp, err = c.InvokeFunctionWithEffects(script, operation, params)
+	case withMethod:
 		resp, err = c.InvokeFunction(script, operation, params)
-	} else {
+	case withEffects:
+		resp, err = c.InvokeWithEffects(script, params)
+	default:
 		resp, err = c.Invoke(script, params)
 	}
 	if err != nil {
@@ -445,23 +511,74 @@ func invokeInternal(ctx *cli.Context, withMethod bool, signAndPush bool) error {
 		if err != nil {
 			return cli.NewExitError(fmt.Errorf("bad script returned from the RPC node: %v", err), 1)
 		}
-		txHash, err := c.SignAndPushInvocationTx(script, acc, 0, gas)
+		txHash, err := signAndPushInvocationTx(ctx, c, script, acc, 0)
 		if err != nil {
 			return cli.NewExitError(fmt.Errorf("failed to push invocation tx: %v", err), 1)
 		}
 		fmt.Printf("Sent invocation transaction %s\n", txHash.StringLE())
 	} else {
+		effects := resp.Effects
+		resp.Effects = nil
 		b, err := json.MarshalIndent(resp, "", "  ")
 		if err != nil {
 			return cli.NewExitError(err, 1)
 		}
 
 		fmt.Println(string(b))
+		if withEffects {
+			if effects == nil {
+				return cli.NewExitError(errors.New("RPC node didn't return invocation effects"), 1)
+			}
+			printEffects(effects)
+		}
 	}
 
 	return nil
 }
 
+// printEffects prints invocation side effects in a human-readable form.
+func printEffects(e *result.InvokeEffects) {
+	fmt.Println("Calls:")
+	for _, c := range e.Calls {
+		var dynamic string
+		if c.Dynamic {
+			dynamic = " (dynamic)"
+		}
+		fmt.Printf("  %s -> %s%s\n", c.Caller.StringLE(), c.Callee.StringLE(), dynamic)
+	}
+	fmt.Println("Storage:")
+	for _, s := range e.Storage {
+		fmt.Printf("  %s:\n", s.Contract.StringLE())
+		for _, keys := range []struct {
+			name string
+			list []string
+		}{{"read", s.Read}, {"write", s.Write}, {"delete", s.Delete}, {"find", s.Find}} {
+			if len(keys.list) != 0 {
+				fmt.Printf("    %-7s %s\n", keys.name+":", strings.Join(keys.list, ", "))
+			}
+		}
+	}
+	fmt.Println("Notifications:")
+	for _, n := range e.Notifications {
+		b, err := json.Marshal(n.Item)
+		if err != nil {
+			b = []byte(err.Error())
+		}
+		fmt.Printf("  %s: %s\n", n.Contract.StringLE(), b)
+	}
+	fmt.Println("Transfers:")
+	for _, t := range e.Transfers {
+		from, to := t.From, t.To
+		if from == "" {
+			from = "(mint)"
+		}
+		if to == "" {
+			to = "(burn)"
+		}
+		fmt.Printf("  %s of %s: %s -> %s\n", t.Amount, t.Asset.StringLE(), from, to)
+	}
+}
+
 func testInvokeScript(ctx *cli.Context) error {
 	src := ctx.String("in")
 	if len(src) == 0 {
@@ -549,6 +666,38 @@ func inspect(ctx *cli.Context) error {
 	return nil
 }
 
+func decompile(ctx *cli.Context) error {
+	var (
+		prog []byte
+		err  error
+	)
+	switch in := ctx.String("in"); {
+	case in != "" && ctx.NArg() != 0:
+		return cli.NewExitError(errors.New("either input file or hex script should be given"), 1)
+	case in != "":
+		prog, err = ioutil.ReadFile(in)
+	case ctx.NArg() == 1:
+		prog, err = hex.DecodeString(ctx.Args().First())
+	default:
+		return cli.NewExitError(errNoInput, 1)
+	}
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	buf := new(bytes.Buffer)
+	if err := decompiler.Decompile(buf, prog); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if out := ctx.String("out"); out != "" {
+		if err := ioutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	}
+	fmt.Print(buf.String())
+	return nil
+}
+
 func getAccFromContext(ctx *cli.Context) (*wallet.Account, error) {
 	var addr util.Uint160
 
@@ -579,13 +728,36 @@ func getAccFromContext(ctx *cli.Context) (*wallet.Account, error) {
 		return nil, cli.NewExitError(err, 1)
 	}
 	pass := strings.TrimRight(string(rawPass), "\n")
-	err = acc.Decrypt(pass)
+	err = acc.DecryptWithParams(pass, wall.Scrypt)
 	if err != nil {
 		return nil, cli.NewExitError(err, 1)
 	}
 	return acc, nil
 }
 
+// signAndPushInvocationTx creates invocation transaction for the script with
+// the given system fee and network fee specified by gas flag or calculated by
+// RPC node, signs it with acc and sends to the network.
+func signAndPushInvocationTx(ctx *cli.Context, c *client.Client, script []byte, acc *wallet.Account, sysfee util.Fixed8) (util.Uint256, error) {
+	if ctx.IsSet("gas") {
+		return c.SignAndPushInvocationTx(script, acc, sysfee, flags.Fixed8FromContext(ctx, "gas"))
+	}
+	prio, err := client.GetFeePriority(ctx.String("priority"))
+	if err != nil {
+		return util.Uint256{}, err
+	}
+	tx, err := c.CreateTxWithNetworkFee(acc.Contract, prio, func(netfee util.Fixed8) (*transaction.Transaction, error) {
+		return c.CreateInvocationTx(script, acc, sysfee, netfee)
+	})
+	if err != nil {
+		return util.Uint256{}, err
+	}
+	if err := acc.SignTx(tx); err != nil {
+		return util.Uint256{}, errors.Wrap(err, "failed to sign tx")
+	}
+	return tx.Hash(), c.SendRawTransaction(tx)
+}
+
 // contractDeploy deploys contract.
 func contractDeploy(ctx *cli.Context) error {
 	in := ctx.String("in")
@@ -600,8 +772,6 @@ func contractDeploy(ctx *cli.Context) error {
 	if len(endpoint) == 0 {
 		return cli.NewExitError(errNoEndpoint, 1)
 	}
-	gas := flags.Fixed8FromContext(ctx, "gas")
-
 	acc, err := getAccFromContext(ctx)
 	if err != nil {
 		return err
@@ -633,7 +803,7 @@ func contractDeploy(ctx *cli.Context) error {
 
 	sysfee := smartcontract.GetDeploymentPrice(request.DetailsToSCProperties(&conf.Contract))
 
-	txHash, err := c.SignAndPushInvocationTx(txScript, acc, sysfee, gas)
+	txHash, err := signAndPushInvocationTx(ctx, c, txScript, acc, sysfee)
 	if err != nil {
 		return cli.NewExitError(fmt.Errorf("failed to push invocation tx: %v", err), 1)
 	}
@@ -1,6 +1,14 @@
 package vm
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm/asm"
 	vmcli "github.com/nspcc-dev/neo-go/pkg/vm/cli"
 	"github.com/urfave/cli"
 )
@@ -14,6 +22,40 @@ func NewCommands() []cli.Command {
 		Flags: []cli.Flag{
 			cli.BoolFlag{Name: "debug, d"},
 		},
+		Subcommands: []cli.Command{
+			{
+				Name:      "asm",
+				Usage:     "assemble NeoVM assembly program into .avm file",
+				UsageText: "asm --in <file.asm> --out <file.avm>",
+				Action:    assemble,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "in, i",
+						Usage: "Input assembly file",
+					},
+					cli.StringFlag{
+						Name:  "out, o",
+						Usage: "Output .avm file (hex is printed to stdout if omitted)",
+					},
+				},
+			},
+			{
+				Name:      "disasm",
+				Usage:     "disassemble .avm file or hex-encoded script",
+				UsageText: "disasm {--in <file.avm> | <hex>} [--out <file.asm>]",
+				Action:    disassemble,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "in, i",
+						Usage: "Input .avm file",
+					},
+					cli.StringFlag{
+						Name:  "out, o",
+						Usage: "Output assembly file (stdout if omitted)",
+					},
+				},
+			},
+		},
 	}}
 }
 
@@ -21,3 +63,59 @@ func startVMPrompt(ctx *cli.Context) error {
 	p := vmcli.New()
 	return p.Run()
 }
+
+func assemble(ctx *cli.Context) error {
+	in := ctx.String("in")
+	if in == "" {
+		return cli.NewExitError(errors.New("no input file was given"), 1)
+	}
+	f, err := os.Open(in)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer f.Close()
+	prog, err := asm.Assemble(f)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if out := ctx.String("out"); out != "" {
+		if err := ioutil.WriteFile(out, prog, 0644); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	}
+	fmt.Println(hex.EncodeToString(prog))
+	return nil
+}
+
+func disassemble(ctx *cli.Context) error {
+	var (
+		prog []byte
+		err  error
+	)
+	switch in := ctx.String("in"); {
+	case in != "" && ctx.NArg() != 0:
+		return cli.NewExitError(errors.New("either input file or hex script should be given"), 1)
+	case in != "":
+		prog, err = ioutil.ReadFile(in)
+	case ctx.NArg() == 1:
+		prog, err = hex.DecodeString(ctx.Args().First())
+	default:
+		return cli.NewExitError(errors.New("no input was given"), 1)
+	}
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	buf := new(bytes.Buffer)
+	if err := asm.Disassemble(buf, prog); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if out := ctx.String("out"); out != "" {
+		if err := ioutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	}
+	fmt.Print(buf.String())
+	return nil
+}
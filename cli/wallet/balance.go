@@ -0,0 +1,241 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+)
+
+// balance holds NEO, GAS, unclaimed GAS and NEP5 token balances.
+type balance struct {
+	NEO       util.Fixed8    `json:"neo"`
+	GAS       util.Fixed8    `json:"gas"`
+	Unclaimed util.Fixed8    `json:"unclaimed"`
+	Tokens    []tokenBalance `json:"tokens"`
+}
+
+// tokenBalance is a balance of one NEP5 token.
+type tokenBalance struct {
+	Hash   util.Uint160 `json:"hash"`
+	Symbol string       `json:"symbol"`
+	Amount string       `json:"amount"`
+}
+
+// accountBalance is a balance of one wallet account.
+type accountBalance struct {
+	Address string `json:"address"`
+	Label   string `json:"label,omitempty"`
+	Type    string `json:"type"`
+	balance
+}
+
+// walletBalance is a balance of all wallet accounts.
+type walletBalance struct {
+	Accounts []accountBalance `json:"accounts"`
+	Total    balance          `json:"total"`
+}
+
+func showBalance(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	gctx, cancel := getGoContext(ctx)
+	defer cancel()
+
+	c, err := client.New(gctx, ctx.String("rpc"), client.Options{})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	res, err := getWalletBalance(c, wall)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if ctx.Bool("json") {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	for i := range res.Accounts {
+		acc := &res.Accounts[i]
+		if acc.Label != "" {
+			fmt.Printf("%s (%s) [%s]\n", acc.Address, acc.Label, acc.Type)
+		} else {
+			fmt.Printf("%s [%s]\n", acc.Address, acc.Type)
+		}
+		printBalance(&acc.balance)
+	}
+	fmt.Println("Total:")
+	printBalance(&res.Total)
+	return nil
+}
+
+func printBalance(b *balance) {
+	fmt.Printf("\tNEO: %s\n", b.NEO)
+	fmt.Printf("\tGAS: %s\n", b.GAS)
+	fmt.Printf("\tUnclaimed GAS: %s\n", b.Unclaimed)
+	for _, t := range b.Tokens {
+		fmt.Printf("\t%s: %s (%s)\n", t.Symbol, t.Amount, t.Hash.StringLE())
+	}
+}
+
+// getWalletBalance queries balances of all wallet accounts via RPC.
+func getWalletBalance(c *client.Client, wall *wallet.Wallet) (*walletBalance, error) {
+	var (
+		res    = &walletBalance{Accounts: make([]accountBalance, 0, len(wall.Accounts))}
+		tokens = make(map[util.Uint160]*wallet.Token)
+		totals = make(map[util.Uint160]*big.Int)
+		order  []util.Uint160
+	)
+	for _, t := range wall.Extra.Tokens {
+		tokens[t.Hash] = t
+	}
+	for _, acc := range wall.Accounts {
+		h, err := address.StringToUint160(acc.Address)
+		if err != nil {
+			return nil, fmt.Errorf("bad account address %s: %v", acc.Address, err)
+		}
+		ab := accountBalance{
+			Address: acc.Address,
+			Label:   acc.Label,
+			Type:    getAccountType(acc),
+		}
+
+		unspents, err := c.GetUnspents(acc.Address)
+		if err != nil {
+			return nil, fmt.Errorf("can't get unspents for %s: %v", acc.Address, err)
+		}
+		for _, ub := range unspents.Balance {
+			switch {
+			case ub.AssetHash.Equals(core.GoverningTokenID()):
+				ab.NEO += ub.Amount
+			case ub.AssetHash.Equals(core.UtilityTokenID()):
+				ab.GAS += ub.Amount
+			}
+		}
+		unclaimed, err := c.GetUnclaimed(acc.Address)
+		if err != nil {
+			return nil, fmt.Errorf("can't get unclaimed GAS for %s: %v", acc.Address, err)
+		}
+		ab.Unclaimed = unclaimed.Unclaimed
+
+		nep5, err := c.GetNEP5Balances(h)
+		if err != nil {
+			return nil, fmt.Errorf("can't get NEP5 balances for %s: %v", acc.Address, err)
+		}
+		ab.Tokens = make([]tokenBalance, 0, len(nep5.Balances))
+		for _, nb := range nep5.Balances {
+			token, ok := tokens[nb.Asset]
+			if !ok {
+				token, err = c.NEP5TokenInfo(nb.Asset)
+				if err != nil {
+					return nil, fmt.Errorf("can't get token %s info: %v", nb.Asset.StringLE(), err)
+				}
+				tokens[nb.Asset] = token
+			}
+			amount, err := parseFixedN(nb.Amount, int(token.Decimals))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s amount: %v", token.Symbol, err)
+			}
+			if _, ok := totals[nb.Asset]; !ok {
+				order = append(order, nb.Asset)
+				totals[nb.Asset] = new(big.Int)
+			}
+			totals[nb.Asset].Add(totals[nb.Asset], amount)
+			ab.Tokens = append(ab.Tokens, tokenBalance{
+				Hash:   nb.Asset,
+				Symbol: token.Symbol,
+				Amount: fixedNToString(amount, int(token.Decimals)),
+			})
+		}
+
+		res.Total.NEO += ab.NEO
+		res.Total.GAS += ab.GAS
+		res.Total.Unclaimed += ab.Unclaimed
+		res.Accounts = append(res.Accounts, ab)
+	}
+	res.Total.Tokens = make([]tokenBalance, 0, len(order))
+	for _, h := range order {
+		res.Total.Tokens = append(res.Total.Tokens, tokenBalance{
+			Hash:   h,
+			Symbol: tokens[h].Symbol,
+			Amount: fixedNToString(totals[h], int(tokens[h].Decimals)),
+		})
+	}
+	return res, nil
+}
+
+func getAccountType(acc *wallet.Account) string {
+	switch {
+	case acc.IsWatchOnly():
+		return "watch-only"
+	case acc.Contract == nil:
+		return "unknown"
+	case vm.IsSignatureContract(acc.Contract.Script):
+		return "standard"
+	case vm.IsMultiSigContract(acc.Contract.Script):
+		return "multisig"
+	default:
+		return "contract"
+	}
+}
+
+// parseFixedN parses decimal amount with the given number of decimals into
+// the number of token units. Token amounts are not limited, so they can't be
+// stored in int64.
+func parseFixedN(s string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid number of decimals %d", decimals)
+	}
+	parts := strings.SplitN(s, ".", 2)
+	var frac string
+	if len(parts) == 2 {
+		frac = strings.TrimRight(parts[1], "0")
+		if parts[1] == "" || len(frac) > decimals {
+			return nil, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+	if parts[0] == "" || parts[0] == "-" || strings.HasPrefix(parts[0], "+") {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	amount, ok := new(big.Int).SetString(parts[0]+frac+strings.Repeat("0", decimals-len(frac)), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, nil
+}
+
+// fixedNToString formats FixedN amount with the given number of decimals.
+func fixedNToString(amount *big.Int, decimals int) string {
+	var sign string
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(amount)
+	if decimals == 0 {
+		return sign + abs.String()
+	}
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	q, r := new(big.Int).QuoRem(abs, pow, new(big.Int))
+	if r.Sign() == 0 {
+		return sign + q.String()
+	}
+	frac := r.String()
+	frac = strings.TrimRight(strings.Repeat("0", decimals-len(frac))+frac, "0")
+	return sign + q.String() + "." + frac
+}
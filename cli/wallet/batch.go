@@ -0,0 +1,284 @@
+package wallet
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+)
+
+// freeGasLimit is the amount of GAS invocations can spend for free
+// (FreeGasLimit setting of public networks).
+var freeGasLimit = util.Fixed8FromInt64(10)
+
+// paymentRecord is a single line of payments file.
+type paymentRecord struct {
+	Address string `json:"address"`
+	Asset   string `json:"asset"`
+	Amount  string `json:"amount"`
+}
+
+func newTransferBatchCommand() cli.Command {
+	return cli.Command{
+		Name:  "transfer-batch",
+		Usage: "make multiple payments with as few transactions as possible",
+		UsageText: "transfer-batch --path <path> --rpc <node> --from <addr> --in <payments.csv|payments.json>" +
+			" [--max-size <bytes>] [--gas <amount>] [--netfee <amount> | --priority <low|high>]" +
+			" [--coins <strategy>] [--dry-run]",
+		Description: `Payments file is either CSV with 'address,asset,amount' lines or JSON
+   array of {"address": ..., "asset": ..., "amount": ...} objects (the file
+   must have .json extension). Asset is NEO, GAS, UTXO asset ID or NEP5
+   token name, symbol or hash. UTXO assets are sent with contract
+   transactions each fitting into --max-size bytes, all NEP5 transfers are
+   made by one invocation transaction paying network fee given by --netfee
+   or calculated by RPC node for the --priority. NEP5 transfers are tested
+   with the RPC node first, the command fails if they fail there. GAS they
+   consume above the free limit (10 GAS) is attached to the transaction
+   as its system fee unless it's given explicitly with --gas.`,
+		Action: transferBatch,
+		Flags: []cli.Flag{
+			walletPathFlag,
+			rpcFlag,
+			timeoutFlag,
+			fromAddrFlag,
+			coinsFlag,
+			cli.StringFlag{
+				Name:  "in",
+				Usage: "File with payments",
+			},
+			cli.IntFlag{
+				Name:  "max-size",
+				Value: 1024,
+				Usage: "Maximum transaction size without network fee (node's MaxFreeTransactionSize)",
+			},
+			flags.Fixed8Flag{
+				Name:  "gas",
+				Usage: "Amount of GAS to attach to NEP5 transfers transaction (calculated by RPC node by default)",
+			},
+			netfeeFlag,
+			priorityFlag,
+			cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only print transactions and fees, don't send anything",
+			},
+		},
+	}
+}
+
+func transferBatch(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	fromFlag := ctx.Generic("from").(*flags.Address)
+	if !fromFlag.IsSet {
+		return cli.NewExitError("'from' address was not provided", 1)
+	}
+	from := fromFlag.Uint160()
+	acc := wall.GetAccount(from)
+	if acc == nil {
+		return cli.NewExitError(fmt.Errorf("wallet contains no account for '%s'", fromFlag), 1)
+	}
+	records, err := readPayments(ctx.String("in"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	gctx, cancel := getGoContext(ctx)
+	defer cancel()
+
+	c, err := client.New(gctx, ctx.String("rpc"), client.Options{})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	} else if err := setCoinSelector(ctx, c); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var (
+		assetPayments []client.AssetPayment
+		tokenPayments []client.TokenPayment
+		tokens        = make(map[string]*wallet.Token)
+	)
+	for i, r := range records {
+		to, err := address.StringToUint160(r.Address)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("payment %d: invalid address: %v", i, err), 1)
+		}
+		if asset, err := getAssetID(r.Asset); err == nil {
+			amount, err := util.Fixed8FromString(r.Amount)
+			if err != nil {
+				return cli.NewExitError(fmt.Errorf("payment %d: invalid amount: %v", i, err), 1)
+			}
+			assetPayments = append(assetPayments, client.AssetPayment{To: to, Asset: asset, Amount: amount})
+			continue
+		}
+		token, ok := tokens[r.Asset]
+		if !ok {
+			if token, err = getMatchingToken(wall, r.Asset); err != nil {
+				if token, err = getMatchingTokenRPC(c, from, r.Asset); err != nil {
+					return cli.NewExitError(fmt.Errorf("payment %d: %v", i, err), 1)
+				}
+			}
+			tokens[r.Asset] = token
+		}
+		amount, err := util.FixedNFromString(r.Amount, int(token.Decimals))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("payment %d: invalid amount: %v", i, err), 1)
+		}
+		tokenPayments = append(tokenPayments, client.TokenPayment{To: to, Token: token.Hash, Amount: amount})
+	}
+
+	batch, err := c.NewBatch(acc)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	maxSize := ctx.Int("max-size")
+	var txs []*transaction.Transaction
+	if len(assetPayments) != 0 {
+		if txs, err = batch.CreateContractTxs(assetPayments, maxSize); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	if len(tokenPayments) != 0 {
+		sysfee, err := getBatchNEP5SystemFee(c, from, tokenPayments)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		if ctx.IsSet("gas") {
+			sysfee = flags.Fixed8FromContext(ctx, "gas")
+		}
+		tx, err := createTxWithFee(ctx, c, acc, func(netfee util.Fixed8) (*transaction.Transaction, error) {
+			return batch.CreateNEP5Tx(tokenPayments, sysfee, netfee)
+		})
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		txs = append(txs, tx)
+	}
+
+	if ctx.Bool("dry-run") {
+		prio, err := client.GetFeePriority(ctx.String("priority"))
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		var total, totalSys util.Fixed8
+		for i, tx := range txs {
+			size := client.EstimateTxSize(tx, acc.Contract)
+			fee, err := c.EstimateNetworkFee(tx, acc.Contract, prio)
+			if err != nil {
+				return cli.NewExitError(fmt.Errorf("can't estimate network fee: %v", err), 1)
+			}
+			var sysfee util.Fixed8
+			if inv, ok := tx.Data.(*transaction.InvocationTX); ok {
+				sysfee = inv.Gas
+			}
+			total += fee
+			totalSys += sysfee
+			fmt.Printf("Transaction %d: %s, %d inputs, %d outputs, %d bytes, network fee %s, system fee %s\n",
+				i, tx.Type, len(tx.Inputs), len(tx.Outputs), size, fee, sysfee)
+		}
+		fmt.Printf("Total: %d payments, %d transactions, network fee %s, system fee %s\n",
+			len(records), len(txs), total, totalSys)
+		return nil
+	}
+
+	lock, err := unlockAccount(wall, acc, "Enter wallet password > ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer lock()
+
+	for _, tx := range txs {
+		if err := acc.SignTx(tx); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	for _, tx := range txs {
+		if err := c.SendRawTransaction(tx); err != nil {
+			return cli.NewExitError(fmt.Errorf("can't send %s: %v", tx.Hash().StringLE(), err), 1)
+		}
+		fmt.Println(tx.Hash().StringLE())
+	}
+	return nil
+}
+
+// getBatchNEP5SystemFee test-invokes NEP5 transfers from the given account
+// and returns the system fee needed for them, it's the amount of GAS consumed
+// above the free limit rounded up to whole GAS.
+func getBatchNEP5SystemFee(c *client.Client, from util.Uint160, payments []client.TokenPayment) (util.Fixed8, error) {
+	script, err := client.CreateBatchNEP5Script(from, payments)
+	if err != nil {
+		return 0, err
+	}
+	res, err := c.InvokeScriptWithHashes(hex.EncodeToString(script), []util.Uint160{from})
+	if err != nil {
+		return 0, fmt.Errorf("can't test NEP5 transfers: %v", err)
+	}
+	if res.State != "HALT" {
+		return 0, fmt.Errorf("NEP5 transfers fail with %s VM state", res.State)
+	}
+	consumed, err := util.Fixed8FromString(res.GasConsumed)
+	if err != nil {
+		return 0, fmt.Errorf("invalid GAS consumed: %v", err)
+	}
+	sysfee := consumed - freeGasLimit
+	if sysfee <= 0 {
+		return 0, nil
+	}
+	if sysfee.FractionalValue() != 0 {
+		sysfee = util.Fixed8FromInt64(sysfee.IntegralValue() + 1)
+	}
+	return sysfee, nil
+}
+
+// readPayments reads payments from CSV or JSON file.
+func readPayments(filename string) ([]paymentRecord, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("can't read payments: %v", err)
+	}
+	defer f.Close()
+
+	var records []paymentRecord
+	if strings.ToLower(filepath.Ext(filename)) == ".json" {
+		if err := json.NewDecoder(f).Decode(&records); err != nil {
+			return nil, fmt.Errorf("can't parse payments: %v", err)
+		}
+	} else {
+		r := csv.NewReader(f)
+		r.FieldsPerRecord = 3
+		r.TrimLeadingSpace = true
+		r.Comment = '#'
+		for {
+			line, err := r.Read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("can't parse payments: %v", err)
+			}
+			if len(records) == 0 && strings.EqualFold(line[0], "address") {
+				continue // header
+			}
+			records = append(records, paymentRecord{Address: line[0], Asset: line[1], Amount: line[2]})
+		}
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no payments found")
+	}
+	return records, nil
+}
@@ -0,0 +1,117 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+)
+
+func newGenerateCommand() cli.Command {
+	return cli.Command{
+		Name:      "generate",
+		Usage:     "generate a lot of accounts at once",
+		UsageText: "generate --path <path> --count <n> [--prefix <prefix>...] [--workers <n>] [--name <name>] [--timeout <duration>]",
+		Description: `Generates keys and encrypts them with the wallet scrypt parameters in
+   parallel, all accounts are encrypted with the same passphrase and the
+   wallet is saved once. If prefixes are given, only addresses starting with
+   any of them are accepted, every additional character makes the search ~58
+   times longer. Generation can be interrupted with Ctrl+C or --timeout, the
+   accounts generated by then are saved.`,
+		Action: generateAccounts,
+		Flags: []cli.Flag{
+			walletPathFlag,
+			timeoutFlag,
+			cli.IntFlag{
+				Name:  "count, c",
+				Usage: "Number of accounts to generate",
+				Value: 1,
+			},
+			cli.StringSliceFlag{
+				Name:  "prefix",
+				Usage: "Address prefix to search for (can be repeated)",
+			},
+			cli.IntFlag{
+				Name:  "workers",
+				Usage: "Number of parallel workers (number of CPUs by default)",
+			},
+			cli.StringFlag{
+				Name:  "name, n",
+				Usage: "Name for all generated accounts",
+			},
+		},
+	}
+}
+
+func generateAccounts(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	count := ctx.Int("count")
+	if count <= 0 {
+		return cli.NewExitError(errors.New("positive number of accounts is required"), 1)
+	}
+	prefixes := ctx.StringSlice("prefix")
+	for _, p := range prefixes {
+		if err := wallet.CheckAddressPrefix(p); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	pass, err := readPassword("Enter passphrase > ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	passCheck, err := readPassword("Confirm passphrase > ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if pass != passCheck {
+		return cli.NewExitError(errPhraseMismatch, 1)
+	}
+
+	gctx, cancel := getGoContext(ctx)
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-gctx.Done():
+		}
+	}()
+
+	start := time.Now()
+	accs, genErr := wall.GenerateAccounts(gctx, wallet.GenerateOptions{
+		Count:      count,
+		Prefixes:   prefixes,
+		Workers:    ctx.Int("workers"),
+		Passphrase: pass,
+		Label:      ctx.String("name"),
+		Progress: func(done int) {
+			fmt.Fprintf(os.Stderr, "\rGenerated %d/%d accounts (%s)", done, count, time.Since(start).Round(time.Second))
+		},
+	})
+	if len(accs) != 0 {
+		fmt.Fprintln(os.Stderr)
+		if err := wall.Save(); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	for _, acc := range accs {
+		fmt.Println(acc.Address)
+	}
+	if genErr != nil {
+		return cli.NewExitError(genErr, 1)
+	}
+	return nil
+}
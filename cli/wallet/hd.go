@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+)
+
+var errNotHD = errors.New("wallet is not created from mnemonic")
+
+// initHD makes wall an HD wallet using a new or an existing mnemonic.
+func initHD(ctx *cli.Context, wall *wallet.Wallet) error {
+	var mnemonic string
+	if ctx.Bool("restore") {
+		fmt.Print("Enter mnemonic > ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		mnemonic = strings.TrimSpace(line)
+	} else {
+		var err error
+		if mnemonic, err = wallet.NewMnemonic(); err != nil {
+			return err
+		}
+		fmt.Println("Mnemonic (write it down, it's needed to recover the wallet):")
+		fmt.Println(mnemonic)
+	}
+
+	phrase, err := readPassword("Enter passphrase > ")
+	if err != nil {
+		return err
+	}
+	phraseCheck, err := readPassword("Confirm passphrase > ")
+	if err != nil {
+		return err
+	}
+	if phrase != phraseCheck {
+		return errPhraseMismatch
+	}
+	return wall.InitHD(mnemonic, phrase)
+}
+
+func recoverAccounts(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	if !wall.IsHD() {
+		return cli.NewExitError(errNotHD, 1)
+	}
+
+	gctx, cancel := getGoContext(ctx)
+	defer cancel()
+
+	c, err := client.New(gctx, ctx.String("rpc"), client.Options{})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	pass, err := readPassword("Enter password > ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	added, err := wall.RecoverHD(pass, uint32(ctx.Uint("start")), ctx.Int("gap"), func(addr string) (bool, error) {
+		return isAddressUsed(c, addr)
+	})
+	for _, acc := range added {
+		fmt.Printf("%s (%s)\n", acc.Address, acc.Extra.Path)
+	}
+	if len(added) != 0 {
+		if err := wall.Save(); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Printf("%d accounts added\n", len(added))
+	return nil
+}
+
+// isAddressUsed checks whether address has ever received any assets or
+// tokens.
+func isAddressUsed(c *client.Client, addr string) (bool, error) {
+	as, err := c.GetAccountState(addr)
+	if err != nil {
+		return false, err
+	}
+	if len(as.Balances) != 0 {
+		return true, nil
+	}
+	tr, err := c.GetNEP5Transfers(addr)
+	if err != nil {
+		return false, err
+	}
+	return len(tr.Sent) != 0 || len(tr.Received) != 0, nil
+}
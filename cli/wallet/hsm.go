@@ -0,0 +1,85 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neo-go/pkg/wallet/pkcs11"
+	"github.com/urfave/cli"
+)
+
+func importHSM(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	k := &wallet.HSMKey{
+		Module: ctx.String("module"),
+		Token:  ctx.String("token"),
+		ID:     ctx.String("id"),
+	}
+	if k.Module == "" || k.Token == "" || k.ID == "" {
+		return cli.NewExitError(errors.New("module, token and key ID must be provided"), 1)
+	}
+	if _, err := hex.DecodeString(k.ID); err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid key ID: %v", err), 1)
+	}
+
+	p, err := openHSM(k.Module)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer p.Close()
+
+	acc, err := wallet.NewAccountFromHSM(p, k)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	acc.Label = ctx.String("name")
+	if err := addAccountAndSave(wall, acc); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Println(acc.Address)
+	return nil
+}
+
+// openHSM asks for PIN and opens PKCS#11 module.
+func openHSM(module string) (*pkcs11.Provider, error) {
+	pin, err := readPassword("Enter HSM PIN > ")
+	if err != nil {
+		return nil, err
+	}
+	return pkcs11.New(module, pin)
+}
+
+// unlockAccount makes acc from wall ready for signing, it asks for a password
+// using the given prompt or for a PIN if the account key is stored in HSM.
+// The returned function must be called when signing is done.
+func unlockAccount(wall *wallet.Wallet, acc *wallet.Account, prompt string) (func(), error) {
+	if acc.IsWatchOnly() {
+		return nil, fmt.Errorf("account %s is watch-only", acc.Address)
+	}
+	if acc.IsHSM() {
+		p, err := openHSM(acc.Extra.HSM.Module)
+		if err != nil {
+			return nil, err
+		}
+		if err := acc.Unlock(p); err != nil {
+			p.Close()
+			return nil, err
+		}
+		return func() { p.Close() }, nil
+	}
+	pass, err := readPassword(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if err := acc.DecryptWithParams(pass, wall.Scrypt); err != nil {
+		return nil, err
+	}
+	return func() {}, nil
+}
@@ -0,0 +1,231 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/context"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+)
+
+func newSignMessageCommand() cli.Command {
+	return cli.Command{
+		Name:      "sign-message",
+		Usage:     "sign a message to prove address ownership",
+		UsageText: "sign-message --path <path> --address <addr> [--in <file>] [--out <file>] [<message>]",
+		Description: `Signs the message with the key of the given account. Salted message is
+   signed the same way NEO dAPI wallets do it in signMessage and the result is
+   printed (or written to --out file) in the same JSON format.
+
+   For multisig accounts a parameter context with a partial signature is
+   written to --out file, pass it via --in to sign-message with other wallets
+   to add more signatures (the message argument is not needed then).`,
+		Action: signMessage,
+		Flags: []cli.Flag{
+			walletPathFlag,
+			inFlag,
+			outFlag,
+			flags.AddressFlag{
+				Name:  "address, a",
+				Usage: "Address to sign the message with",
+			},
+		},
+	}
+}
+
+func newVerifyMessageCommand() cli.Command {
+	return cli.Command{
+		Name:      "verify-message",
+		Usage:     "verify a signed message",
+		UsageText: "verify-message --in <file> [--address <addr>] [--path <path>]",
+		Description: `Verifies the message signed with sign-message or any NEO dAPI wallet. If
+   the address is given, it also checks the message is signed by it. Parameter
+   contexts of multisig accounts need the address and the wallet containing
+   the account to get its verification script.`,
+		Action: verifyMessage,
+		Flags: []cli.Flag{
+			walletPathFlag,
+			inFlag,
+			flags.AddressFlag{
+				Name:  "address, a",
+				Usage: "Address the message should be signed by",
+			},
+		},
+	}
+}
+
+func signMessage(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	addrFlag := ctx.Generic("address").(*flags.Address)
+	if !addrFlag.IsSet {
+		return cli.NewExitError("address was not provided", 1)
+	}
+	acc := wall.GetAccount(addrFlag.Uint160())
+	if acc == nil {
+		return cli.NewExitError(fmt.Errorf("wallet contains no account for '%s'", addrFlag), 1)
+	}
+	_, isMultiSig := vm.ParseMultiSigContract(acc.Contract.Script)
+
+	var (
+		msg  *wallet.Message
+		pctx *context.ParameterContext
+	)
+	if in := ctx.String("in"); in != "" {
+		if !isMultiSig {
+			return cli.NewExitError("parameter context can only be signed with multisig account", 1)
+		}
+		pctx, err = readParameterContext(in)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		var ok bool
+		if msg, ok = pctx.Verifiable.(*wallet.Message); !ok {
+			return cli.NewExitError("verifiable item is not a message", 1)
+		}
+	} else {
+		if ctx.NArg() != 1 {
+			return cli.NewExitError("message should be provided", 1)
+		}
+		msg, err = wallet.NewMessage(ctx.Args().First())
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		if isMultiSig {
+			pctx = context.NewParameterContext(wallet.MessageType, msg)
+		}
+	}
+	out := ctx.String("out")
+	if isMultiSig && out == "" {
+		return cli.NewExitError("output file should be provided for multisig accounts", 1)
+	}
+
+	fmt.Printf("Message: %s\n", msg.Text)
+	lock, err := unlockAccount(wall, acc, "Password > ")
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't unlock an account: %v", err), 1)
+	}
+	defer lock()
+
+	if isMultiSig {
+		signer := acc.Signer()
+		sig, err := signer.Sign(msg.GetSignedPart())
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("can't sign the message: %v", err), 1)
+		}
+		if err := pctx.AddSignature(acc.Contract, signer.PublicKey(), sig); err != nil {
+			return cli.NewExitError(fmt.Errorf("can't add signature: %v", err), 1)
+		}
+		if err := writeParameterContext(pctx, out); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	}
+
+	sm, err := acc.SignMessage(msg)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't sign the message: %v", err), 1)
+	}
+	data, err := json.MarshalIndent(sm, "", "  ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if out == "" {
+		fmt.Println(string(data))
+	} else if err := ioutil.WriteFile(out, data, 0644); err != nil {
+		return cli.NewExitError(fmt.Errorf("can't write signed message to file: %v", err), 1)
+	}
+	return nil
+}
+
+func verifyMessage(ctx *cli.Context) error {
+	in := ctx.String("in")
+	if in == "" {
+		return cli.NewExitError("input file should be provided", 1)
+	}
+	data, err := ioutil.ReadFile(in)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't read input file: %v", err), 1)
+	}
+	addrFlag := ctx.Generic("address").(*flags.Address)
+
+	var probe struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return cli.NewExitError(fmt.Errorf("can't parse input file: %v", err), 1)
+	}
+	if probe.Type != "" {
+		if err := verifyMultiSigMessage(ctx, data, addrFlag); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	}
+
+	sm := new(wallet.SignedMessage)
+	if err := json.Unmarshal(data, sm); err != nil {
+		return cli.NewExitError(fmt.Errorf("can't parse signed message: %v", err), 1)
+	}
+	if addrFlag.IsSet {
+		err = sm.VerifyAddress(addrFlag.Uint160())
+	} else {
+		err = sm.Verify()
+	}
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("message verification failed: %v", err), 1)
+	}
+	fmt.Printf("Message: %s\n", sm.Message)
+	fmt.Printf("Signed by: %s\n", sm.PublicKey.Address())
+	return nil
+}
+
+// verifyMultiSigMessage verifies message signatures collected in the
+// parameter context against the multisig account from the wallet.
+func verifyMultiSigMessage(ctx *cli.Context, data []byte, addrFlag *flags.Address) error {
+	if !addrFlag.IsSet {
+		return errors.New("address should be provided for parameter context")
+	}
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return err
+	}
+	defer wall.Close()
+
+	h := addrFlag.Uint160()
+	acc := wall.GetAccount(h)
+	if acc == nil {
+		return fmt.Errorf("wallet contains no account for '%s'", addrFlag)
+	}
+	pctx := new(context.ParameterContext)
+	if err := json.Unmarshal(data, pctx); err != nil {
+		return fmt.Errorf("can't parse parameter context: %v", err)
+	}
+	msg, ok := pctx.Verifiable.(*wallet.Message)
+	if !ok {
+		return errors.New("verifiable item is not a message")
+	}
+	item, ok := pctx.Items[h]
+	if !ok {
+		return fmt.Errorf("no signatures for '%s'", addrFlag)
+	}
+	sigs := make([][]byte, 0, len(item.Signatures))
+	for _, sig := range item.Signatures {
+		sigs = append(sigs, sig)
+	}
+	if err := msg.VerifyMultiSig(acc.Contract.Script, sigs); err != nil {
+		return fmt.Errorf("message verification failed: %v", err)
+	}
+	fmt.Printf("Message: %s\n", msg.Text)
+	fmt.Printf("Signed by: %s\n", address.Uint160ToString(h))
+	return nil
+}
@@ -61,16 +61,18 @@ func signMultisig(ctx *cli.Context) error {
 	}
 	printTxInfo(tx)
 	fmt.Println("Enter password to unlock wallet and sign the transaction")
-	pass, err := readPassword("Password > ")
+	lock, err := unlockAccount(wall, acc, "Password > ")
 	if err != nil {
-		return cli.NewExitError(err, 1)
-	} else if err := acc.Decrypt(pass); err != nil {
 		return cli.NewExitError(fmt.Errorf("can't unlock an account: %v", err), 1)
 	}
+	defer lock()
 
-	priv := acc.PrivateKey()
-	sign := priv.Sign(tx.GetSignedPart())
-	if err := c.AddSignature(acc.Contract, priv.PublicKey(), sign); err != nil {
+	signer := acc.Signer()
+	sign, err := signer.Sign(tx.GetSignedPart())
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("can't sign transaction: %v", err), 1)
+	}
+	if err := c.AddSignature(acc.Contract, signer.PublicKey(), sign); err != nil {
 		return cli.NewExitError(fmt.Errorf("can't add signature: %v", err), 1)
 	} else if err := writeParameterContext(c, ctx.String("out")); err != nil {
 		return cli.NewExitError(err, 1)
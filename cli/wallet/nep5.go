@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
 	"github.com/nspcc-dev/neo-go/pkg/util"
@@ -75,10 +76,11 @@ func newNEP5Commands() []cli.Command {
 			},
 		},
 		{
-			Name:      "transfer",
-			Usage:     "transfer NEP5 tokens",
-			UsageText: "transfer --path <path> --rpc <node> --from <addr> --to <addr> --token <hash> --amount string",
-			Action:    transferNEP5,
+			Name:  "transfer",
+			Usage: "transfer NEP5 tokens",
+			UsageText: "transfer --path <path> --rpc <node> --from <addr> --to <addr> --token <hash> --amount string" +
+				" [--gas <amount>] [--netfee <amount> | --priority <low|high>]",
+			Action: transferNEP5,
 			Flags: []cli.Flag{
 				walletPathFlag,
 				rpcFlag,
@@ -97,6 +99,8 @@ func newNEP5Commands() []cli.Command {
 					Name:  "gas",
 					Usage: "Amount of GAS to attach to a tx",
 				},
+				netfeeFlag,
+				priorityFlag,
 			},
 		},
 	}
@@ -114,7 +118,7 @@ func getNEP5Balance(ctx *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError(fmt.Errorf("invalid address: %v", err), 1)
 	}
-	acc := wall.GetAccount(addrHash)
+	acc := findAccount(wall, addrHash)
 	if acc == nil {
 		return cli.NewExitError(fmt.Errorf("can't find account for the address: %s", addr), 1)
 	}
@@ -335,17 +339,23 @@ func transferNEP5(ctx *cli.Context) error {
 
 	gas := flags.Fixed8FromContext(ctx, "gas")
 
-	if pass, err := readPassword("Password > "); err != nil {
-		return cli.NewExitError(err, 1)
-	} else if err := acc.Decrypt(pass); err != nil {
+	lock, err := unlockAccount(wall, acc, "Password > ")
+	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
+	defer lock()
 
-	hash, err := c.TransferNEP5(acc, to, token, amount, gas)
+	tx, err := createTxWithFee(ctx, c, acc, func(netfee util.Fixed8) (*transaction.Transaction, error) {
+		return c.CreateNEP5TransferTx(acc, to, token, amount, gas, netfee)
+	})
 	if err != nil {
 		return cli.NewExitError(err, 1)
+	} else if err := acc.SignTx(tx); err != nil {
+		return cli.NewExitError(fmt.Errorf("can't sign tx: %v", err), 1)
+	} else if err := c.SendRawTransaction(tx); err != nil {
+		return cli.NewExitError(err, 1)
 	}
 
-	fmt.Println(hash.StringLE())
+	fmt.Println(tx.Hash().StringLE())
 	return nil
 }
@@ -0,0 +1,339 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/context"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neo-go/pkg/wallet/pkcs11"
+	"github.com/urfave/cli"
+)
+
+func newTxCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:  "build",
+			Usage: "build an unsigned transfer transaction",
+			UsageText: "build --path <path> --rpc <node> --from <addr> --to <addr> --amount <amount>" +
+				" (--asset [NEO|GAS|<hex-id>] | --token <hash> [--gas <amount>]) [--coins <strategy>]" +
+				" [--netfee <amount> | --priority <low|high>] --out <file.out>",
+			Action: buildTx,
+			Flags: []cli.Flag{
+				walletPathFlag,
+				rpcFlag,
+				timeoutFlag,
+				outFlag,
+				fromAddrFlag,
+				toAddrFlag,
+				coinsFlag,
+				netfeeFlag,
+				priorityFlag,
+				cli.StringFlag{
+					Name:  "amount",
+					Usage: "Amount of asset to send",
+				},
+				cli.StringFlag{
+					Name:  "asset",
+					Usage: "Asset ID",
+				},
+				cli.StringFlag{
+					Name:  "token",
+					Usage: "NEP5 token to use instead of an asset",
+				},
+				flags.Fixed8Flag{
+					Name:  "gas",
+					Usage: "Amount of GAS to attach to NEP5 transfer",
+				},
+			},
+		},
+		{
+			Name:      "sign",
+			Usage:     "sign a transaction with all wallet accounts it needs",
+			UsageText: "sign --path <path> --in <file.in> --out <file.out>",
+			Action:    signTx,
+			Flags: []cli.Flag{
+				walletPathFlag,
+				inFlag,
+				outFlag,
+			},
+		},
+		{
+			Name:      "send",
+			Usage:     "check transaction signatures and send it to the network",
+			UsageText: "send --path <path> --rpc <node> --in <file.in>",
+			Action:    sendTx,
+			Flags: []cli.Flag{
+				walletPathFlag,
+				rpcFlag,
+				timeoutFlag,
+				inFlag,
+			},
+		},
+	}
+}
+
+func buildTx(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	fromFlag := ctx.Generic("from").(*flags.Address)
+	if !fromFlag.IsSet {
+		return cli.NewExitError("'from' address was not provided", 1)
+	}
+	from := fromFlag.Uint160()
+	acc := wall.GetAccount(from)
+	if acc == nil {
+		return cli.NewExitError(fmt.Errorf("wallet contains no account for '%s'", fromFlag), 1)
+	}
+	toFlag := ctx.Generic("to").(*flags.Address)
+	if !toFlag.IsSet {
+		return cli.NewExitError("'to' address was not provided", 1)
+	}
+	to := toFlag.Uint160()
+	outFile := ctx.String("out")
+	if outFile == "" {
+		return cli.NewExitError("output file was not provided", 1)
+	}
+
+	gctx, cancel := getGoContext(ctx)
+	defer cancel()
+
+	c, err := client.New(gctx, ctx.String("rpc"), client.Options{})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	} else if err := setCoinSelector(ctx, c); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var (
+		tx  *transaction.Transaction
+		typ string
+	)
+	if name := ctx.String("token"); name != "" {
+		token, err := getMatchingToken(wall, name)
+		if err != nil {
+			fmt.Println("Can't find matching token in the wallet. Querying RPC-node for balances.")
+			token, err = getMatchingTokenRPC(c, from, name)
+			if err != nil {
+				return cli.NewExitError(err, 1)
+			}
+		}
+		amount, err := util.FixedNFromString(ctx.String("amount"), int(token.Decimals))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid amount: %v", err), 1)
+		}
+		gas := flags.Fixed8FromContext(ctx, "gas")
+		tx, err = createTxWithFee(ctx, c, acc, func(netfee util.Fixed8) (*transaction.Transaction, error) {
+			return c.CreateNEP5TransferTx(acc, to, token, amount, gas, netfee)
+		})
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		typ = "Neo.Core.InvocationTransaction"
+	} else {
+		asset, err := getAssetID(ctx.String("asset"))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid asset id: %v", err), 1)
+		}
+		amount, err := util.Fixed8FromString(ctx.String("amount"))
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid amount: %v", err), 1)
+		}
+		payments := []client.AssetPayment{{To: to, Asset: asset, Amount: amount}}
+		tx, err = createTxWithFee(ctx, c, acc, func(netfee util.Fixed8) (*transaction.Transaction, error) {
+			return c.CreateContractTx(acc, payments, netfee)
+		})
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		typ = "Neo.Core.ContractTransaction"
+	}
+
+	pc := context.NewParameterContext(typ, tx)
+	pc.AddContract(acc.Contract)
+	if err := writeParameterContext(pc, outFile); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(tx.Hash().StringLE())
+	return nil
+}
+
+func signTx(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	c, err := readParameterContext(ctx.String("in"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	tx, ok := c.Verifiable.(*transaction.Transaction)
+	if !ok {
+		return cli.NewExitError("verifiable item is not a transaction", 1)
+	}
+
+	var (
+		accs     []*wallet.Account
+		needPass bool
+	)
+	for _, acc := range wall.Accounts {
+		if acc.Contract == nil || acc.EncryptedWIF == "" && !acc.IsHSM() {
+			continue
+		}
+		if _, ok := c.Items[acc.Contract.ScriptHash()]; ok {
+			accs = append(accs, acc)
+			needPass = needPass || !acc.IsHSM()
+		}
+	}
+	if len(accs) == 0 {
+		return cli.NewExitError("wallet contains no accounts for this transaction", 1)
+	}
+
+	printTxInfo(tx)
+	var pass string
+	if needPass {
+		fmt.Println("Enter password to unlock wallet and sign the transaction")
+		if pass, err = readPassword("Password > "); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	hsms := make(map[string]*pkcs11.Provider)
+	defer func() {
+		for _, p := range hsms {
+			p.Close()
+		}
+	}()
+
+	data := tx.GetSignedPart()
+	var signed int
+	for _, acc := range accs {
+		if acc.IsHSM() {
+			module := acc.Extra.HSM.Module
+			p, ok := hsms[module]
+			if !ok {
+				if p, err = openHSM(module); err != nil {
+					return cli.NewExitError(err, 1)
+				}
+				hsms[module] = p
+			}
+			err = acc.Unlock(p)
+		} else {
+			err = acc.DecryptWithParams(pass, wall.Scrypt)
+		}
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("can't unlock account %s: %v", acc.Address, err), 1)
+		}
+		signer := acc.Signer()
+		pub := signer.PublicKey()
+		if !needsSignature(c.Items[acc.Contract.ScriptHash()], acc.Contract, pub) {
+			continue
+		}
+		sig, err := signer.Sign(data)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("can't sign for %s: %v", acc.Address, err), 1)
+		}
+		if err := c.AddSignature(acc.Contract, pub, sig); err != nil {
+			return cli.NewExitError(fmt.Errorf("can't add signature for %s: %v", acc.Address, err), 1)
+		}
+		fmt.Printf("Signed by %s\n", acc.Address)
+		signed++
+	}
+	if signed == 0 {
+		return cli.NewExitError("transaction is already signed by all wallet accounts", 1)
+	}
+	if err := writeParameterContext(c, ctx.String("out")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+// needsSignature checks whether item for the contract can accept a signature
+// made with the key corresponding to pub.
+func needsSignature(item *context.Item, ctr *wallet.Contract, pub *keys.PublicKey) bool {
+	if _, ok := vm.ParseMultiSigContract(ctr.Script); ok {
+		return item.GetSignature(pub) == nil && len(item.Signatures) < len(ctr.Parameters)
+	}
+	return true
+}
+
+func sendTx(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	c, err := readParameterContext(ctx.String("in"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	tx, ok := c.Verifiable.(*transaction.Transaction)
+	if !ok {
+		return cli.NewExitError("verifiable item is not a transaction", 1)
+	}
+
+	hashes := make([]util.Uint160, 0, len(c.Items))
+	for h := range c.Items {
+		hashes = append(hashes, h)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Less(hashes[j]) })
+
+	tx.Scripts = make([]transaction.Witness, 0, len(hashes))
+	for _, h := range hashes {
+		acc := wall.GetAccount(h)
+		if acc == nil {
+			return cli.NewExitError(fmt.Errorf("wallet contains no contract for %s", h.StringLE()), 1)
+		}
+		w, err := c.GetWitness(acc.Contract)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("incomplete witness for %s: %v", acc.Address, err), 1)
+		}
+		if err := verifyWitness(tx, w); err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid witness for %s: %v", acc.Address, err), 1)
+		}
+		tx.Scripts = append(tx.Scripts, *w)
+	}
+
+	gctx, cancel := getGoContext(ctx)
+	defer cancel()
+
+	cl, err := client.New(gctx, ctx.String("rpc"), client.Options{})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	} else if err := cl.SendRawTransaction(tx); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(tx.Hash().StringLE())
+	return nil
+}
+
+// verifyWitness runs witness scripts against the transaction.
+func verifyWitness(tx *transaction.Transaction, w *transaction.Witness) error {
+	v := vm.New()
+	v.SetCheckedHash(tx.VerificationHash().BytesBE())
+	v.LoadScript(w.VerificationScript)
+	v.LoadScript(w.InvocationScript)
+	if err := v.Run(); err != nil {
+		return err
+	}
+	if v.Estack().Len() != 1 || !v.Estack().Pop().Bool() {
+		return errors.New("verification failed")
+	}
+	return nil
+}
@@ -18,7 +18,6 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
-	"github.com/nspcc-dev/neo-go/pkg/rpc/request"
 	context2 "github.com/nspcc-dev/neo-go/pkg/smartcontract/context"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
@@ -72,6 +71,20 @@ var (
 		Name:  "force",
 		Usage: "Do not ask for a confirmation",
 	}
+	coinsFlag = cli.StringFlag{
+		Name:  "coins",
+		Value: "smallest",
+		Usage: "Coin selection strategy (smallest, largest, exact or random)",
+	}
+	netfeeFlag = flags.Fixed8Flag{
+		Name:  "netfee",
+		Usage: "Network fee to pay (calculated by RPC node if not set)",
+	}
+	priorityFlag = cli.StringFlag{
+		Name:  "priority",
+		Value: "low",
+		Usage: "Transaction priority to calculate network fee for (low or high)",
+	}
 )
 
 // NewCommands returns 'wallet' command.
@@ -104,6 +117,14 @@ func NewCommands() []cli.Command {
 						Name:  "account, a",
 						Usage: "Create a new account",
 					},
+					cli.BoolFlag{
+						Name:  "mnemonic, m",
+						Usage: "Generate a mnemonic and derive accounts from it",
+					},
+					cli.BoolFlag{
+						Name:  "restore",
+						Usage: "Derive accounts from an existing mnemonic",
+					},
 				},
 			},
 			{
@@ -114,6 +135,26 @@ func NewCommands() []cli.Command {
 					walletPathFlag,
 				},
 			},
+			{
+				Name:      "recover",
+				Usage:     "find used accounts of HD wallet",
+				UsageText: "recover --path <path> --rpc <endpoint> [--start <index>] [--gap <n>]",
+				Action:    recoverAccounts,
+				Flags: []cli.Flag{
+					walletPathFlag,
+					rpcFlag,
+					timeoutFlag,
+					cli.UintFlag{
+						Name:  "start",
+						Usage: "Index of the first account to check",
+					},
+					cli.IntFlag{
+						Name:  "gap",
+						Value: 20,
+						Usage: "Number of consecutive unused accounts to stop at",
+					},
+				},
+			},
 			{
 				Name:   "dump",
 				Usage:  "check and dump an existing NEO wallet",
@@ -123,6 +164,23 @@ func NewCommands() []cli.Command {
 					decryptFlag,
 				},
 			},
+			{
+				Name:      "convert",
+				Usage:     "convert wallet to another format",
+				UsageText: "convert --path <path> --out <path>",
+				Description: `Copies wallet into a new file, its format is determined by the file
+   extension: wallets with '.db' extension are stored in a bbolt database
+   that only writes changed accounts on save, all others are NEP-6 JSON
+   files. The original wallet is not changed.`,
+				Action: convertWallet,
+				Flags: []cli.Flag{
+					walletPathFlag,
+					cli.StringFlag{
+						Name:  "out",
+						Usage: "Path to the new wallet",
+					},
+				},
+			},
 			{
 				Name:      "export",
 				Usage:     "export keys for address",
@@ -169,6 +227,61 @@ func NewCommands() []cli.Command {
 					},
 				},
 			},
+			{
+				Name:      "import-hsm",
+				Usage:     "import an account with the key stored in HSM",
+				UsageText: "import-hsm --path <path> --module <lib.so> --token <label> --id <hex> [--name <name>]",
+				Action:    importHSM,
+				Flags: []cli.Flag{
+					walletPathFlag,
+					cli.StringFlag{
+						Name:  "module",
+						Usage: "PKCS#11 library path",
+					},
+					cli.StringFlag{
+						Name:  "token",
+						Usage: "Token label",
+					},
+					cli.StringFlag{
+						Name:  "id",
+						Usage: "Hex-encoded key ID (CKA_ID)",
+					},
+					cli.StringFlag{
+						Name:  "name, n",
+						Usage: "Optional account name",
+					},
+				},
+			},
+			{
+				Name:      "import-watch",
+				Usage:     "import a watch-only account",
+				UsageText: "import-watch --path <path> [--name <name>] <address|scripthash>",
+				Action:    importWatchOnly,
+				Flags: []cli.Flag{
+					walletPathFlag,
+					cli.StringFlag{
+						Name:  "name, n",
+						Usage: "Optional account name",
+					},
+				},
+			},
+			{
+				Name:      "balance",
+				Usage:     "show balances of all wallet accounts",
+				UsageText: "balance --path <path> --rpc <node> [--json]",
+				Description: `Shows NEO and GAS balances, unclaimed GAS and NEP5 token balances of all
+   wallet accounts including multisig and watch-only ones along with totals.`,
+				Action: showBalance,
+				Flags: []cli.Flag{
+					walletPathFlag,
+					rpcFlag,
+					timeoutFlag,
+					cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print balances in JSON",
+					},
+				},
+			},
 			{
 				Name:      "remove",
 				Usage:     "remove an account from the wallet",
@@ -183,7 +296,8 @@ func NewCommands() []cli.Command {
 				Name:  "transfer",
 				Usage: "transfer NEO/GAS",
 				UsageText: "transfer --path <path> --from <addr> --to <addr>" +
-					" --amount <amount> --asset [NEO|GAS|<hex-id>] [--out <path>]",
+					" --amount <amount> --asset [NEO|GAS|<hex-id>] [--coins <strategy>]" +
+					" [--netfee <amount> | --priority <low|high>] [--out <path>]",
 				Action: transferAsset,
 				Flags: []cli.Flag{
 					walletPathFlag,
@@ -192,6 +306,9 @@ func NewCommands() []cli.Command {
 					outFlag,
 					fromAddrFlag,
 					toAddrFlag,
+					coinsFlag,
+					netfeeFlag,
+					priorityFlag,
 					cli.StringFlag{
 						Name:  "amount",
 						Usage: "Amount of asset to send",
@@ -202,6 +319,39 @@ func NewCommands() []cli.Command {
 					},
 				},
 			},
+			{
+				Name:  "consolidate",
+				Usage: "merge small unspent outputs into one",
+				UsageText: "consolidate --path <path> --rpc <node> --address <addr> --asset [NEO|GAS|<hex-id>]" +
+					" [--max-size <bytes>] [--max-value <amount>]",
+				Action: consolidateUnspents,
+				Flags: []cli.Flag{
+					walletPathFlag,
+					rpcFlag,
+					timeoutFlag,
+					flags.AddressFlag{
+						Name:  "address, a",
+						Usage: "Address to consolidate outputs of",
+					},
+					cli.StringFlag{
+						Name:  "asset",
+						Usage: "Asset ID",
+					},
+					cli.IntFlag{
+						Name:  "max-size",
+						Value: 1024,
+						Usage: "Maximum transaction size (node's MaxFreeTransactionSize), 0 for unlimited",
+					},
+					flags.Fixed8Flag{
+						Name:  "max-value",
+						Usage: "Only merge outputs not bigger than this amount",
+					},
+				},
+			},
+			newTransferBatchCommand(),
+			newGenerateCommand(),
+			newSignMessageCommand(),
+			newVerifyMessageCommand(),
 			{
 				Name:        "multisig",
 				Usage:       "work with multisig address",
@@ -212,6 +362,11 @@ func NewCommands() []cli.Command {
 				Usage:       "work with NEP5 contracts",
 				Subcommands: newNEP5Commands(),
 			},
+			{
+				Name:        "tx",
+				Usage:       "build, sign and send transactions separately",
+				Subcommands: newTxCommands(),
+			},
 		},
 	}}
 }
@@ -233,12 +388,11 @@ func claimGas(ctx *cli.Context) error {
 		return cli.NewExitError(fmt.Errorf("wallet contains no account for '%s'", addrFlag), 1)
 	}
 
-	pass, err := readPassword("Enter password > ")
+	lock, err := unlockAccount(wall, acc, "Enter password > ")
 	if err != nil {
 		return cli.NewExitError(err, 1)
-	} else if err := acc.Decrypt(pass); err != nil {
-		return cli.NewExitError(err, 1)
 	}
+	defer lock()
 
 	gctx, cancel := getGoContext(ctx)
 	defer cancel()
@@ -274,8 +428,9 @@ func claimGas(ctx *cli.Context) error {
 		ScriptHash: scriptHash,
 	})
 
-	_ = acc.SignTx(tx)
-	if err := c.SendRawTransaction(tx); err != nil {
+	if err := acc.SignTx(tx); err != nil {
+		return cli.NewExitError(err, 1)
+	} else if err := c.SendRawTransaction(tx); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
@@ -303,6 +458,7 @@ func exportKeys(ctx *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
+	defer wall.Close()
 
 	var addr string
 
@@ -322,7 +478,7 @@ func exportKeys(ctx *cli.Context) error {
 
 loop:
 	for _, a := range wall.Accounts {
-		if addr != "" && a.Address != addr {
+		if addr != "" && a.Address != addr || a.EncryptedWIF == "" {
 			continue
 		}
 
@@ -342,7 +498,7 @@ loop:
 				return cli.NewExitError(err, 1)
 			}
 
-			pk, err := keys.NEP2Decrypt(wif, pass)
+			pk, err := keys.NEP2DecryptWithParams(wif, pass, wall.Scrypt)
 			if err != nil {
 				return cli.NewExitError(err, 1)
 			}
@@ -356,6 +512,33 @@ loop:
 	return nil
 }
 
+func importWatchOnly(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	if ctx.NArg() != 1 {
+		return cli.NewExitError(errors.New("address or script hash must be provided"), 1)
+	}
+	arg := ctx.Args().First()
+	h, err := address.StringToUint160(arg)
+	if err != nil {
+		if h, err = util.Uint160DecodeStringLE(strings.TrimPrefix(arg, "0x")); err != nil {
+			return cli.NewExitError(fmt.Errorf("invalid address or script hash: %s", arg), 1)
+		}
+	}
+
+	acc := wallet.NewWatchOnlyAccount(h)
+	acc.Label = ctx.String("name")
+	if err := addAccountAndSave(wall, acc); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Println(acc.Address)
+	return nil
+}
+
 func importMultisig(ctx *cli.Context) error {
 	wall, err := openWallet(ctx.String("path"))
 	if err != nil {
@@ -379,7 +562,7 @@ func importMultisig(ctx *cli.Context) error {
 		}
 	}
 
-	acc, err := newAccountFromWIF(ctx.String("wif"))
+	acc, err := newAccountFromWIF(wall, ctx.String("wif"))
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -403,7 +586,7 @@ func importWallet(ctx *cli.Context) error {
 
 	defer wall.Close()
 
-	acc, err := newAccountFromWIF(ctx.String("wif"))
+	acc, err := newAccountFromWIF(wall, ctx.String("wif"))
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -436,7 +619,7 @@ func removeAccount(ctx *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError("valid address must be provided", 1)
 	}
-	acc := wall.GetAccount(addr)
+	acc := findAccount(wall, addr)
 	if acc == nil {
 		return cli.NewExitError("account wasn't found", 1)
 	}
@@ -497,12 +680,11 @@ func transferAsset(ctx *cli.Context) error {
 		return cli.NewExitError(fmt.Errorf("invalid amount: %v", err), 1)
 	}
 
-	pass, err := readPassword("Enter wallet password > ")
+	lock, err := unlockAccount(wall, acc, "Enter wallet password > ")
 	if err != nil {
 		return cli.NewExitError(err, 1)
-	} else if err := acc.Decrypt(pass); err != nil {
-		return cli.NewExitError(err, 1)
 	}
+	defer lock()
 
 	gctx, cancel := getGoContext(ctx)
 	defer cancel()
@@ -510,10 +692,7 @@ func transferAsset(ctx *cli.Context) error {
 	c, err := client.New(gctx, ctx.String("rpc"), client.Options{})
 	if err != nil {
 		return cli.NewExitError(err, 1)
-	}
-
-	tx := transaction.NewContractTX()
-	if err := request.AddInputsAndUnspentsToTx(tx, fromFlag.String(), asset, amount, c); err != nil {
+	} else if err := setCoinSelector(ctx, c); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
@@ -521,20 +700,26 @@ func transferAsset(ctx *cli.Context) error {
 	if !toFlag.IsSet {
 		return cli.NewExitError("'to' address was not provided", 1)
 	}
-	toAddr := toFlag.Uint160()
-	tx.AddOutput(&transaction.Output{
-		AssetID:    asset,
-		Amount:     amount,
-		ScriptHash: toAddr,
-		Position:   1,
+	payments := []client.AssetPayment{{
+		To:     toFlag.Uint160(),
+		Asset:  asset,
+		Amount: amount,
+	}}
+	tx, err := createTxWithFee(ctx, c, acc, func(netfee util.Fixed8) (*transaction.Transaction, error) {
+		return c.CreateContractTx(acc, payments, netfee)
 	})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
 
 	if outFile := ctx.String("out"); outFile != "" {
-		priv := acc.PrivateKey()
-		pub := priv.PublicKey()
-		sign := priv.Sign(tx.GetSignedPart())
+		signer := acc.Signer()
+		sign, err := signer.Sign(tx.GetSignedPart())
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("can't sign transaction: %v", err), 1)
+		}
 		c := context2.NewParameterContext("Neo.Core.ContractTransaction", tx)
-		if err := c.AddSignature(acc.Contract, pub, sign); err != nil {
+		if err := c.AddSignature(acc.Contract, signer.PublicKey(), sign); err != nil {
 			return cli.NewExitError(fmt.Errorf("can't add signature: %v", err), 1)
 		} else if data, err := json.Marshal(c); err != nil {
 			return cli.NewExitError(fmt.Errorf("can't marshal tx to JSON: %v", err), 1)
@@ -542,8 +727,9 @@ func transferAsset(ctx *cli.Context) error {
 			return cli.NewExitError(fmt.Errorf("can't write tx to file: %v", err), 1)
 		}
 	} else {
-		_ = acc.SignTx(tx)
-		if err := c.SendRawTransaction(tx); err != nil {
+		if err := acc.SignTx(tx); err != nil {
+			return cli.NewExitError(err, 1)
+		} else if err := c.SendRawTransaction(tx); err != nil {
 			return cli.NewExitError(err, 1)
 		}
 	}
@@ -552,6 +738,78 @@ func transferAsset(ctx *cli.Context) error {
 	return nil
 }
 
+func consolidateUnspents(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	addrFlag := ctx.Generic("address").(*flags.Address)
+	if !addrFlag.IsSet {
+		return cli.NewExitError("address was not provided", 1)
+	}
+	acc := wall.GetAccount(addrFlag.Uint160())
+	if acc == nil {
+		return cli.NewExitError(fmt.Errorf("wallet contains no account for '%s'", addrFlag), 1)
+	}
+	asset, err := getAssetID(ctx.String("asset"))
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid asset id: %v", err), 1)
+	}
+
+	gctx, cancel := getGoContext(ctx)
+	defer cancel()
+
+	c, err := client.New(gctx, ctx.String("rpc"), client.Options{})
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	tx, err := c.CreateConsolidationTx(acc, asset, ctx.Int("max-size"), flags.Fixed8FromContext(ctx, "max-value"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Printf("Merging %d outputs, total %s\n", len(tx.Inputs), tx.Outputs[0].Amount)
+
+	lock, err := unlockAccount(wall, acc, "Enter password > ")
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer lock()
+
+	if err := acc.SignTx(tx); err != nil {
+		return cli.NewExitError(err, 1)
+	} else if err := c.SendRawTransaction(tx); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Println(tx.Hash().StringLE())
+	return nil
+}
+
+// setCoinSelector sets coin selection strategy specified by the flag.
+func setCoinSelector(ctx *cli.Context, c *client.Client) error {
+	sel, err := client.GetCoinSelector(ctx.String("coins"))
+	if err != nil {
+		return err
+	}
+	c.SetCoinSelector(sel)
+	return nil
+}
+
+// createTxWithFee creates transaction with create paying network fee given by
+// the user or the one calculated by RPC node for the requested priority.
+func createTxWithFee(ctx *cli.Context, c *client.Client, acc *wallet.Account, create func(netfee util.Fixed8) (*transaction.Transaction, error)) (*transaction.Transaction, error) {
+	if ctx.IsSet("netfee") {
+		return create(flags.Fixed8FromContext(ctx, "netfee"))
+	}
+	prio, err := client.GetFeePriority(ctx.String("priority"))
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateTxWithNetworkFee(acc.Contract, prio, create)
+}
+
 func getGoContext(ctx *cli.Context) (context.Context, func()) {
 	if dur := ctx.Duration("timeout"); dur != 0 {
 		return context.WithTimeout(context.Background(), dur)
@@ -570,8 +828,11 @@ func dumpWallet(ctx *cli.Context) error {
 			return cli.NewExitError(err, 1)
 		}
 		for i := range wall.Accounts {
+			if wall.Accounts[i].IsHSM() || wall.Accounts[i].IsWatchOnly() {
+				continue
+			}
 			// Just testing the decryption here.
-			err := wall.Accounts[i].Decrypt(pass)
+			err := wall.Accounts[i].DecryptWithParams(pass, wall.Scrypt)
 			if err != nil {
 				return cli.NewExitError(err, 1)
 			}
@@ -581,6 +842,26 @@ func dumpWallet(ctx *cli.Context) error {
 	return nil
 }
 
+func convertWallet(ctx *cli.Context) error {
+	out := ctx.String("out")
+	if len(out) == 0 {
+		return cli.NewExitError(errors.New("path to the new wallet is mandatory and should be passed using --out flag"), 1)
+	}
+	wall, err := openWallet(ctx.String("path"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	newWall, err := wall.Convert(out)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	newWall.Close()
+	fmt.Printf("wallet successfully converted, file location is %s\n", newWall.Path())
+	return nil
+}
+
 func createWallet(ctx *cli.Context) error {
 	path := ctx.String("path")
 	if len(path) == 0 {
@@ -590,6 +871,11 @@ func createWallet(ctx *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
+	if ctx.Bool("mnemonic") || ctx.Bool("restore") {
+		if err := initHD(ctx, wall); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
 	if err := wall.Save(); err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -653,7 +939,9 @@ func getAssetID(s string) (util.Uint256, error) {
 	}
 }
 
-func newAccountFromWIF(wif string) (*wallet.Account, error) {
+// newAccountFromWIF creates an account for wall from the given WIF, the key
+// is (re)encrypted with the wallet scrypt parameters.
+func newAccountFromWIF(wall *wallet.Wallet, wif string) (*wallet.Account, error) {
 	// note: NEP2 strings always have length of 58 even though
 	// base58 strings can have different lengths even if slice lengths are equal
 	if len(wif) == 58 {
@@ -662,7 +950,16 @@ func newAccountFromWIF(wif string) (*wallet.Account, error) {
 			return nil, err
 		}
 
-		return wallet.NewAccountFromEncryptedWIF(wif, pass)
+		acc, err := wallet.NewAccountFromEncryptedWIF(wif, pass)
+		if err != nil {
+			return nil, err
+		}
+		if wall.Scrypt != keys.NEP2ScryptParams() {
+			if err := acc.EncryptWithParams(pass, wall.Scrypt); err != nil {
+				return nil, err
+			}
+		}
+		return acc, nil
 	}
 
 	acc, err := wallet.NewAccountFromWIF(wif)
@@ -677,13 +974,28 @@ func newAccountFromWIF(wif string) (*wallet.Account, error) {
 	}
 
 	acc.Label = name
-	if err := acc.Encrypt(pass); err != nil {
+	if err := acc.EncryptWithParams(pass, wall.Scrypt); err != nil {
 		return nil, err
 	}
 
 	return acc, nil
 }
 
+// findAccount returns wallet account for the given script hash including
+// watch-only ones.
+func findAccount(w *wallet.Wallet, h util.Uint160) *wallet.Account {
+	if acc := w.GetAccount(h); acc != nil {
+		return acc
+	}
+	addr := address.Uint160ToString(h)
+	for i := range w.Accounts {
+		if w.Accounts[i].Address == addr {
+			return w.Accounts[i]
+		}
+	}
+	return nil
+}
+
 func addAccountAndSave(w *wallet.Wallet, acc *wallet.Account) error {
 	for i := range w.Accounts {
 		if w.Accounts[i].Address == acc.Address {
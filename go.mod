@@ -5,6 +5,7 @@ require (
 	github.com/alicebob/miniredis v2.5.0+incompatible
 	github.com/go-redis/redis v6.10.2+incompatible
 	github.com/go-yaml/yaml v2.1.0+incompatible
+	github.com/miekg/pkcs11 v1.1.1
 	github.com/mr-tron/base58 v1.1.2
 	github.com/nspcc-dev/dbft v0.0.0-20200303183127-36d3da79c682
 	github.com/nspcc-dev/rfc6979 v0.2.0
@@ -12,6 +13,7 @@ require (
 	github.com/prometheus/client_golang v1.2.1
 	github.com/stretchr/testify v1.4.0
 	github.com/syndtr/goleveldb v0.0.0-20180307113352-169b1b37be73
+	github.com/tyler-smith/go-bip39 v1.0.2
 	github.com/urfave/cli v1.20.0
 	go.etcd.io/bbolt v1.3.4
 	go.uber.org/atomic v1.4.0
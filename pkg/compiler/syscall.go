@@ -1,5 +1,10 @@
 package compiler
 
+import (
+	"errors"
+	"fmt"
+)
+
 var syscalls = map[string]map[string]string{
 	"storage": {
 		"GetContext": "Neo.Storage.GetContext",
@@ -91,3 +96,25 @@ var syscalls = map[string]map[string]string{
 		"Values": "Neo.Iterator.Values",
 	},
 }
+
+// RegisterSyscall makes calls to the function name of the package imported
+// as pkg (it's the package name, not its path) compile into SYSCALL api. It's
+// used for custom interop functions of private chains, package functions are
+// stubs that are never compiled, the same way pkg/interop ones are. It should
+// be called before compilation.
+func RegisterSyscall(pkg, name, api string) error {
+	if pkg == "" || name == "" || api == "" {
+		return errors.New("package, function and API names can't be empty")
+	}
+	if old, ok := syscalls[pkg][name]; ok {
+		if old == api {
+			return nil
+		}
+		return fmt.Errorf("%s.%s is already registered as %s", pkg, name, old)
+	}
+	if syscalls[pkg] == nil {
+		syscalls[pkg] = make(map[string]string)
+	}
+	syscalls[pkg][name] = api
+	return nil
+}
@@ -1,8 +1,11 @@
 package compiler_test
 
 import (
+	"fmt"
+	"math/big"
 	"testing"
 
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,3 +48,32 @@ func TestNotify(t *testing.T) {
 	assert.Equal(t, []vm.StackItem{}, s.events[1].Value())
 	assert.Equal(t, []vm.StackItem{vm.NewByteArrayItem([]byte("single"))}, s.events[2].Value())
 }
+
+func TestRegisterSyscall(t *testing.T) {
+	require.Error(t, compiler.RegisterSyscall("", "GetPrice", "Test.Oracle.GetPrice"))
+	require.Error(t, compiler.RegisterSyscall("runtime", "Log", "Test.Runtime.Log"))
+	require.NoError(t, compiler.RegisterSyscall("runtime", "Log", "Neo.Runtime.Log"))
+	require.NoError(t, compiler.RegisterSyscall("oracle", "GetPrice", "Test.Oracle.GetPrice"))
+
+	src := `package foo
+	import "github.com/nspcc-dev/neo-go/pkg/compiler/testdata/oracle"
+	func Main() int {
+		return oracle.GetPrice("NEO") + 1
+	}`
+	v := vmAndCompile(t, src)
+	id := vm.InteropNameToID([]byte("Test.Oracle.GetPrice"))
+	v.RegisterInteropGetter(func(i uint32) *vm.InteropFuncPrice {
+		if i != id {
+			return nil
+		}
+		return &vm.InteropFuncPrice{Func: func(v *vm.VM) error {
+			if asset := string(v.Estack().Pop().Bytes()); asset != "NEO" {
+				return fmt.Errorf("unexpected asset %s", asset)
+			}
+			v.Estack().PushVal(41)
+			return nil
+		}, Price: 1}
+	})
+	require.NoError(t, v.Run())
+	require.Equal(t, big.NewInt(42), v.PopResult())
+}
@@ -0,0 +1,6 @@
+package oracle
+
+// GetPrice is a stub of custom interop function used for testing purposes.
+func GetPrice(asset string) int {
+	return 0
+}
@@ -33,12 +33,17 @@ type (
 		// Maximum number of low priority transactions accepted into block.
 		MaxFreeTransactionsPerBlock int `yaml:"MaxFreeTransactionsPerBlock"`
 		MemPoolSize                 int `yaml:"MemPoolSize"`
+		// SaveMemPool enables saving memory pool contents on shutdown and
+		// restoring them on startup.
+		SaveMemPool bool `yaml:"SaveMemPool"`
 		// SaveStorageBatch enables storage batch saving before every persist.
-		SaveStorageBatch  bool      `yaml:"SaveStorageBatch"`
-		SecondsPerBlock   int       `yaml:"SecondsPerBlock"`
-		SeedList          []string  `yaml:"SeedList"`
-		StandbyValidators []string  `yaml:"StandbyValidators"`
-		SystemFee         SystemFee `yaml:"SystemFee"`
+		SaveStorageBatch  bool     `yaml:"SaveStorageBatch"`
+		SecondsPerBlock   int      `yaml:"SecondsPerBlock"`
+		SeedList          []string `yaml:"SeedList"`
+		StandbyValidators []string `yaml:"StandbyValidators"`
+		// StateRootEnabled enables calculation of per-block state digests.
+		StateRootEnabled bool      `yaml:"StateRootEnabled"`
+		SystemFee        SystemFee `yaml:"SystemFee"`
 		// Whether to verify received blocks.
 		VerifyBlocks bool `yaml:"VerifyBlocks"`
 		// Whether to verify transactions in received blocks.
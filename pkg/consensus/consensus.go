@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math/rand"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/nspcc-dev/dbft"
@@ -19,6 +20,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
 
@@ -44,6 +46,14 @@ type Service interface {
 	// OnNewBlock notifies consensus service that there is a new block in
 	// the chain (without explicitly passing it to the service).
 	OnNewBlock()
+	// State returns the snapshot of consensus state for the current height.
+	// It returns nil if consensus is not running.
+	State() *State
+	// StateAt returns the snapshot of consensus state for the given recent
+	// height. It returns nil if there is no such state.
+	StateAt(height uint32) *State
+	// Shutdown stops the event loop and closes the consensus journal.
+	Shutdown()
 }
 
 type service struct {
@@ -64,6 +74,20 @@ type service struct {
 	blockEvents  chan struct{}
 	lastProposal []util.Uint256
 	wallet       *wallet.Wallet
+	signer       Signer
+
+	// stateLock protects state and history.
+	stateLock sync.RWMutex
+	state     *State
+	history   []*State
+
+	journal *Journal
+
+	started *atomic.Bool
+	// quit is closed to stop the event loop, finished is closed by the
+	// event loop when it exits.
+	quit     chan struct{}
+	finished chan struct{}
 }
 
 // Config is a configuration for consensus services.
@@ -85,6 +109,15 @@ type Config struct {
 	TimePerBlock time.Duration
 	// Wallet is a local-node wallet configuration.
 	Wallet *wallet.Config
+	// RemoteSigner is an address of the remote signer holding validator
+	// keys ("unix:/path/to/socket"), it's used instead of Wallet if set.
+	RemoteSigner string
+	// WatchOnly makes dBFT track consensus messages without sending any,
+	// it allows to run consensus on non-validator nodes (without wallet).
+	WatchOnly bool
+	// JournalPath is a path to the consensus journal file, all consensus
+	// events are appended to it if it's not empty.
+	JournalPath string
 }
 
 // NewService returns new consensus.Service instance.
@@ -107,19 +140,42 @@ func NewService(cfg Config) (Service, error) {
 
 		transactions: make(chan *transaction.Transaction, 100),
 		blockEvents:  make(chan struct{}, 1),
+
+		started:  atomic.NewBool(false),
+		quit:     make(chan struct{}),
+		finished: make(chan struct{}),
 	}
 
-	if cfg.Wallet == nil {
+	if cfg.Wallet == nil && cfg.RemoteSigner == "" && !cfg.WatchOnly {
 		return srv, nil
 	}
 
-	var err error
+	switch {
+	case cfg.RemoteSigner != "":
+		var err error
+
+		if srv.signer, err = NewRemoteSigner(cfg.RemoteSigner, defaultRemoteSignerTimeout); err != nil {
+			return nil, err
+		}
+	case cfg.Wallet != nil:
+		var err error
+
+		if srv.wallet, err = wallet.NewWalletFromFile(cfg.Wallet.Path); err != nil {
+			return nil, err
+		}
+
+		defer srv.wallet.Close()
 
-	if srv.wallet, err = wallet.NewWalletFromFile(cfg.Wallet.Path); err != nil {
-		return nil, err
+		srv.signer = NewWalletSigner(srv.wallet, cfg.Wallet.Password)
 	}
 
-	defer srv.wallet.Close()
+	if cfg.JournalPath != "" {
+		var err error
+
+		if srv.journal, err = NewJournal(cfg.JournalPath); err != nil {
+			return nil, err
+		}
+	}
 
 	srv.dbft = dbft.New(
 		dbft.WithLogger(srv.log),
@@ -133,7 +189,7 @@ func NewService(cfg Config) (Service, error) {
 		dbft.WithProcessBlock(srv.processBlock),
 		dbft.WithVerifyBlock(srv.verifyBlock),
 		dbft.WithGetBlock(srv.getBlock),
-		dbft.WithWatchOnly(func() bool { return false }),
+		dbft.WithWatchOnly(func() bool { return cfg.WatchOnly }),
 		dbft.WithNewBlock(func() block.Block { return new(neoBlock) }),
 		dbft.WithCurrentHeight(cfg.Chain.BlockHeight),
 		dbft.WithCurrentBlockHash(cfg.Chain.CurrentBlockHash),
@@ -163,17 +219,40 @@ var (
 
 func (s *service) Start() {
 	s.dbft.Start()
+	s.updateState()
+	s.started.Store(true)
 
 	go s.eventLoop()
 }
 
+// Shutdown implements Service interface.
+func (s *service) Shutdown() {
+	if s.started.Load() {
+		close(s.quit)
+		<-s.finished
+	}
+	if s.journal != nil {
+		if err := s.journal.Close(); err != nil {
+			s.log.Warn("can't close consensus journal", zap.Error(err))
+		}
+	}
+}
+
 func (s *service) eventLoop() {
+	defer close(s.finished)
 	for {
 		select {
+		case <-s.quit:
+			return
 		case hv := <-s.dbft.Timer.C():
 			s.log.Debug("timer fired",
 				zap.Uint32("height", hv.Height),
 				zap.Uint("view", uint(hv.View)))
+			s.addToJournal(&JournalEntry{
+				Type:   JournalTimeout,
+				Height: hv.Height,
+				View:   hv.View,
+			})
 			s.dbft.OnTimeout(hv)
 		case msg := <-s.messages:
 			fields := []zap.Field{
@@ -200,6 +279,14 @@ func (s *service) eventLoop() {
 			}
 
 			s.log.Debug("received message", fields...)
+			// Payloads are journaled here rather than in OnPayload
+			// so that the journal order matches the processing order.
+			s.addToJournal(&JournalEntry{
+				Type:    JournalReceived,
+				Height:  msg.Height(),
+				View:    msg.ViewNumber(),
+				Payload: &msg,
+			})
 			s.dbft.OnReceive(&msg)
 		case tx := <-s.transactions:
 			s.dbft.OnTransaction(tx)
@@ -209,6 +296,7 @@ func (s *service) eventLoop() {
 				zap.Uint32("chain index", s.Chain.BlockHeight()))
 			s.dbft.InitializeConsensus(0)
 		}
+		s.updateState()
 	}
 }
 
@@ -225,24 +313,37 @@ func (s *service) validatePayload(p *Payload) bool {
 }
 
 func (s *service) getKeyPair(pubs []crypto.PublicKey) (int, crypto.PrivateKey, crypto.PublicKey) {
+	if s.signer == nil {
+		return -1, nil, nil
+	}
 	for i := range pubs {
-		sh := pubs[i].(*publicKey).GetScriptHash()
-		acc := s.wallet.GetAccount(sh)
-		if acc == nil {
-			continue
-		}
-
-		key, err := keys.NEP2Decrypt(acc.EncryptedWIF, s.Config.Wallet.Password)
+		pub := pubs[i].(*publicKey).PublicKey
+		ok, err := s.signer.HasKey(pub)
 		if err != nil {
+			// Don't wait for the signer for every validator, the
+			// next round will try again.
+			s.log.Error("can't check validator key in the signer",
+				zap.Stringer("key", pub),
+				zap.Error(err))
+			return -1, nil, nil
+		}
+		if !ok {
 			continue
 		}
 
-		return i, &privateKey{PrivateKey: key}, &publicKey{PublicKey: key.PublicKey()}
+		priv := &signerKey{signer: s.signer, pub: pub, round: s.round, log: s.log}
+		return i, priv, &publicKey{PublicKey: pub}
 	}
 
 	return -1, nil, nil
 }
 
+// round returns current dBFT height and view, it must be called from the
+// goroutine processing dBFT events.
+func (s *service) round() (uint32, byte) {
+	return s.dbft.BlockIndex, s.dbft.ViewNumber
+}
+
 // OnPayload handles Payload receive.
 func (s *service) OnPayload(cp *Payload) {
 	log := s.log.With(zap.Stringer("hash", cp.Hash()), zap.Stringer("type", cp.Type()))
@@ -270,12 +371,18 @@ func (s *service) OnPayload(cp *Payload) {
 		s.lastProposal = req.transactionHashes
 	}
 
-	s.messages <- *cp
+	select {
+	case s.messages <- *cp:
+	case <-s.quit:
+	}
 }
 
 func (s *service) OnTransaction(tx *transaction.Transaction) {
 	if s.dbft != nil {
-		s.transactions <- tx
+		select {
+		case s.transactions <- tx:
+		case <-s.quit:
+		}
 	}
 }
 
@@ -312,12 +419,29 @@ func (s *service) broadcast(p payload.ConsensusPayload) {
 		pr.minerTx = *s.txx.Get(pr.transactionHashes[0]).(*transaction.Transaction)
 	}
 
-	if err := p.(*Payload).Sign(s.dbft.Priv.(*privateKey)); err != nil {
+	cp := p.(*Payload)
+	if err := s.dbft.Priv.(*signerKey).signPayload(cp); err != nil {
 		s.log.Warn("can't sign consensus payload", zap.Error(err))
 	}
 
-	s.cache.Add(p)
-	s.Config.Broadcast(p.(*Payload))
+	s.cache.Add(cp)
+	s.addToJournal(&JournalEntry{
+		Type:    JournalSent,
+		Height:  cp.Height(),
+		View:    cp.ViewNumber(),
+		Payload: cp,
+	})
+	s.Config.Broadcast(cp)
+}
+
+// addToJournal appends the entry to the consensus journal if it's enabled.
+func (s *service) addToJournal(e *JournalEntry) {
+	if s.journal == nil {
+		return
+	}
+	if err := s.journal.Add(e); err != nil {
+		s.log.Warn("can't write consensus journal", zap.Error(err))
+	}
 }
 
 func (s *service) getTx(h util.Uint256) block.Transaction {
@@ -440,7 +564,10 @@ func (s *service) getVerifiedTx(count int) []block.Transaction {
 
 	var txOuts []transaction.Output
 	if netFee != 0 {
-		sh := s.wallet.GetChangeAddress()
+		var sh util.Uint160
+		if s.wallet != nil {
+			sh = s.wallet.GetChangeAddress()
+		}
 		if sh.Equals(util.Uint160{}) {
 			pk := s.dbft.Pub.(*publicKey)
 			sh = pk.GetScriptHash()
@@ -1,7 +1,11 @@
 package consensus
 
 import (
+	"io/ioutil"
+	"os"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/nspcc-dev/dbft/block"
 	"github.com/nspcc-dev/dbft/payload"
@@ -160,6 +164,95 @@ func TestService_OnPayload(t *testing.T) {
 	srv.Chain.Close()
 }
 
+func TestService_WatchOnlyState(t *testing.T) {
+	chain := newTestChain(t)
+	srv, err := NewService(Config{
+		Logger:    zaptest.NewLogger(t),
+		Broadcast: func(*Payload) {},
+		Chain:     chain,
+		RequestTx: func(...util.Uint256) {},
+		WatchOnly: true,
+	})
+	require.NoError(t, err)
+
+	s := srv.(*service)
+	require.Nil(t, s.State())
+
+	s.dbft.Start()
+	s.updateState()
+
+	st := s.State()
+	require.NotNil(t, st)
+	require.True(t, st.WatchOnly)
+	require.Equal(t, chain.BlockHeight()+1, st.Height)
+	require.Equal(t, byte(0), st.View)
+	require.Len(t, st.Validators, 4)
+	require.Equal(t, StageWaitingRequest, st.Stage)
+	require.Equal(t, 0, st.Preparations())
+	require.Equal(t, 0, st.Commits())
+	require.Equal(t, 0, st.ChangeViews())
+
+	require.Equal(t, st, s.StateAt(st.Height))
+	require.Nil(t, s.StateAt(st.Height+1))
+	chain.Close()
+}
+
+func TestService_JournalAndShutdown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consensus-journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "journal")
+
+	chain := newTestChain(t)
+	defer chain.Close()
+	srv, err := NewService(Config{
+		Logger:      zaptest.NewLogger(t),
+		Broadcast:   func(*Payload) {},
+		Chain:       chain,
+		RequestTx:   func(...util.Uint256) {},
+		WatchOnly:   true,
+		JournalPath: file,
+	})
+	require.NoError(t, err)
+	s := srv.(*service)
+	s.Start()
+
+	priv, _ := getTestValidator(1)
+	p := new(Payload)
+	p.SetType(payload.CommitType)
+	p.SetPayload(&commit{})
+	p.SetValidatorIndex(1)
+	p.SetHeight(chain.BlockHeight() + 1)
+	require.NoError(t, p.Sign(priv))
+	s.OnPayload(p)
+
+	// The payload is journaled by the event loop, it finishes processing
+	// the message before exiting.
+	for i := 0; len(s.messages) != 0; i++ {
+		require.True(t, i < 100, "payload is not processed")
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.Shutdown()
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	entries, err := ReadJournal(f)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	require.Equal(t, JournalRound, entries[0].Type)
+	require.Equal(t, JournalReceived, entries[1].Type)
+	require.Equal(t, p.Hash(), entries[1].Payload.Hash())
+
+	// Payloads received after shutdown don't block.
+	p.SetHeight(p.Height() + 1)
+	require.NoError(t, p.Sign(priv))
+	for i := 0; i < cap(s.messages)+1; i++ {
+		s.cache = newFIFOCache(cacheMaxCapacity)
+		s.OnPayload(p)
+	}
+}
+
 func shouldReceive(t *testing.T, ch chan Payload) {
 	select {
 	case <-ch:
@@ -0,0 +1,168 @@
+package consensus
+
+import (
+	"bufio"
+	"fmt"
+	gio "io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// JournalEntryType is a type of consensus journal record.
+type JournalEntryType byte
+
+// Consensus journal record types.
+const (
+	// JournalRound marks the start of a new round (height).
+	JournalRound JournalEntryType = iota
+	// JournalReceived is a payload received from the network.
+	JournalReceived
+	// JournalSent is a payload sent by the node.
+	JournalSent
+	// JournalTimeout is a dBFT timer event.
+	JournalTimeout
+)
+
+// maxJournalValidators is the maximum number of validators in a round
+// record.
+const maxJournalValidators = 1024
+
+// String implements fmt.Stringer interface.
+func (t JournalEntryType) String() string {
+	switch t {
+	case JournalRound:
+		return "round"
+	case JournalReceived:
+		return "received"
+	case JournalSent:
+		return "sent"
+	case JournalTimeout:
+		return "timeout"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", byte(t))
+	}
+}
+
+// JournalEntry is a single record of the consensus journal.
+type JournalEntry struct {
+	Type      JournalEntryType
+	Timestamp time.Time
+	Height    uint32
+	View      byte
+	// Payload is set for JournalReceived and JournalSent records.
+	Payload *Payload
+	// PrevHash and Validators are set for JournalRound records.
+	PrevHash   util.Uint256
+	Validators []*keys.PublicKey
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (e *JournalEntry) EncodeBinary(w *io.BinWriter) {
+	w.WriteB(byte(e.Type))
+	w.WriteU64LE(uint64(e.Timestamp.UnixNano()))
+	w.WriteU32LE(e.Height)
+	w.WriteB(e.View)
+	switch e.Type {
+	case JournalRound:
+		w.WriteBytes(e.PrevHash[:])
+		w.WriteArray(e.Validators)
+	case JournalReceived, JournalSent:
+		e.Payload.EncodeBinary(w)
+	}
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (e *JournalEntry) DecodeBinary(r *io.BinReader) {
+	e.Type = JournalEntryType(r.ReadB())
+	e.Timestamp = time.Unix(0, int64(r.ReadU64LE()))
+	e.Height = r.ReadU32LE()
+	e.View = r.ReadB()
+	switch e.Type {
+	case JournalRound:
+		r.ReadBytes(e.PrevHash[:])
+		r.ReadArray(&e.Validators, maxJournalValidators)
+	case JournalReceived, JournalSent:
+		e.Payload = new(Payload)
+		e.Payload.DecodeBinary(r)
+	case JournalTimeout:
+	default:
+		if r.Err == nil {
+			r.Err = fmt.Errorf("unknown journal entry type %d", byte(e.Type))
+		}
+	}
+}
+
+// Journal is an append-only file log of consensus events. Every record is
+// encoded into the buffer first and then written with a single call, so
+// records are never interleaved and an interrupted write can only truncate
+// the last one.
+type Journal struct {
+	lock sync.Mutex
+	file *os.File
+	buf  *io.BufBinWriter
+}
+
+// NewJournal opens (or creates) journal file at the given path, new
+// records are appended to it.
+func NewJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{
+		file: f,
+		buf:  io.NewBufBinWriter(),
+	}, nil
+}
+
+// Add appends the entry to the journal setting its timestamp to the
+// current time.
+func (j *Journal) Add(e *JournalEntry) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	e.Timestamp = time.Now()
+	j.buf.Reset()
+	e.EncodeBinary(j.buf.BinWriter)
+	if j.buf.Err != nil {
+		return j.buf.Err
+	}
+	_, err := j.file.Write(j.buf.Bytes())
+	return err
+}
+
+// Close syncs and closes the journal file.
+func (j *Journal) Close() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	err := j.file.Sync()
+	if cerr := j.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ReadJournal reads all journal entries from the given reader.
+func ReadJournal(rd gio.Reader) ([]*JournalEntry, error) {
+	var entries []*JournalEntry
+
+	br := bufio.NewReader(rd)
+	r := io.NewBinReaderFromIO(br)
+	for {
+		if _, err := br.Peek(1); err == gio.EOF {
+			return entries, nil
+		}
+		e := new(JournalEntry)
+		e.DecodeBinary(r)
+		if r.Err != nil {
+			return entries, fmt.Errorf("entry #%d: %v", len(entries), r.Err)
+		}
+		entries = append(entries, e)
+	}
+}
@@ -0,0 +1,120 @@
+package consensus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/dbft/timer"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/internal/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consensus-journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, pub := getTestValidator(0)
+	entries := []*JournalEntry{
+		{
+			Type:       JournalRound,
+			Height:     10,
+			PrevHash:   random.Uint256(),
+			Validators: []*keys.PublicKey{pub.PublicKey},
+		},
+		{
+			Type:    JournalReceived,
+			Height:  10,
+			Payload: randomPayload(t, commitType),
+		},
+		{
+			Type:    JournalSent,
+			Height:  10,
+			Payload: randomPayload(t, prepareResponseType),
+		},
+		{
+			Type:   JournalTimeout,
+			Height: 10,
+			View:   1,
+		},
+	}
+
+	file := path.Join(dir, "journal")
+	j, err := NewJournal(file)
+	require.NoError(t, err)
+	for _, e := range entries[:2] {
+		require.NoError(t, j.Add(e))
+	}
+	require.NoError(t, j.Close())
+
+	// Journal is appended to.
+	j, err = NewJournal(file)
+	require.NoError(t, err)
+	for _, e := range entries[2:] {
+		require.NoError(t, j.Add(e))
+	}
+	require.NoError(t, j.Close())
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	actual, err := ReadJournal(f)
+	require.NoError(t, err)
+	require.Equal(t, len(entries), len(actual))
+	for i := range entries {
+		require.Equal(t, entries[i].Type, actual[i].Type)
+		require.Equal(t, entries[i].Timestamp.UnixNano(), actual[i].Timestamp.UnixNano())
+		require.Equal(t, entries[i].Height, actual[i].Height)
+		require.Equal(t, entries[i].View, actual[i].View)
+		require.Equal(t, entries[i].PrevHash, actual[i].PrevHash)
+		require.Equal(t, entries[i].Validators, actual[i].Validators)
+		if entries[i].Payload != nil {
+			require.Equal(t, entries[i].Payload.Hash(), actual[i].Payload.Hash())
+		}
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		data, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		actual, err := ReadJournal(bytes.NewReader(data[:len(data)-1]))
+		require.Error(t, err)
+		require.Equal(t, len(entries)-1, len(actual))
+	})
+}
+
+func TestJournalEntryType_String(t *testing.T) {
+	require.Equal(t, "round", JournalRound.String())
+	require.Equal(t, "received", JournalReceived.String())
+	require.Equal(t, "sent", JournalSent.String())
+	require.Equal(t, "timeout", JournalTimeout.String())
+	require.Equal(t, "UNKNOWN(42)", JournalEntryType(42).String())
+}
+
+func TestReplayTimer(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tm := &replayTimer{now: now}
+	require.Equal(t, now, tm.Now())
+	require.Nil(t, tm.C())
+
+	tm.Reset(timer.HV{Height: 10, View: 1}, time.Second)
+	require.Equal(t, timer.HV{Height: 10, View: 1}, tm.HV())
+	require.Equal(t, now.Add(time.Second), tm.deadline)
+	tm.Extend(time.Second)
+	require.Equal(t, now.Add(2*time.Second), tm.deadline)
+
+	// Sleeping doesn't advance the clock.
+	tm.Sleep(time.Hour)
+	require.Equal(t, now, tm.Now())
+	tm.SetHV(timer.HV{Height: 11})
+	require.Equal(t, timer.HV{Height: 11}, tm.HV())
+}
+
+func TestReplay_Empty(t *testing.T) {
+	require.Error(t, Replay(nil, ioutil.Discard, nil))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
@@ -184,13 +185,19 @@ func (p *Payload) EncodeBinary(w *io.BinWriter) {
 // Sign signs payload using the private key.
 // It also sets corresponding verification and invocation scripts.
 func (p *Payload) Sign(key *privateKey) error {
-	sig, err := key.Sign(p.MarshalUnsigned())
+	return p.signWith(key.PublicKey(), key.Sign)
+}
+
+// signWith signs payload using the given function producing signature for
+// the key corresponding to pub.
+func (p *Payload) signWith(pub *keys.PublicKey, sign func([]byte) ([]byte, error)) error {
+	sig, err := sign(p.MarshalUnsigned())
 	if err != nil {
 		return err
 	}
 
 	p.Witness.InvocationScript = append([]byte{byte(opcode.PUSHBYTES64)}, sig...)
-	p.Witness.VerificationScript = key.PublicKey().GetVerificationScript()
+	p.Witness.VerificationScript = pub.GetVerificationScript()
 
 	return nil
 }
@@ -0,0 +1,71 @@
+package consensus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics used in monitoring service.
+var (
+	consensusHeight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Height of the current consensus round",
+			Name:      "consensus_height",
+			Namespace: "neogo",
+		},
+	)
+	consensusView = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "View number of the current consensus round",
+			Name:      "consensus_view",
+			Namespace: "neogo",
+		},
+	)
+	consensusPrimary = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Primary validator index of the current consensus round",
+			Name:      "consensus_primary",
+			Namespace: "neogo",
+		},
+	)
+	consensusPreparations = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of preparations collected in the current view",
+			Name:      "consensus_preparations",
+			Namespace: "neogo",
+		},
+	)
+	consensusCommits = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of commits collected in the current view",
+			Name:      "consensus_commits",
+			Namespace: "neogo",
+		},
+	)
+	consensusChangeViews = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of view change requests collected in the current view",
+			Name:      "consensus_change_views",
+			Namespace: "neogo",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		consensusHeight,
+		consensusView,
+		consensusPrimary,
+		consensusPreparations,
+		consensusCommits,
+		consensusChangeViews,
+	)
+}
+
+func updateStateMetrics(st *State) {
+	consensusHeight.Set(float64(st.Height))
+	consensusView.Set(float64(st.View))
+	consensusPrimary.Set(float64(st.Primary))
+	consensusPreparations.Set(float64(st.Preparations()))
+	consensusCommits.Set(float64(st.Commits()))
+	consensusChangeViews.Set(float64(st.ChangeViews()))
+}
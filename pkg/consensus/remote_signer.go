@@ -0,0 +1,195 @@
+package consensus
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+)
+
+// defaultRemoteSignerTimeout is the default timeout for remote signer
+// requests.
+const defaultRemoteSignerTimeout = 5 * time.Second
+
+// Remote signer protocol methods.
+const (
+	signerMethodHasKey = "haskey"
+	signerMethodSign   = "sign"
+)
+
+// signerRequest is a remote signer protocol request, requests and responses
+// are JSON objects separated by newlines.
+type signerRequest struct {
+	Method    string          `json:"method"`
+	PublicKey *keys.PublicKey `json:"publickey"`
+	Type      SignType        `json:"type,omitempty"`
+	Height    uint32          `json:"height,omitempty"`
+	View      byte            `json:"view,omitempty"`
+	Data      []byte          `json:"data,omitempty"`
+}
+
+// signerResponse is a remote signer protocol response.
+type signerResponse struct {
+	HasKey    bool   `json:"haskey,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// RemoteSigner is a Signer that sends requests to the signer daemon over
+// the network. The connection is established on the first request and is
+// reestablished after failures. HasKey answers are cached, so the daemon is
+// asked about every key only once.
+type RemoteSigner struct {
+	network string
+	address string
+	timeout time.Duration
+
+	lock sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+
+	keysLock sync.RWMutex
+	keys     map[string]bool
+}
+
+var _ Signer = (*RemoteSigner)(nil)
+
+// ParseSignerAddress splits remote signer address into network and address
+// suitable for net.Dial and net.Listen. The signer protocol has no
+// authentication, so only "unix:/path/to/socket" addresses are valid.
+func ParseSignerAddress(addr string) (string, string, error) {
+	i := strings.IndexByte(addr, ':')
+	if i < 0 {
+		return "", "", fmt.Errorf("invalid signer address %q", addr)
+	}
+	network, address := addr[:i], addr[i+1:]
+	if network != "unix" {
+		return "", "", fmt.Errorf("unsupported signer network %q, only unix sockets can be used", network)
+	}
+	if address == "" {
+		return "", "", fmt.Errorf("invalid signer address %q", addr)
+	}
+	return network, address, nil
+}
+
+// NewRemoteSigner creates a signer using the daemon listening at the given
+// address.
+func NewRemoteSigner(addr string, timeout time.Duration) (*RemoteSigner, error) {
+	network, address, err := ParseSignerAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{
+		network: network,
+		address: address,
+		timeout: timeout,
+		keys:    make(map[string]bool),
+	}, nil
+}
+
+// HasKey implements Signer interface. Only successful answers are cached,
+// communication errors are returned.
+func (s *RemoteSigner) HasKey(pub *keys.PublicKey) (bool, error) {
+	k := string(pub.Bytes())
+	s.keysLock.RLock()
+	has, ok := s.keys[k]
+	s.keysLock.RUnlock()
+	if ok {
+		return has, nil
+	}
+
+	resp, err := s.call(&signerRequest{
+		Method:    signerMethodHasKey,
+		PublicKey: pub,
+	})
+	if err != nil {
+		return false, err
+	}
+	s.keysLock.Lock()
+	s.keys[k] = resp.HasKey
+	s.keysLock.Unlock()
+	return resp.HasKey, nil
+}
+
+// Sign implements Signer interface.
+func (s *RemoteSigner) Sign(req *SignRequest) ([]byte, error) {
+	resp, err := s.call(&signerRequest{
+		Method:    signerMethodSign,
+		PublicKey: req.PublicKey,
+		Type:      req.Type,
+		Height:    req.Height,
+		View:      req.View,
+		Data:      req.Data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// Close closes connection to the signer daemon.
+func (s *RemoteSigner) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *RemoteSigner) call(req *signerRequest) (*signerResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+		if err != nil {
+			return nil, err
+		}
+		s.conn = conn
+		s.r = bufio.NewReader(conn)
+	}
+
+	resp, err := s.roundTrip(req)
+	if err != nil {
+		// The stream is in unknown state, start over next time.
+		s.conn.Close()
+		s.conn = nil
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (s *RemoteSigner) roundTrip(req *signerRequest) (*signerResponse, error) {
+	if err := s.conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	line, err := s.r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	resp := new(signerResponse)
+	if err := json.Unmarshal(line, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
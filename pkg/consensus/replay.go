@@ -0,0 +1,197 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	gio "io"
+	"time"
+
+	"github.com/nspcc-dev/dbft"
+	"github.com/nspcc-dev/dbft/block"
+	"github.com/nspcc-dev/dbft/crypto"
+	"github.com/nspcc-dev/dbft/payload"
+	"github.com/nspcc-dev/dbft/timer"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"go.uber.org/zap"
+)
+
+// replayer feeds journal entries into a fresh watch-only dBFT instance. The
+// clock is driven by journal timestamps and timer events are taken from the
+// journal instead of being generated locally, so replay is deterministic.
+type replayer struct {
+	dbft  *dbft.DBFT
+	timer *replayTimer
+	out   gio.Writer
+
+	height     uint32
+	prevHash   util.Uint256
+	validators []crypto.PublicKey
+
+	start time.Time
+}
+
+// replayTimer is a dBFT timer driven by the journal. Its clock is set to the
+// timestamp of the entry being replayed and it never fires by itself,
+// timeouts are delivered from the journal.
+type replayTimer struct {
+	now      time.Time
+	hv       timer.HV
+	deadline time.Time
+}
+
+var _ timer.Timer = (*replayTimer)(nil)
+
+// Now implements timer.Timer interface.
+func (t *replayTimer) Now() time.Time { return t.now }
+
+// Reset implements timer.Timer interface.
+func (t *replayTimer) Reset(hv timer.HV, d time.Duration) {
+	t.hv = hv
+	t.deadline = t.now.Add(d)
+}
+
+// Sleep implements timer.Timer interface, time only advances with the
+// journal.
+func (t *replayTimer) Sleep(time.Duration) {}
+
+// Extend implements timer.Timer interface.
+func (t *replayTimer) Extend(d time.Duration) { t.deadline = t.deadline.Add(d) }
+
+// Stop implements timer.Timer interface.
+func (t *replayTimer) Stop() {}
+
+// HV implements timer.Timer interface.
+func (t *replayTimer) HV() timer.HV { return t.hv }
+
+// SetHV implements timer.Timer interface.
+func (t *replayTimer) SetHV(hv timer.HV) { t.hv = hv }
+
+// C implements timer.Timer interface, the channel returned is nil as the
+// timer never fires.
+func (t *replayTimer) C() <-chan timer.HV { return nil }
+
+// Replay replays consensus journal entries printing dBFT state transitions
+// to out.
+func Replay(entries []*JournalEntry, out gio.Writer, log *zap.Logger) error {
+	if len(entries) == 0 {
+		return errors.New("empty journal")
+	}
+	if log == nil {
+		log = zap.NewNop()
+	}
+
+	r := &replayer{
+		out:   out,
+		start: entries[0].Timestamp,
+		timer: &replayTimer{now: entries[0].Timestamp},
+	}
+	r.dbft = dbft.New(
+		dbft.WithLogger(log),
+		dbft.WithTimer(r.timer),
+		dbft.WithGetKeyPair(func([]crypto.PublicKey) (int, crypto.PrivateKey, crypto.PublicKey) {
+			return -1, nil, nil
+		}),
+		dbft.WithTxPerBlock(10000),
+		dbft.WithRequestTx(func(...util.Uint256) {}),
+		dbft.WithGetTx(func(h util.Uint256) block.Transaction {
+			// Only hashes matter for the block header.
+			return transaction.NewTrimmedTX(h)
+		}),
+		dbft.WithGetVerified(func(int) []block.Transaction { return nil }),
+		dbft.WithBroadcast(func(payload.ConsensusPayload) {}),
+		dbft.WithProcessBlock(r.processBlock),
+		dbft.WithVerifyBlock(func(block.Block) bool { return true }),
+		dbft.WithGetBlock(func(util.Uint256) block.Block { return nil }),
+		dbft.WithWatchOnly(func() bool { return true }),
+		dbft.WithNewBlock(func() block.Block { return new(neoBlock) }),
+		dbft.WithCurrentHeight(func() uint32 { return r.height - 1 }),
+		dbft.WithCurrentBlockHash(func() util.Uint256 { return r.prevHash }),
+		dbft.WithGetValidators(func(...block.Transaction) []crypto.PublicKey { return r.validators }),
+		dbft.WithGetConsensusAddress(r.getConsensusAddress),
+
+		dbft.WithNewConsensusPayload(func() payload.ConsensusPayload { return new(Payload) }),
+		dbft.WithNewPrepareRequest(func() payload.PrepareRequest { return new(prepareRequest) }),
+		dbft.WithNewPrepareResponse(func() payload.PrepareResponse { return new(prepareResponse) }),
+		dbft.WithNewChangeView(func() payload.ChangeView { return new(changeView) }),
+		dbft.WithNewCommit(func() payload.Commit { return new(commit) }),
+		dbft.WithNewRecoveryRequest(func() payload.RecoveryRequest { return new(recoveryRequest) }),
+		dbft.WithNewRecoveryMessage(func() payload.RecoveryMessage { return new(recoveryMessage) }),
+	)
+	if r.dbft == nil {
+		return errors.New("can't initialize dBFT")
+	}
+
+	var (
+		started bool
+		prev    *State
+	)
+	for i, e := range entries {
+		r.timer.now = e.Timestamp
+		switch e.Type {
+		case JournalRound:
+			r.height = e.Height
+			r.prevHash = e.PrevHash
+			r.validators = make([]crypto.PublicKey, len(e.Validators))
+			for j := range e.Validators {
+				r.validators[j] = &publicKey{PublicKey: e.Validators[j]}
+			}
+			r.printf("new round, %d validators, previous block %s", len(r.validators), r.prevHash.StringLE())
+			if !started {
+				r.dbft.Start()
+				started = true
+			} else {
+				r.dbft.InitializeConsensus(0)
+			}
+		case JournalReceived, JournalSent:
+			r.printf("%s %s from #%d (height %d, view %d)", e.Type, e.Payload.Type(),
+				e.Payload.ValidatorIndex(), e.Payload.Height(), e.Payload.ViewNumber())
+			if started {
+				r.dbft.OnReceive(e.Payload)
+			}
+		case JournalTimeout:
+			r.printf("timeout (height %d, view %d, scheduled at +%s)", e.Height, e.View, r.timer.deadline.Sub(r.start))
+			if started {
+				r.dbft.OnTimeout(timer.HV{Height: e.Height, View: e.View})
+			}
+		default:
+			return fmt.Errorf("entry #%d: unknown type %s", i, e.Type)
+		}
+		if !started {
+			continue
+		}
+
+		st := newState(r.dbft)
+		if prev == nil || st.Height != prev.Height || st.View != prev.View || st.Stage != prev.Stage {
+			r.printf("  -> height %d, view %d, primary #%d: %s", st.Height, st.View, st.Primary, st.Stage)
+		}
+		if prev == nil || st.Preparations() != prev.Preparations() ||
+			st.Commits() != prev.Commits() || st.ChangeViews() != prev.ChangeViews() {
+			r.printf("  -> preparations %d, commits %d, change views %d of %d",
+				st.Preparations(), st.Commits(), st.ChangeViews(), len(st.Validators))
+		}
+		prev = st
+	}
+	return nil
+}
+
+func (r *replayer) printf(format string, args ...interface{}) {
+	now := r.timer.Now()
+	fmt.Fprintf(r.out, "%s +%s ", now.UTC().Format(time.RFC3339Nano), now.Sub(r.start))
+	fmt.Fprintf(r.out, format, args...)
+	fmt.Fprintln(r.out)
+}
+
+func (r *replayer) processBlock(b block.Block) {
+	r.printf("  -> block %d accepted: %s", b.Index(), b.Hash().StringLE())
+}
+
+func (r *replayer) getConsensusAddress(validators ...crypto.PublicKey) (h util.Uint160) {
+	script, err := smartcontract.CreateMultiSigRedeemScript(r.dbft.M(), convertKeys(validators))
+	if err != nil {
+		return
+	}
+
+	return crypto.Hash160(script)
+}
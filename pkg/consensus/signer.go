@@ -0,0 +1,174 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"go.uber.org/zap"
+)
+
+// SignType is a type of data being signed by the validator.
+type SignType byte
+
+// Types of data signed by validators.
+const (
+	// SignPayload is used for consensus payloads.
+	SignPayload SignType = iota
+	// SignBlock is used for block headers (commit signatures).
+	SignBlock
+)
+
+// String implements fmt.Stringer interface.
+func (t SignType) String() string {
+	switch t {
+	case SignPayload:
+		return "payload"
+	case SignBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", byte(t))
+	}
+}
+
+// SignRequest is a request to sign some data with the validator key.
+type SignRequest struct {
+	PublicKey *keys.PublicKey
+	Type      SignType
+	Height    uint32
+	View      byte
+	Data      []byte
+}
+
+// Signer provides access to validator keys, it allows to keep them outside
+// of the node process.
+type Signer interface {
+	// HasKey returns true if the signer can sign data with the key
+	// corresponding to the given public key. An error is returned if
+	// this can't be checked.
+	HasKey(pub *keys.PublicKey) (bool, error)
+	// Sign signs data with the key specified in the request.
+	Sign(req *SignRequest) ([]byte, error)
+}
+
+// WalletSigner is a Signer using keys from the local wallet. Keys are
+// decrypted on the first use and are kept in memory.
+type WalletSigner struct {
+	wallet   *wallet.Wallet
+	password string
+
+	lock sync.RWMutex
+	keys map[util.Uint160]*keys.PrivateKey
+}
+
+var _ Signer = (*WalletSigner)(nil)
+
+// NewWalletSigner creates a signer for the wallet unlocked with the given
+// password.
+func NewWalletSigner(w *wallet.Wallet, password string) *WalletSigner {
+	return &WalletSigner{
+		wallet:   w,
+		password: password,
+		keys:     make(map[util.Uint160]*keys.PrivateKey),
+	}
+}
+
+func (s *WalletSigner) getKey(pub *keys.PublicKey) *keys.PrivateKey {
+	sh := pub.GetScriptHash()
+
+	s.lock.RLock()
+	key, ok := s.keys[sh]
+	s.lock.RUnlock()
+	if ok {
+		return key
+	}
+
+	acc := s.wallet.GetAccount(sh)
+	if acc == nil {
+		return nil
+	}
+	key, err := keys.NEP2DecryptWithParams(acc.EncryptedWIF, s.password, s.wallet.Scrypt)
+	if err != nil || !key.PublicKey().Equal(pub) {
+		return nil
+	}
+
+	s.lock.Lock()
+	s.keys[sh] = key
+	s.lock.Unlock()
+	return key
+}
+
+// HasKey implements Signer interface.
+func (s *WalletSigner) HasKey(pub *keys.PublicKey) (bool, error) {
+	return s.getKey(pub) != nil, nil
+}
+
+// Sign implements Signer interface.
+func (s *WalletSigner) Sign(req *SignRequest) ([]byte, error) {
+	key := s.getKey(req.PublicKey)
+	if key == nil {
+		return nil, errors.New("no key in the wallet")
+	}
+	return key.Sign(req.Data), nil
+}
+
+// signerKey implements dbft's crypto.PrivateKey interface using Signer.
+type signerKey struct {
+	signer Signer
+	pub    *keys.PublicKey
+	// round returns current dBFT height and view.
+	round func() (uint32, byte)
+	log   *zap.Logger
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler interface. Private key
+// can't be exported from the signer.
+func (k *signerKey) MarshalBinary() ([]byte, error) {
+	return nil, errors.New("private key is not available")
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler interface.
+func (k *signerKey) UnmarshalBinary([]byte) error {
+	return errors.New("private key can't be set")
+}
+
+// PublicKey returns public key corresponding to the signer key.
+func (k *signerKey) PublicKey() *keys.PublicKey {
+	return k.pub
+}
+
+// Sign implements dbft's crypto.PrivateKey interface. dBFT only uses it to
+// sign block headers.
+func (k *signerKey) Sign(data []byte) ([]byte, error) {
+	height, view := k.round()
+	sig, err := k.signer.Sign(&SignRequest{
+		PublicKey: k.pub,
+		Type:      SignBlock,
+		Height:    height,
+		View:      view,
+		Data:      data,
+	})
+	if err != nil && k.log != nil {
+		k.log.Error("can't sign block",
+			zap.Uint32("height", height),
+			zap.Uint("view", uint(view)),
+			zap.Error(err))
+	}
+	return sig, err
+}
+
+// signPayload signs consensus payload.
+func (k *signerKey) signPayload(p *Payload) error {
+	return p.signWith(k.pub, func(data []byte) ([]byte, error) {
+		return k.signer.Sign(&SignRequest{
+			PublicKey: k.pub,
+			Type:      SignPayload,
+			Height:    p.Height(),
+			View:      p.ViewNumber(),
+			Data:      data,
+		})
+	})
+}
@@ -0,0 +1,183 @@
+package consensus
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+const (
+	// signedHistorySize is the number of heights for which signed blocks
+	// are remembered by the signer daemon.
+	signedHistorySize = 1000
+
+	// signedKeySize is the size of the compressed public key.
+	signedKeySize = 33
+	// signedRecordSize is the size of the signed block record in the
+	// history file: compressed public key, height and block data hash.
+	signedRecordSize = signedKeySize + 4 + 32
+)
+
+// signedRound identifies a block signature. View is not a part of the block
+// data, and dBFT never commits different blocks at the same height anyway,
+// so only one block per height can be signed.
+type signedRound struct {
+	key    string
+	height uint32
+}
+
+// signedHistory keeps hashes of the blocks signed by the signer daemon. It's
+// backed by an append-only file, every record is synced to the disk before
+// the signature is given out, so double signing is prevented across daemon
+// restarts too.
+type signedHistory struct {
+	file      *os.File
+	signed    map[signedRound]util.Uint256
+	maxHeight uint32
+}
+
+// openSignedHistory loads signed blocks history from the file at the given
+// path (creating it if it doesn't exist). The file is rewritten with only
+// recent records kept, incomplete last record (that can be left by an
+// interrupted write) is dropped, the signature for it was never returned.
+func openSignedHistory(path string) (*signedHistory, error) {
+	h := &signedHistory{
+		signed: make(map[signedRound]util.Uint256),
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var maxHeight uint32
+	r := io.NewBinReaderFromBuf(data)
+	for i := 0; i < len(data)/signedRecordSize; i++ {
+		var (
+			key  [signedKeySize]byte
+			hash util.Uint256
+		)
+		r.ReadBytes(key[:])
+		height := r.ReadU32LE()
+		r.ReadBytes(hash[:])
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		h.signed[signedRound{key: string(key[:]), height: height}] = hash
+		if height > maxHeight {
+			maxHeight = height
+		}
+	}
+	h.prune(maxHeight)
+
+	if err := h.rewrite(path); err != nil {
+		return nil, err
+	}
+	h.file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// rewrite atomically replaces the history file with the records kept in
+// memory.
+func (h *signedHistory) rewrite(path string) error {
+	w := io.NewBufBinWriter()
+	for r, hash := range h.signed {
+		encodeSignedRecord(w.BinWriter, r, hash)
+	}
+	if w.Err != nil {
+		return w.Err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(w.Bytes())
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir syncs the directory so that the file renamed in it is persisted.
+func syncDir(path string) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// get returns the hash of the block signed for the given round.
+func (h *signedHistory) get(r signedRound) (util.Uint256, bool) {
+	hash, ok := h.signed[r]
+	return hash, ok
+}
+
+// add writes the record to the history file and syncs it.
+func (h *signedHistory) add(r signedRound, hash util.Uint256) error {
+	if len(r.key) != signedKeySize {
+		return errors.New("public key is not compressed")
+	}
+	w := io.NewBufBinWriter()
+	encodeSignedRecord(w.BinWriter, r, hash)
+	if w.Err != nil {
+		return w.Err
+	}
+	if _, err := h.file.Write(w.Bytes()); err != nil {
+		return err
+	}
+	if err := h.file.Sync(); err != nil {
+		return err
+	}
+	h.signed[r] = hash
+	h.prune(r.height)
+	return nil
+}
+
+// prune forgets blocks signed too long ago.
+func (h *signedHistory) prune(height uint32) {
+	if height <= h.maxHeight {
+		return
+	}
+	h.maxHeight = height
+	if height < signedHistorySize {
+		return
+	}
+	for r := range h.signed {
+		if r.height <= height-signedHistorySize {
+			delete(h.signed, r)
+		}
+	}
+}
+
+// close closes the history file.
+func (h *signedHistory) close() error {
+	return h.file.Close()
+}
+
+func encodeSignedRecord(w *io.BinWriter, r signedRound, hash util.Uint256) {
+	w.WriteBytes([]byte(r.key))
+	w.WriteU32LE(r.height)
+	w.WriteBytes(hash[:])
+}
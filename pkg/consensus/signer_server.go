@@ -0,0 +1,236 @@
+package consensus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	coreb "github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"go.uber.org/zap"
+)
+
+// blockDataSize is the size of the block hashable data, see
+// block.Base.GetHashableData.
+const blockDataSize = 4 + 32 + 32 + 4 + 4 + 8 + 20
+
+// ErrDoubleSign is returned when the signer is asked to sign a block
+// different from the one already signed for the same height.
+var ErrDoubleSign = errors.New("another block was already signed at this height")
+
+// SignerServer is a remote signer daemon serving requests of RemoteSigner.
+// It refuses to sign two different blocks with the same key at the same
+// height, so that a compromised or misbehaving node can't make the validator
+// double-sign. Heights and views are taken from the data being signed, the
+// ones in the request must match them. Signed blocks are recorded in the
+// history file that is loaded on startup.
+type SignerServer struct {
+	signer Signer
+	log    *zap.Logger
+
+	lock    sync.Mutex
+	history *signedHistory
+}
+
+// NewSignerServer creates signer daemon using the given signer for keys and
+// the file at historyPath for signed blocks history.
+func NewSignerServer(signer Signer, historyPath string, log *zap.Logger) (*SignerServer, error) {
+	if historyPath == "" {
+		return nil, errors.New("no signed blocks history file")
+	}
+	history, err := openSignedHistory(historyPath)
+	if err != nil {
+		return nil, fmt.Errorf("can't open signed blocks history: %v", err)
+	}
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &SignerServer{
+		signer:  signer,
+		log:     log,
+		history: history,
+	}, nil
+}
+
+// Close closes the history file, it must be called after Serve returns.
+func (s *SignerServer) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.history.close()
+}
+
+// Serve accepts connections on the listener and serves signing requests,
+// it blocks until the listener is closed.
+func (s *SignerServer) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *SignerServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		resp := new(signerResponse)
+		req := new(signerRequest)
+		if err := json.Unmarshal(line, req); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp = s.handle(req)
+		}
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write(append(data, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+func (s *SignerServer) handle(req *signerRequest) *signerResponse {
+	resp := new(signerResponse)
+	if req.PublicKey == nil {
+		resp.Error = "no public key"
+		return resp
+	}
+	switch req.Method {
+	case signerMethodHasKey:
+		has, err := s.signer.HasKey(req.PublicKey)
+		if err != nil {
+			resp.Error = err.Error()
+			break
+		}
+		resp.HasKey = has
+	case signerMethodSign:
+		sreq := &SignRequest{
+			PublicKey: req.PublicKey,
+			Type:      req.Type,
+			Height:    req.Height,
+			View:      req.View,
+			Data:      req.Data,
+		}
+		sig, err := s.sign(sreq)
+		if err != nil {
+			s.log.Warn("refused to sign",
+				zap.Stringer("key", req.PublicKey),
+				zap.Stringer("type", req.Type),
+				zap.Uint32("height", req.Height),
+				zap.Uint("view", uint(req.View)),
+				zap.Error(err))
+			resp.Error = err.Error()
+			break
+		}
+		s.log.Info("signed",
+			zap.Stringer("key", req.PublicKey),
+			zap.Stringer("type", req.Type),
+			zap.Uint32("height", req.Height),
+			zap.Uint("view", uint(req.View)))
+		resp.Signature = sig
+	default:
+		resp.Error = fmt.Sprintf("unknown method %q", req.Method)
+	}
+	return resp
+}
+
+// sign checks the request against previously signed data and signs it.
+func (s *SignerServer) sign(req *SignRequest) ([]byte, error) {
+	var height uint32
+	switch req.Type {
+	case SignPayload:
+		p, err := decodePayloadData(req.Data)
+		if err != nil {
+			return nil, err
+		}
+		if p.Height() != req.Height || p.ViewNumber() != req.View {
+			return nil, fmt.Errorf("round mismatch: request %d/%d, payload %d/%d",
+				req.Height, req.View, p.Height(), p.ViewNumber())
+		}
+		return s.signer.Sign(req)
+	case SignBlock:
+		var err error
+		if height, err = decodeBlockData(req.Data); err != nil {
+			return nil, err
+		}
+		if height != req.Height {
+			return nil, fmt.Errorf("height mismatch: request %d, block %d", req.Height, height)
+		}
+	default:
+		return nil, fmt.Errorf("unknown sign type %s", req.Type)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	round := signedRound{
+		key:    string(req.PublicKey.Bytes()),
+		height: height,
+	}
+	h := hash.Sha256(req.Data)
+	prev, ok := s.history.get(round)
+	if ok && !prev.Equals(h) {
+		return nil, ErrDoubleSign
+	}
+	sig, err := s.signer.Sign(req)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.history.add(round, h); err != nil {
+			return nil, fmt.Errorf("can't save signed block: %v", err)
+		}
+	}
+	return sig, nil
+}
+
+// decodePayloadData decodes unsigned consensus payload. The data must be the
+// canonical payload encoding and can't have the size of the block data, so
+// that block headers can't be signed as payloads bypassing the checks.
+func decodePayloadData(data []byte) (*Payload, error) {
+	if len(data) == blockDataSize {
+		return nil, errors.New("payload data can't have the block data size")
+	}
+	p := new(Payload)
+	if err := p.UnmarshalUnsigned(data); err != nil {
+		return nil, fmt.Errorf("invalid payload: %v", err)
+	}
+	if !bytes.Equal(p.MarshalUnsigned(), data) {
+		return nil, errors.New("invalid payload: non-canonical encoding")
+	}
+	return p, nil
+}
+
+// decodeBlockData decodes block hashable data returning its index.
+func decodeBlockData(data []byte) (uint32, error) {
+	if len(data) != blockDataSize {
+		return 0, fmt.Errorf("invalid block data size %d", len(data))
+	}
+	var b coreb.Base
+	r := io.NewBinReaderFromBuf(data)
+	b.Version = r.ReadU32LE()
+	r.ReadBytes(b.PrevHash[:])
+	r.ReadBytes(b.MerkleRoot[:])
+	b.Timestamp = r.ReadU32LE()
+	b.Index = r.ReadU32LE()
+	b.ConsensusData = r.ReadU64LE()
+	r.ReadBytes(b.NextConsensus[:])
+	if r.Err != nil {
+		return 0, r.Err
+	}
+	return b.Index, nil
+}
@@ -0,0 +1,242 @@
+package consensus
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	coreb "github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestWalletSigner(t *testing.T) *WalletSigner {
+	w, err := wallet.NewWalletFromFile("./testdata/wallet1.json")
+	require.NoError(t, err)
+	defer w.Close()
+
+	return NewWalletSigner(w, "one")
+}
+
+func TestWalletSigner(t *testing.T) {
+	s := newTestWalletSigner(t)
+
+	_, pub := getTestValidator(2)
+	has, err := s.HasKey(pub.PublicKey)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	_, other := getTestValidator(0)
+	has, err = s.HasKey(other.PublicKey)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	data := []byte{1, 2, 3}
+	sig, err := s.Sign(&SignRequest{PublicKey: pub.PublicKey, Data: data})
+	require.NoError(t, err)
+	require.NoError(t, pub.Verify(data, sig))
+
+	_, err = s.Sign(&SignRequest{PublicKey: other.PublicKey, Data: data})
+	require.Error(t, err)
+}
+
+func TestParseSignerAddress(t *testing.T) {
+	network, address, err := ParseSignerAddress("unix:/tmp/signer.sock")
+	require.NoError(t, err)
+	require.Equal(t, "unix", network)
+	require.Equal(t, "/tmp/signer.sock", address)
+
+	for _, addr := range []string{"", "/tmp/signer.sock", "unix:", "udp:localhost:10400", "tcp:localhost:10400"} {
+		_, _, err := ParseSignerAddress(addr)
+		require.Error(t, err, addr)
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neogo.signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sock := path.Join(dir, "signer.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	defer l.Close()
+
+	srv, err := NewSignerServer(newTestWalletSigner(t), path.Join(dir, "signed"), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer srv.Close()
+	go func() { _ = srv.Serve(l) }()
+
+	s, err := NewRemoteSigner("unix:"+sock, defaultRemoteSignerTimeout)
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, pub := getTestValidator(2)
+	_, other := getTestValidator(0)
+	has, err := s.HasKey(pub.PublicKey)
+	require.NoError(t, err)
+	require.True(t, has)
+	has, err = s.HasKey(other.PublicKey)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	var (
+		height uint32 = 5
+		view   byte
+	)
+	key := &signerKey{
+		signer: s,
+		pub:    pub.PublicKey,
+		round:  func() (uint32, byte) { return height, view },
+	}
+
+	t.Run("payload", func(t *testing.T) {
+		p := randomPayload(t, prepareResponseType)
+		require.NoError(t, key.signPayload(p))
+		require.True(t, p.Verify(pub.GetScriptHash()))
+	})
+
+	t.Run("block", func(t *testing.T) {
+		b := &coreb.Base{Index: height, Timestamp: 1}
+		data := b.GetHashableData()
+		sig, err := key.Sign(data)
+		require.NoError(t, err)
+		require.NoError(t, pub.Verify(data, sig))
+
+		// Signing the same block again is fine.
+		_, err = key.Sign(data)
+		require.NoError(t, err)
+
+		b.Timestamp++
+		_, err = key.Sign(b.GetHashableData())
+		require.Error(t, err)
+
+		// View is not a part of the block, another block can't be signed
+		// at the same height after view change.
+		view++
+		_, err = key.Sign(b.GetHashableData())
+		require.Error(t, err)
+
+		// Height must match the block.
+		height++
+		_, err = key.Sign(b.GetHashableData())
+		require.Error(t, err)
+		b.Index = height
+		_, err = key.Sign(b.GetHashableData())
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		sign := func(data []byte) error {
+			_, err := s.Sign(&SignRequest{
+				PublicKey: pub.PublicKey,
+				Type:      SignPayload,
+				Height:    height,
+				Data:      data,
+			})
+			return err
+		}
+
+		// Block can't be signed as a payload.
+		b := &coreb.Base{Index: height, Timestamp: 2}
+		require.Error(t, sign(b.GetHashableData()))
+
+		p := randomPayload(t, prepareResponseType)
+		p.SetHeight(height)
+		p.SetViewNumber(0)
+		data := p.MarshalUnsigned()
+		require.NoError(t, sign(data))
+		require.Error(t, sign(append(data, 0)))
+
+		p.SetHeight(height + 1)
+		require.Error(t, sign(p.MarshalUnsigned()))
+	})
+}
+
+func TestSignerServerRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neogo.signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewSignerServer(newTestWalletSigner(t), "", nil)
+	require.Error(t, err)
+
+	history := path.Join(dir, "signed")
+	srv, err := NewSignerServer(newTestWalletSigner(t), history, nil)
+	require.NoError(t, err)
+
+	_, pub := getTestValidator(2)
+	signBlock := func(srv *SignerServer, b *coreb.Base) error {
+		_, err := srv.sign(&SignRequest{
+			PublicKey: pub.PublicKey,
+			Type:      SignBlock,
+			Height:    b.Index,
+			Data:      b.GetHashableData(),
+		})
+		return err
+	}
+	b1 := &coreb.Base{Index: 5, Timestamp: 1}
+	b2 := &coreb.Base{Index: 5, Timestamp: 2}
+	require.NoError(t, signBlock(srv, b1))
+	require.NoError(t, srv.Close())
+
+	// Interrupted write leaves an incomplete record.
+	f, err := os.OpenFile(history, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	srv, err = NewSignerServer(newTestWalletSigner(t), history, nil)
+	require.NoError(t, err)
+	require.Equal(t, ErrDoubleSign, signBlock(srv, b2))
+	require.NoError(t, signBlock(srv, b1))
+	require.NoError(t, signBlock(srv, &coreb.Base{Index: 6, Timestamp: 2}))
+	require.NoError(t, srv.Close())
+
+	data, err := ioutil.ReadFile(history)
+	require.NoError(t, err)
+	require.Equal(t, 2*signedRecordSize, len(data))
+
+	srv, err = NewSignerServer(newTestWalletSigner(t), history, nil)
+	require.NoError(t, err)
+	defer srv.Close()
+	require.Equal(t, ErrDoubleSign, signBlock(srv, &coreb.Base{Index: 6, Timestamp: 3}))
+}
+
+func TestRemoteSignerHasKeyCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neogo.signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sock := path.Join(dir, "signer.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	srv, err := NewSignerServer(newTestWalletSigner(t), path.Join(dir, "signed"), nil)
+	require.NoError(t, err)
+	defer srv.Close()
+	go func() { _ = srv.Serve(l) }()
+
+	s, err := NewRemoteSigner("unix:"+sock, defaultRemoteSignerTimeout)
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, pub := getTestValidator(2)
+	_, other := getTestValidator(0)
+	has, err := s.HasKey(pub.PublicKey)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	// Daemon is gone, cached answers are still available.
+	require.NoError(t, l.Close())
+	require.NoError(t, s.Close())
+	has, err = s.HasKey(pub.PublicKey)
+	require.NoError(t, err)
+	require.True(t, has)
+	_, err = s.HasKey(other.PublicKey)
+	require.Error(t, err)
+}
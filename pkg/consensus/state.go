@@ -0,0 +1,186 @@
+package consensus
+
+import (
+	"github.com/nspcc-dev/dbft"
+	"github.com/nspcc-dev/dbft/payload"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+)
+
+// stateHistorySize is the number of previous heights for which final
+// consensus state is kept.
+const stateHistorySize = 16
+
+// Round stages reported in State.
+const (
+	StageWaitingRequest      = "waiting for prepare request"
+	StageWaitingTransactions = "waiting for transactions"
+	StageWaitingPreparations = "waiting for preparations"
+	StageWaitingCommits      = "waiting for commits"
+	StageAccepted            = "block accepted"
+)
+
+// State is a snapshot of the dBFT state machine for some height.
+type State struct {
+	Height    uint32
+	View      byte
+	Primary   uint16
+	WatchOnly bool
+	// Stage is a short description of what the round is waiting for.
+	Stage string
+	// MissingTransactions is the number of proposed transactions that
+	// are not available locally.
+	MissingTransactions int
+	Validators          []ValidatorState
+}
+
+// ValidatorState describes messages received from a single validator in the
+// current view.
+type ValidatorState struct {
+	PublicKey *keys.PublicKey
+	Prepared  bool
+	Committed bool
+	// ChangeView is not nil if the validator has requested a view change.
+	ChangeView *ChangeViewState
+}
+
+// ChangeViewState describes the view change request. NEO 2 ChangeView
+// messages don't carry a reason, so only its view and timestamp are
+// reported.
+type ChangeViewState struct {
+	NewView   byte
+	Timestamp uint32
+}
+
+// Preparations returns the number of validators that have sent preparations.
+func (st *State) Preparations() int {
+	var n int
+	for i := range st.Validators {
+		if st.Validators[i].Prepared {
+			n++
+		}
+	}
+	return n
+}
+
+// Commits returns the number of validators that have sent commits.
+func (st *State) Commits() int {
+	var n int
+	for i := range st.Validators {
+		if st.Validators[i].Committed {
+			n++
+		}
+	}
+	return n
+}
+
+// ChangeViews returns the number of validators that have requested a view
+// change.
+func (st *State) ChangeViews() int {
+	var n int
+	for i := range st.Validators {
+		if st.Validators[i].ChangeView != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// newState creates State from the current dBFT context. It must be called
+// from the goroutine processing dBFT events.
+func newState(d *dbft.DBFT) *State {
+	ctx := d.Context
+	pubs := convertKeys(ctx.Validators)
+	st := &State{
+		Height:              ctx.BlockIndex,
+		View:                ctx.ViewNumber,
+		Primary:             uint16(ctx.PrimaryIndex),
+		WatchOnly:           d.WatchOnly(),
+		MissingTransactions: len(ctx.MissingTransactions),
+		Validators:          make([]ValidatorState, len(pubs)),
+	}
+	isCurrent := func(ps []payload.ConsensusPayload, i int) bool {
+		return i < len(ps) && ps[i] != nil && ps[i].ViewNumber() == ctx.ViewNumber
+	}
+	for i := range pubs {
+		v := &st.Validators[i]
+		v.PublicKey = pubs[i]
+		v.Prepared = isCurrent(ctx.PreparationPayloads, i)
+		v.Committed = isCurrent(ctx.CommitPayloads, i)
+		if i < len(ctx.ChangeViewPayloads) && ctx.ChangeViewPayloads[i] != nil {
+			cv := ctx.ChangeViewPayloads[i].GetChangeView()
+			if cv.NewViewNumber() > ctx.ViewNumber {
+				v.ChangeView = &ChangeViewState{
+					NewView:   cv.NewViewNumber(),
+					Timestamp: cv.Timestamp(),
+				}
+			}
+		}
+	}
+
+	m := d.M()
+	switch {
+	case int(st.Primary) >= len(st.Validators) || !st.Validators[st.Primary].Prepared:
+		st.Stage = StageWaitingRequest
+	case st.MissingTransactions != 0:
+		st.Stage = StageWaitingTransactions
+	case st.Preparations() < m:
+		st.Stage = StageWaitingPreparations
+	case st.Commits() < m:
+		st.Stage = StageWaitingCommits
+	default:
+		st.Stage = StageAccepted
+	}
+	return st
+}
+
+// updateState refreshes consensus state snapshot and metrics. The last
+// state of the previous height is moved into history.
+func (s *service) updateState() {
+	st := newState(s.dbft)
+
+	if s.state == nil || s.state.Height != st.Height {
+		s.addToJournal(&JournalEntry{
+			Type:       JournalRound,
+			Height:     st.Height,
+			View:       st.View,
+			PrevHash:   s.dbft.PrevHash,
+			Validators: convertKeys(s.dbft.Validators),
+		})
+	}
+
+	s.stateLock.Lock()
+	if s.state != nil && s.state.Height != st.Height {
+		if len(s.history) == stateHistorySize {
+			s.history = s.history[1:]
+		}
+		s.history = append(s.history, s.state)
+	}
+	s.state = st
+	s.stateLock.Unlock()
+
+	updateStateMetrics(st)
+}
+
+// State implements Service interface.
+func (s *service) State() *State {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+
+	return s.state
+}
+
+// StateAt implements Service interface.
+func (s *service) StateAt(height uint32) *State {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+
+	if s.state != nil && s.state.Height == height {
+		return s.state
+	}
+	for _, st := range s.history {
+		if st.Height == height {
+			return st
+		}
+	}
+	return nil
+}
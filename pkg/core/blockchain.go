@@ -3,7 +3,6 @@ package core
 import (
 	"fmt"
 	"math"
-	"math/big"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -40,6 +39,13 @@ const (
 	registeredAssetLifetime = 2 * 2000000
 
 	defaultMemPoolSize = 50000
+
+	// witnessCacheFactor is the number of witness cache entries per mempool
+	// transaction, most transactions have one or two witnesses.
+	witnessCacheFactor = 2
+
+	// programCacheSize is the number of pre-decoded contract scripts kept.
+	programCacheSize = 1000
 )
 
 var (
@@ -113,12 +119,28 @@ type Blockchain struct {
 	runToExitCh chan struct{}
 
 	memPool mempool.Pool
+	// pendingRestore contains saved memory pool transactions that were not
+	// restored before shutdown, they're saved again along with the pool.
+	pendingRestore []mempool.TxWithFee
 
 	// This lock protects concurrent access to keyCache.
 	keyCacheLock sync.RWMutex
 	// cache for block verification keys.
 	keyCache map[util.Uint160]map[string]*keys.PublicKey
 
+	// witnessCache stores successful witness checks made for mempool
+	// transactions, so that they're not repeated when the block is added.
+	witnessCache *witnessCache
+
+	// programs caches pre-decoded contract scripts.
+	programs *vm.ProgramCache
+
+	// This lock protects customInterops.
+	interopsLock sync.RWMutex
+	// customInterops are sorted interop functions registered via
+	// RegisterInterop.
+	customInterops []interopedFunction
+
 	log *zap.Logger
 
 	lastBatch *storage.MemBatch
@@ -162,6 +184,8 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 		runToExitCh:   make(chan struct{}),
 		memPool:       mempool.NewMemPool(cfg.MemPoolSize),
 		keyCache:      make(map[util.Uint160]map[string]*keys.PublicKey),
+		witnessCache:  newWitnessCache(witnessCacheFactor * cfg.MemPoolSize),
+		programs:      vm.NewProgramCache(programCacheSize),
 		log:           log,
 
 		generationAmount:  genAmount,
@@ -269,6 +293,11 @@ func (bc *Blockchain) Run() {
 	persistTimer := time.NewTimer(persistInterval)
 	defer func() {
 		persistTimer.Stop()
+		if bc.config.SaveMemPool {
+			if err := bc.saveMemPool(); err != nil {
+				bc.log.Warn("failed to save memory pool", zap.Error(err))
+			}
+		}
 		if err := bc.persist(); err != nil {
 			bc.log.Warn("failed to persist", zap.Error(err))
 		}
@@ -277,10 +306,32 @@ func (bc *Blockchain) Run() {
 		}
 		close(bc.runToExitCh)
 	}()
+	restored := make(chan struct{})
+	if bc.config.SaveMemPool {
+		// Verification needs headers operations to be processed, so it
+		// can't be done synchronously here.
+		go func() {
+			bc.restoreMemPool()
+			close(restored)
+		}()
+	} else {
+		close(restored)
+	}
 	for {
 		select {
 		case <-bc.stopCh:
-			return
+			// Memory pool restoration is stopped and waited for before
+			// saving the pool and closing the store, it needs headers
+			// operations to be processed until then.
+			for {
+				select {
+				case <-restored:
+					return
+				case op := <-bc.headersOp:
+					op(bc.headerList)
+					bc.headersOpDone <- struct{}{}
+				}
+			}
 		case op := <-bc.headersOp:
 			op(bc.headerList)
 			bc.headersOpDone <- struct{}{}
@@ -303,6 +354,72 @@ func (bc *Blockchain) Close() {
 	<-bc.runToExitCh
 }
 
+// saveMemPool stores verified memory pool transactions along with their
+// fees, so that they can be restored after restart.
+func (bc *Blockchain) saveMemPool() error {
+	txes := append(bc.memPool.GetVerifiedTransactions(), bc.pendingRestore...)
+	buf := io.NewBufBinWriter()
+	buf.WriteArray(txes)
+	if buf.Err != nil {
+		return buf.Err
+	}
+	bc.log.Info("saving memory pool", zap.Int("transactions", len(txes)))
+	return bc.dao.Store.Put(storage.SYSMemPool.Bytes(), buf.Bytes())
+}
+
+// restoreMemPool adds transactions saved by saveMemPool back to the memory
+// pool. Every transaction is verified against the current chain state and
+// the ones that are no longer valid are dropped.
+func (bc *Blockchain) restoreMemPool() {
+	key := storage.SYSMemPool.Bytes()
+	data, err := bc.dao.Store.Get(key)
+	if err != nil {
+		if err != storage.ErrKeyNotFound {
+			bc.log.Warn("failed to read saved memory pool", zap.Error(err))
+		}
+		return
+	}
+
+	var txes []mempool.TxWithFee
+	r := io.NewBinReaderFromBuf(data)
+	r.ReadArray(&txes)
+	if r.Err != nil {
+		bc.log.Warn("failed to decode saved memory pool", zap.Error(r.Err))
+		txes = nil
+	}
+
+	// Add most profitable transactions first, so that they're kept if the
+	// pool can't fit all of them.
+	sort.SliceStable(txes, func(i, j int) bool { return txes[i].Fee > txes[j].Fee })
+	var restored int
+	for i := range txes {
+		select {
+		case <-bc.stopCh:
+			bc.pendingRestore = txes[i:]
+			bc.log.Info("memory pool restoration interrupted",
+				zap.Int("saved", len(txes)),
+				zap.Int("restored", restored))
+			return
+		default:
+		}
+		if err := bc.PoolTx(txes[i].Tx); err != nil {
+			bc.log.Debug("dropping saved transaction",
+				zap.Stringer("hash", txes[i].Tx.Hash()),
+				zap.Error(err))
+			continue
+		}
+		restored++
+	}
+	if err := bc.dao.Store.Delete(key); err != nil {
+		bc.log.Warn("failed to delete saved memory pool", zap.Error(err))
+	}
+
+	updateMemPoolRestoredMetric(restored)
+	bc.log.Info("memory pool restored",
+		zap.Int("saved", len(txes)),
+		zap.Int("restored", restored))
+}
+
 // AddBlock accepts successive block for the Blockchain, verifies it and
 // stores internally. Eventually it will be persisted to the backing storage.
 func (bc *Blockchain) AddBlock(block *block.Block) error {
@@ -327,11 +444,9 @@ func (bc *Blockchain) AddBlock(block *block.Block) error {
 			return fmt.Errorf("block %s is invalid: %s", block.Hash().StringLE(), err)
 		}
 		if bc.config.VerifyTransactions {
-			for _, tx := range block.Transactions {
-				err := bc.VerifyTx(tx, block)
-				if err != nil {
-					return fmt.Errorf("transaction %s failed to verify: %s", tx.Hash().StringLE(), err)
-				}
+			err := bc.verifyBlockTxs(block)
+			if err != nil {
+				return err
 			}
 		}
 	}
@@ -371,12 +486,17 @@ func (bc *Blockchain) addHeaders(verify bool, headers ...*block.Header) (err err
 		if lastHeader, err = bc.GetHeader(headers[0].PrevHash); err != nil {
 			return fmt.Errorf("previous header was not found: %v", err)
 		}
+		checks := make([]witnessCheck, 0, len(headers))
 		for _, h := range headers {
 			if err = bc.verifyHeader(h, lastHeader); err != nil {
 				return
 			}
+			checks = append(checks, bc.headerWitnessCheck(h, lastHeader))
 			lastHeader = h
 		}
+		if i, verr := bc.verifyWitnesses(checks, false); verr != nil {
+			return fmt.Errorf("header %d witness check failed: %s", headers[i].Index, verr)
+		}
 	}
 
 	bc.headersOp <- func(headerList *HeaderHashList) {
@@ -458,7 +578,15 @@ func (bc *Blockchain) getSystemFeeAmount(h util.Uint256) uint32 {
 // is happening here, quite allot as you can see :). If things are wired together
 // and all tests are in place, we can make a more optimized and cleaner implementation.
 func (bc *Blockchain) storeBlock(block *block.Block) error {
+	// blockDAO collects all changes made by the block to calculate the state
+	// digest before they go to bc.dao, it's only used if state roots are
+	// enabled.
+	var blockDAO *dao.Simple
 	cache := dao.NewCached(bc.dao)
+	if bc.config.StateRootEnabled {
+		blockDAO = dao.NewSimple(bc.dao.Store)
+		cache = dao.NewCached(blockDAO)
+	}
 	fee := bc.getSystemFeeAmount(block.PrevHash)
 	for _, tx := range block.Transactions {
 		fee += uint32(bc.SystemFee(tx).IntegralValue())
@@ -672,30 +800,10 @@ func (bc *Blockchain) storeBlock(block *block.Block) error {
 					return errors.Wrap(err, "failed to persist invocation results")
 				}
 				for _, note := range systemInterop.notifications {
-					arr, ok := note.Item.Value().([]vm.StackItem)
-					if !ok || len(arr) != 4 {
-						continue
-					}
-					op, ok := arr[0].Value().([]byte)
-					if !ok || string(op) != "transfer" {
-						continue
-					}
-					from, ok := arr[1].Value().([]byte)
-					if !ok {
-						continue
-					}
-					to, ok := arr[2].Value().([]byte)
+					from, to, amount, ok := note.NEP5Transfer()
 					if !ok {
 						continue
 					}
-					amount, ok := arr[3].Value().(*big.Int)
-					if !ok {
-						bs, ok := arr[3].Value().([]byte)
-						if !ok {
-							continue
-						}
-						amount = emit.BytesToInt(bs)
-					}
 					bc.processNEP5Transfer(cache, tx, block, note.ScriptHash, from, to, amount.Int64())
 				}
 			} else {
@@ -729,6 +837,15 @@ func (bc *Blockchain) storeBlock(block *block.Block) error {
 	if err != nil {
 		return err
 	}
+	if blockDAO != nil {
+		if err = storeStateRoot(blockDAO, block.Index); err != nil {
+			return errors.Wrap(err, "failed to store state root")
+		}
+		_, err = blockDAO.Persist()
+		if err != nil {
+			return err
+		}
+	}
 	bc.topBlock.Store(block)
 	atomic.StoreUint32(&bc.blockHeight, block.Index)
 	updateBlockHeightMetric(block.Index)
@@ -1032,6 +1149,14 @@ func (bc *Blockchain) GetAppExecResult(hash util.Uint256) (*state.AppExecResult,
 	return bc.dao.GetAppExecResult(hash)
 }
 
+// GetStateRoot returns the state digest of the block at the given height.
+func (bc *Blockchain) GetStateRoot(height uint32) (util.Uint256, error) {
+	if !bc.config.StateRootEnabled {
+		return util.Uint256{}, errors.New("state roots are disabled")
+	}
+	return bc.dao.GetStateRoot(height)
+}
+
 // GetStorageItem returns an item from storage.
 func (bc *Blockchain) GetStorageItem(scripthash util.Uint160, key []byte) *state.StorageItem {
 	return bc.dao.GetStorageItem(scripthash, key)
@@ -1301,6 +1426,22 @@ func (bc *Blockchain) IsLowPriority(fee util.Fixed8) bool {
 	return fee < util.Fixed8FromFloat(bc.GetConfig().LowPriorityThreshold)
 }
 
+// MinNetworkFee returns the minimum network fee transaction of the given size
+// must have to be accepted into the memory pool. Transactions not bigger than
+// MaxFreeTransactionSize can be sent for free, bigger ones need to pay
+// FeePerExtraByte for every extra byte and not be low priority.
+func (bc *Blockchain) MinNetworkFee(size int) util.Fixed8 {
+	maxFree := bc.config.MaxFreeTransactionSize
+	if maxFree == 0 || size <= maxFree {
+		return 0
+	}
+	fee := util.Fixed8FromFloat(bc.config.FeePerExtraByte) * util.Fixed8(size-maxFree)
+	if threshold := util.Fixed8FromFloat(bc.config.LowPriorityThreshold); fee < threshold {
+		fee = threshold
+	}
+	return fee
+}
+
 // GetMemPool returns the memory pool of the blockchain.
 func (bc *Blockchain) GetMemPool() *mempool.Pool {
 	return &bc.memPool
@@ -1334,11 +1475,53 @@ func (bc *Blockchain) verifyHeader(currHeader, prevHeader *block.Header) error {
 	if prevHeader.Timestamp >= currHeader.Timestamp {
 		return errors.New("block is not newer than the previous one")
 	}
-	return bc.verifyHeaderWitnesses(currHeader, prevHeader)
+	return nil
 }
 
 // verifyTx verifies whether a transaction is bonafide or not.
 func (bc *Blockchain) verifyTx(t *transaction.Transaction, block *block.Block) error {
+	if err := bc.verifyTxState(t, block); err != nil {
+		return err
+	}
+	return bc.verifyTxWitnesses(t, block)
+}
+
+// verifyBlockTxs verifies all transactions of the block. Checks depending on
+// the chain state are done sequentially, while witnesses of all transactions
+// are verified as a single batch.
+func (bc *Blockchain) verifyBlockTxs(block *block.Block) error {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	var (
+		checks []witnessCheck
+		// txIndex and witIndex map checks to transactions and witnesses.
+		txIndex, witIndex []int
+	)
+	for i, tx := range block.Transactions {
+		err := bc.verifyTxState(tx, block)
+		if err == nil {
+			var txChecks []witnessCheck
+			txChecks, err = bc.txWitnessChecks(tx, block)
+			for j := range txChecks {
+				txIndex = append(txIndex, i)
+				witIndex = append(witIndex, j)
+			}
+			checks = append(checks, txChecks...)
+		}
+		if err != nil {
+			return fmt.Errorf("transaction %s failed to verify: %s", tx.Hash().StringLE(), err)
+		}
+	}
+	if i, err := bc.verifyWitnesses(checks, false); err != nil {
+		tx := block.Transactions[txIndex[i]]
+		return fmt.Errorf("transaction %s failed to verify: witness #%d: %s", tx.Hash().StringLE(), witIndex[i], err)
+	}
+	return nil
+}
+
+// verifyTxState does all transaction checks except for witnesses verification.
+func (bc *Blockchain) verifyTxState(t *transaction.Transaction, block *block.Block) error {
 	if io.GetVarSize(t) > transaction.MaxTransactionSize {
 		return errors.Errorf("invalid transaction size = %d. It shoud be less then MaxTransactionSize = %d", io.GetVarSize(t), transaction.MaxTransactionSize)
 	}
@@ -1447,7 +1630,7 @@ func (bc *Blockchain) verifyTx(t *transaction.Transaction, block *block.Block) e
 		}
 	}
 
-	return bc.verifyTxWitnesses(t, block)
+	return nil
 }
 
 func (bc *Blockchain) verifyClaims(tx *transaction.Transaction, results []*transaction.Result) (err error) {
@@ -1576,14 +1759,8 @@ func (bc *Blockchain) PoolTx(t *transaction.Transaction) error {
 	}
 	// Policying.
 	if t.Type != transaction.ClaimType {
-		txSize := io.GetVarSize(t)
-		maxFree := bc.config.MaxFreeTransactionSize
-		if maxFree != 0 && txSize > maxFree {
-			netFee := bc.NetworkFee(t)
-			if bc.IsLowPriority(netFee) ||
-				netFee < util.Fixed8FromFloat(bc.config.FeePerExtraByte)*util.Fixed8(txSize-maxFree) {
-				return ErrPolicy
-			}
+		if bc.NetworkFee(t) < bc.MinNetworkFee(io.GetVarSize(t)) {
+			return ErrPolicy
 		}
 	}
 	if err := bc.memPool.Add(t, bc); err != nil {
@@ -2000,8 +2177,12 @@ func (bc *Blockchain) GetScriptHashesForVerifying(t *transaction.Transaction) ([
 }
 
 // GetTestVM returns a VM and a Store setup for a test run of some sort of code.
-func (bc *Blockchain) GetTestVM() *vm.VM {
-	systemInterop := bc.newInteropContext(trigger.Application, bc.dao, nil, nil)
+// tx (if it's not nil) is used as a script container, so that witnesses can
+// be checked against it. Side effects of the run (contract calls, storage
+// accesses and notifications) are collected into effects if it's not nil.
+func (bc *Blockchain) GetTestVM(tx *transaction.Transaction, effects *state.Effects) *vm.VM {
+	systemInterop := bc.newInteropContext(trigger.Application, bc.dao, nil, tx)
+	systemInterop.effects = effects
 	vm := systemInterop.SpawnVM()
 	vm.SetPriceGetter(getPrice)
 	return vm
@@ -2078,39 +2259,61 @@ func (bc *Blockchain) verifyHashAgainstScript(hash util.Uint160, witness *transa
 // Golang implementation of VerifyWitnesses method in C# (https://github.com/neo-project/neo/blob/master/neo/SmartContract/Helper.cs#L87).
 // Unfortunately the IVerifiable interface could not be implemented because we can't move the References method in blockchain.go to the transaction.go file.
 func (bc *Blockchain) verifyTxWitnesses(t *transaction.Transaction, block *block.Block) error {
-	hashes, err := bc.GetScriptHashesForVerifying(t)
+	checks, err := bc.txWitnessChecks(t, block)
 	if err != nil {
 		return err
 	}
+	// Transactions not yet in a block are the ones worth caching.
+	if i, err := bc.verifyWitnesses(checks, block == nil); err != nil {
+		numStr := fmt.Sprintf("witness #%d", i)
+		return errors.Wrap(err, numStr)
+	}
+	return nil
+}
+
+// txWitnessChecks returns witness checks for the given transaction.
+func (bc *Blockchain) txWitnessChecks(t *transaction.Transaction, block *block.Block) ([]witnessCheck, error) {
+	hashes, err := bc.GetScriptHashesForVerifying(t)
+	if err != nil {
+		return nil, err
+	}
 
 	witnesses := t.Scripts
 	if len(hashes) != len(witnesses) {
-		return errors.Errorf("expected len(hashes) == len(witnesses). got: %d != %d", len(hashes), len(witnesses))
+		return nil, errors.Errorf("expected len(hashes) == len(witnesses). got: %d != %d", len(hashes), len(witnesses))
 	}
 	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Less(hashes[j]) })
 	sort.Slice(witnesses, func(i, j int) bool { return witnesses[i].ScriptHash().Less(witnesses[j].ScriptHash()) })
 	interopCtx := bc.newInteropContext(trigger.Verification, bc.dao, block, t)
-	for i := 0; i < len(hashes); i++ {
-		err := bc.verifyHashAgainstScript(hashes[i], &witnesses[i], t.VerificationHash(), interopCtx, false)
-		if err != nil {
-			numStr := fmt.Sprintf("witness #%d", i)
-			return errors.Wrap(err, numStr)
+	checks := make([]witnessCheck, len(hashes))
+	for i := range hashes {
+		checks[i] = witnessCheck{
+			tx:         t.Hash(),
+			hash:       hashes[i],
+			witness:    &witnesses[i],
+			checked:    t.VerificationHash(),
+			interopCtx: interopCtx,
 		}
 	}
-
-	return nil
+	return checks, nil
 }
 
-// verifyHeaderWitnesses is a block-specific implementation of VerifyWitnesses logic.
-func (bc *Blockchain) verifyHeaderWitnesses(currHeader, prevHeader *block.Header) error {
+// headerWitnessCheck is a block-specific implementation of VerifyWitnesses
+// logic, it returns witness check for the given header.
+func (bc *Blockchain) headerWitnessCheck(currHeader, prevHeader *block.Header) witnessCheck {
 	var hash util.Uint160
 	if prevHeader == nil && currHeader.PrevHash.Equals(util.Uint256{}) {
 		hash = currHeader.Script.ScriptHash()
 	} else {
 		hash = prevHeader.NextConsensus
 	}
-	interopCtx := bc.newInteropContext(trigger.Verification, bc.dao, nil, nil)
-	return bc.verifyHashAgainstScript(hash, &currHeader.Script, currHeader.VerificationHash(), interopCtx, true)
+	return witnessCheck{
+		hash:       hash,
+		witness:    &currHeader.Script,
+		checked:    currHeader.VerificationHash(),
+		interopCtx: bc.newInteropContext(trigger.Verification, bc.dao, nil, nil),
+		useKeys:    true,
+	}
 }
 
 func hashAndIndexToBytes(h util.Uint256, index uint32) []byte {
@@ -2125,5 +2328,8 @@ func (bc *Blockchain) secondsPerBlock() int {
 }
 
 func (bc *Blockchain) newInteropContext(trigger trigger.Type, d dao.DAO, block *block.Block, tx *transaction.Transaction) *interopContext {
-	return newInteropContext(trigger, bc, d, block, tx, bc.log)
+	ic := newInteropContext(trigger, bc, d, block, tx, bc.log)
+	ic.custom = bc.getCustomInterops()
+	ic.programs = bc.programs
+	return ic
 }
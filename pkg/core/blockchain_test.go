@@ -3,7 +3,9 @@ package core
 import (
 	"testing"
 
+	"github.com/nspcc-dev/neo-go/pkg/config"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
 	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
@@ -11,6 +13,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
 )
 
 func TestAddHeaders(t *testing.T) {
@@ -212,6 +215,77 @@ func TestGetClaimable(t *testing.T) {
 	})
 }
 
+func TestSaveRestoreMemPool(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	newMinerTx := func(nonce uint32) *transaction.Transaction {
+		return &transaction.Transaction{
+			Type: transaction.MinerType,
+			Data: &transaction.MinerTX{Nonce: nonce},
+		}
+	}
+	tx1, tx2 := newMinerTx(1), newMinerTx(2)
+	require.NoError(t, bc.PoolTx(tx1))
+	require.NoError(t, bc.PoolTx(tx2))
+	require.NoError(t, bc.saveMemPool())
+
+	// Simulate restart with tx2 included into the chain.
+	bc.memPool = mempool.NewMemPool(bc.config.MemPoolSize)
+	require.NoError(t, bc.dao.StoreAsTransaction(tx2, 0))
+
+	bc.restoreMemPool()
+	require.True(t, bc.memPool.ContainsKey(tx1.Hash()))
+	require.False(t, bc.memPool.ContainsKey(tx2.Hash()))
+	require.Equal(t, 1, bc.memPool.Count())
+
+	_, err := bc.dao.Store.Get(storage.SYSMemPool.Bytes())
+	require.Equal(t, storage.ErrKeyNotFound, err)
+
+	t.Run("interrupted", func(t *testing.T) {
+		cfg, err := config.Load("../../config", config.ModeUnitTestNet)
+		require.NoError(t, err)
+		chain, err := NewBlockchain(storage.NewMemoryStore(), cfg.ProtocolConfiguration, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		defer chain.dao.Store.Close()
+
+		require.NoError(t, chain.memPool.Add(tx1, chain))
+		require.NoError(t, chain.saveMemPool())
+		chain.memPool = mempool.NewMemPool(chain.config.MemPoolSize)
+
+		// Transactions not restored before shutdown are saved again.
+		close(chain.stopCh)
+		chain.restoreMemPool()
+		require.Equal(t, 0, chain.memPool.Count())
+		require.NoError(t, chain.saveMemPool())
+		data, err := chain.dao.Store.Get(storage.SYSMemPool.Bytes())
+		require.NoError(t, err)
+		var txes []mempool.TxWithFee
+		r := io.NewBinReaderFromBuf(data)
+		r.ReadArray(&txes)
+		require.NoError(t, r.Err)
+		require.Equal(t, 1, len(txes))
+		require.Equal(t, tx1.Hash(), txes[0].Tx.Hash())
+	})
+}
+
+func TestMinNetworkFee(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	require.Equal(t, util.Fixed8(0), bc.MinNetworkFee(100500))
+
+	bc.config.MaxFreeTransactionSize = 1024
+	bc.config.FeePerExtraByte = 0.00001
+	require.Equal(t, util.Fixed8(0), bc.MinNetworkFee(1024))
+	require.Equal(t, util.Fixed8FromFloat(0.01), bc.MinNetworkFee(2024))
+
+	bc.config.LowPriorityThreshold = 0.001
+	require.Equal(t, util.Fixed8(0), bc.MinNetworkFee(1000))
+	require.Equal(t, util.Fixed8FromFloat(0.001), bc.MinNetworkFee(1034))
+	require.Equal(t, util.Fixed8FromFloat(0.01), bc.MinNetworkFee(2024))
+}
+
 func TestClose(t *testing.T) {
 	defer func() {
 		r := recover()
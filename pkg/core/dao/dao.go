@@ -34,6 +34,7 @@ type DAO interface {
 	GetHeaderHashes() ([]util.Uint256, error)
 	GetNEP5Balances(acc util.Uint160) (*state.NEP5Balances, error)
 	GetNEP5TransferLog(acc util.Uint160, index uint32) (*state.NEP5TransferLog, error)
+	GetStateRoot(height uint32) (util.Uint256, error)
 	GetStorageItem(scripthash util.Uint160, key []byte) *state.StorageItem
 	GetStorageItems(hash util.Uint160) (map[string]*state.StorageItem, error)
 	GetTransaction(hash util.Uint256) (*transaction.Transaction, uint32, error)
@@ -55,6 +56,7 @@ type DAO interface {
 	PutCurrentHeader(hashAndIndex []byte) error
 	PutNEP5Balances(acc util.Uint160, bs *state.NEP5Balances) error
 	PutNEP5TransferLog(acc util.Uint160, index uint32, lg *state.NEP5TransferLog) error
+	PutStateRoot(height uint32, root util.Uint256) error
 	PutStorageItem(scripthash util.Uint160, key []byte, si *state.StorageItem) error
 	PutUnspentCoinState(hash util.Uint256, ucs *state.UnspentCoin) error
 	PutValidatorState(vs *state.Validator) error
@@ -404,6 +406,24 @@ func (dao *Simple) PutAppExecResult(aer *state.AppExecResult) error {
 
 // -- end notification event.
 
+// -- start state root.
+
+// GetStateRoot returns state digest of the block at the given height.
+func (dao *Simple) GetStateRoot(height uint32) (util.Uint256, error) {
+	b, err := dao.Store.Get(storage.AppendPrefixInt(storage.DataStateRoot, int(height)))
+	if err != nil {
+		return util.Uint256{}, err
+	}
+	return util.Uint256DecodeBytesBE(b)
+}
+
+// PutStateRoot stores state digest of the block at the given height.
+func (dao *Simple) PutStateRoot(height uint32, root util.Uint256) error {
+	return dao.Store.Put(storage.AppendPrefixInt(storage.DataStateRoot, int(height)), root.BytesBE())
+}
+
+// -- end state root.
+
 // -- start storage item.
 
 // GetStorageItem returns StorageItem if it exists in the given store.
@@ -183,6 +183,19 @@ func TestPutGetAppExecResult(t *testing.T) {
 	require.Equal(t, appExecResult, gotAppExecResult)
 }
 
+func TestPutGetStateRoot(t *testing.T) {
+	dao := NewSimple(storage.NewMemoryStore())
+	_, err := dao.GetStateRoot(1)
+	require.Error(t, err)
+	root := random.Uint256()
+	require.NoError(t, dao.PutStateRoot(1, root))
+	got, err := dao.GetStateRoot(1)
+	require.NoError(t, err)
+	require.Equal(t, root, got)
+	_, err = dao.GetStateRoot(2)
+	require.Error(t, err)
+}
+
 func TestPutGetStorageItem(t *testing.T) {
 	dao := NewSimple(storage.NewMemoryStore())
 	hash := random.Uint160()
@@ -35,7 +35,7 @@ var privNetKeys = []string{
 
 // newTestChain should be called before newBlock invocation to properly setup
 // global state.
-func newTestChain(t *testing.T) *Blockchain {
+func newTestChain(t testing.TB) *Blockchain {
 	unitTestNetCfg, err := config.Load("../../config", config.ModeUnitTestNet)
 	require.NoError(t, err)
 	chain, err := NewBlockchain(storage.NewMemoryStore(), unitTestNetCfg.ProtocolConfiguration, zaptest.NewLogger(t))
@@ -112,7 +112,7 @@ func newMinerTX() *transaction.Transaction {
 	}
 }
 
-func getDecodedBlock(t *testing.T, i int) *block.Block {
+func getDecodedBlock(t testing.TB, i int) *block.Block {
 	data, err := getBlockData(i)
 	require.NoError(t, err)
 
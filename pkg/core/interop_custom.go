@@ -0,0 +1,138 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"go.uber.org/zap"
+)
+
+// InteropFunc is a handler of custom interop function. It gets its arguments
+// from v evaluation stack and pushes results there, errors make the VM fault.
+type InteropFunc func(s InteropState, v *vm.VM) error
+
+// InteropState is a restricted read-only view of the chain state available to
+// custom interop functions.
+type InteropState interface {
+	// Trigger returns the trigger of the current execution.
+	Trigger() trigger.Type
+	// Block returns the block being persisted, it's nil for verification
+	// and test invocations.
+	Block() *block.Block
+	// Tx returns the transaction being executed, it's nil when there is
+	// none.
+	Tx() *transaction.Transaction
+	// Height returns the current blockchain height.
+	Height() uint32
+	// GetStorageItem returns the value stored by the contract with the
+	// given script hash under the key, it's nil if there is no such item.
+	// Changes made by previous transactions of the persisted block are
+	// visible.
+	GetStorageItem(h util.Uint160, key []byte) []byte
+	// Log returns the chain logger.
+	Log() *zap.Logger
+}
+
+// interopState implements InteropState for the interop context.
+type interopState struct {
+	ic *interopContext
+}
+
+// Trigger implements InteropState interface.
+func (s interopState) Trigger() trigger.Type {
+	return s.ic.trigger
+}
+
+// Block implements InteropState interface.
+func (s interopState) Block() *block.Block {
+	return s.ic.block
+}
+
+// Tx implements InteropState interface.
+func (s interopState) Tx() *transaction.Transaction {
+	return s.ic.tx
+}
+
+// Height implements InteropState interface.
+func (s interopState) Height() uint32 {
+	return s.ic.bc.BlockHeight()
+}
+
+// GetStorageItem implements InteropState interface.
+func (s interopState) GetStorageItem(h util.Uint160, key []byte) []byte {
+	si := s.ic.dao.GetStorageItem(h, key)
+	if si == nil {
+		return nil
+	}
+	return si.Value
+}
+
+// Log implements InteropState interface.
+func (s interopState) Log() *zap.Logger {
+	return s.ic.log
+}
+
+// RegisterInterop adds custom interop function with the given name available
+// to all contracts executed by the chain. Price is set in the same units
+// standard interop prices are (0.001 GAS) and should be positive. Custom
+// interops change the way transactions are executed, so all nodes of the
+// network should register the same functions before processing any blocks.
+func (bc *Blockchain) RegisterInterop(name string, price int, f InteropFunc) error {
+	if name == "" {
+		return errors.New("empty interop name")
+	}
+	if price <= 0 {
+		return errors.New("interop price should be positive")
+	}
+	if f == nil {
+		return errors.New("nil interop function")
+	}
+	id := vm.InteropNameToID([]byte(name))
+
+	bc.interopsLock.Lock()
+	defer bc.interopsLock.Unlock()
+	for _, slice := range [][]interopedFunction{systemInterops, neoInterops, bc.customInterops} {
+		n := sort.Search(len(slice), func(i int) bool {
+			return slice[i].ID >= id
+		})
+		if n < len(slice) && slice[n].ID == id {
+			return fmt.Errorf("interop %s conflicts with %s", name, slice[n].Name)
+		}
+	}
+	// The slice is shared with interop contexts created before, so it's
+	// copied rather than modified.
+	custom := make([]interopedFunction, len(bc.customInterops), len(bc.customInterops)+1)
+	copy(custom, bc.customInterops)
+	custom = append(custom, interopedFunction{
+		ID:   id,
+		Name: name,
+		Func: func(ic *interopContext, v *vm.VM) error {
+			return f(interopState{ic: ic}, v)
+		},
+		Price: price,
+	})
+	sort.Slice(custom, func(i, j int) bool {
+		return custom[i].ID < custom[j].ID
+	})
+	bc.customInterops = custom
+	return nil
+}
+
+// getCustomInterops returns interop functions registered via RegisterInterop.
+func (bc *Blockchain) getCustomInterops() []interopedFunction {
+	bc.interopsLock.RLock()
+	defer bc.interopsLock.RUnlock()
+	return bc.customInterops
+}
+
+// getCustomInterop returns matching custom interop function for a given id
+// in the current context.
+func (ic *interopContext) getCustomInterop(id uint32) *vm.InteropFuncPrice {
+	return ic.getInteropFromSlice(id, ic.custom)
+}
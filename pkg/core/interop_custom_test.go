@@ -0,0 +1,87 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockchain_RegisterInterop(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	registry := util.Uint160{1, 2, 3}
+	double := func(s InteropState, v *vm.VM) error {
+		n := v.Estack().Pop().BigInt()
+		v.Estack().PushVal(new(big.Int).Mul(n, big.NewInt(2)))
+		return nil
+	}
+	lookup := func(s InteropState, v *vm.VM) error {
+		key := v.Estack().Pop().Bytes()
+		val := s.GetStorageItem(registry, key)
+		if val == nil {
+			return errors.New("not found")
+		}
+		v.Estack().PushVal(val)
+		return nil
+	}
+	require.NoError(t, bc.RegisterInterop("Test.Math.Double", 5, double))
+	require.NoError(t, bc.RegisterInterop("Test.KYC.Lookup", 10, lookup))
+
+	require.Error(t, bc.RegisterInterop("", 1, double))
+	require.Error(t, bc.RegisterInterop("Test.Math.Triple", 0, double))
+	require.Error(t, bc.RegisterInterop("Test.Math.Triple", 1, nil))
+	require.Error(t, bc.RegisterInterop("Test.Math.Double", 1, double))
+	require.Error(t, bc.RegisterInterop("Neo.Runtime.Log", 1, double))
+
+	ic := bc.newInteropContext(trigger.Application, bc.dao, nil, nil)
+	require.NoError(t, ic.dao.PutStorageItem(registry, []byte("alice"), &state.StorageItem{Value: []byte("verified")}))
+
+	t.Run("call", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Int(w.BinWriter, 21)
+		emit.Syscall(w.BinWriter, "Test.Math.Double")
+		emit.Bytes(w.BinWriter, []byte("alice"))
+		emit.Syscall(w.BinWriter, "Test.KYC.Lookup")
+		require.NoError(t, w.Err)
+
+		v := ic.SpawnVM()
+		v.SetPriceGetter(getPrice)
+		v.Load(w.Bytes())
+		require.NoError(t, v.Run())
+		require.Equal(t, 2, v.Estack().Len())
+		require.Equal(t, []byte("verified"), v.Estack().Pop().Bytes())
+		require.Equal(t, int64(42), v.Estack().Pop().BigInt().Int64())
+		require.Equal(t, toFixed8(5+10), v.GasConsumed())
+	})
+	t.Run("error", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Bytes(w.BinWriter, []byte("bob"))
+		emit.Syscall(w.BinWriter, "Test.KYC.Lookup")
+		require.NoError(t, w.Err)
+
+		v := ic.SpawnVM()
+		v.Load(w.Bytes())
+		require.Error(t, v.Run())
+	})
+	t.Run("not registered", func(t *testing.T) {
+		w := io.NewBufBinWriter()
+		emit.Int(w.BinWriter, 21)
+		emit.Syscall(w.BinWriter, "Test.Math.Double")
+		require.NoError(t, w.Err)
+
+		other := newTestChain(t)
+		defer other.Close()
+		v := other.newInteropContext(trigger.Application, other.dao, nil, nil).SpawnVM()
+		v.Load(w.Bytes())
+		require.Error(t, v.Run())
+	})
+}
@@ -445,6 +445,7 @@ func (ic *interopContext) storageFind(v *vm.VM) error {
 		return err
 	}
 	prefix := string(v.Estack().Pop().Bytes())
+	ic.recordStorage(stc.ScriptHash, []byte(prefix), state.StorageFind)
 	siMap, err := ic.dao.GetStorageItems(stc.ScriptHash)
 	if err != nil {
 		return err
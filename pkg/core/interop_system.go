@@ -355,6 +355,9 @@ func (ic *interopContext) runtimeNotify(v *vm.VM) error {
 	}
 	ne := state.NotificationEvent{ScriptHash: getContextScriptHash(v, 0), Item: item}
 	ic.notifications = append(ic.notifications, ne)
+	if ic.effects != nil {
+		ic.effects.Notifications = append(ic.effects.Notifications, ne)
+	}
 	return nil
 }
 
@@ -428,6 +431,7 @@ func (ic *interopContext) storageDelete(v *vm.VM) error {
 	if si != nil && si.IsConst {
 		return errors.New("storage item is constant")
 	}
+	ic.recordStorage(stc.ScriptHash, key, state.StorageDelete)
 	return ic.dao.DeleteStorageItem(stc.ScriptHash, key)
 }
 
@@ -443,6 +447,7 @@ func (ic *interopContext) storageGet(v *vm.VM) error {
 		return err
 	}
 	key := v.Estack().Pop().Bytes()
+	ic.recordStorage(stc.ScriptHash, key, state.StorageRead)
 	si := ic.dao.GetStorageItem(stc.ScriptHash, key)
 	if si != nil && si.Value != nil {
 		v.Estack().PushVal(si.Value)
@@ -511,7 +516,11 @@ func (ic *interopContext) storagePutInternal(v *vm.VM, getFlag bool) error {
 	if getFlag {
 		flag = int(v.Estack().Pop().BigInt().Int64())
 	}
-	return ic.putWithContextAndFlags(stc, key, value, flag == 1)
+	if err := ic.putWithContextAndFlags(stc, key, value, flag == 1); err != nil {
+		return err
+	}
+	ic.recordStorage(stc.ScriptHash, key, state.StorageWrite)
+	return nil
 }
 
 // storagePut puts key-value pair into the storage.
@@ -556,6 +565,9 @@ func (ic *interopContext) contractDestroy(v *vm.VM) error {
 	if err != nil {
 		return err
 	}
+	if ic.programs != nil {
+		ic.programs.Delete(hash)
+	}
 	if cs.HasStorage() {
 		siMap, err := ic.dao.GetStorageItems(hash)
 		if err != nil {
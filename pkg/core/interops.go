@@ -18,6 +18,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
 	"go.uber.org/zap"
 )
 
@@ -29,12 +30,18 @@ type interopContext struct {
 	dao           *dao.Cached
 	notifications []state.NotificationEvent
 	log           *zap.Logger
+	// custom are interop functions registered in the chain.
+	custom []interopedFunction
+	// programs caches pre-decoded contract scripts.
+	programs *vm.ProgramCache
+	// effects collects invocation side effects, nil if they're not needed.
+	effects *state.Effects
 }
 
 func newInteropContext(trigger trigger.Type, bc Blockchainer, d dao.DAO, block *block.Block, tx *transaction.Transaction, log *zap.Logger) *interopContext {
 	dao := dao.NewCached(d)
 	nes := make([]state.NotificationEvent, 0)
-	return &interopContext{bc, trigger, block, tx, dao, nes, log}
+	return &interopContext{bc: bc, trigger: trigger, block: block, tx: tx, dao: dao, notifications: nes, log: log}
 }
 
 // SpawnVM returns a VM with script getter and interop functions set
@@ -47,13 +54,60 @@ func (ic *interopContext) SpawnVM() *vm.VM {
 			return nil, false
 		}
 		hasDynamicInvoke := (cs.Properties & smartcontract.HasDynamicInvoke) != 0
+		if ic.effects != nil {
+			ic.effects.Calls = append(ic.effects.Calls, state.ContractCall{
+				Caller:  getContextScriptHash(vm, 0),
+				Callee:  hash,
+				Dynamic: isDynamicCall(vm.Context()),
+			})
+		}
 		return cs.Script, hasDynamicInvoke
 	})
+	if ic.programs != nil {
+		vm.SetProgramCache(ic.programs)
+	}
 	vm.RegisterInteropGetter(ic.getSystemInterop)
 	vm.RegisterInteropGetter(ic.getNeoInterop)
+	if len(ic.custom) != 0 {
+		vm.RegisterInteropGetter(ic.getCustomInterop)
+	}
 	return vm
 }
 
+// isDynamicCall checks whether the current instruction of the context calls
+// a contract which hash is taken from the stack.
+func isDynamicCall(ctx *vm.Context) bool {
+	ip, op := ctx.CurrInstr()
+	switch op {
+	case opcode.CALLED, opcode.CALLEDT:
+		return true
+	case opcode.APPCALL, opcode.TAILCALL:
+		prog := ctx.Program()
+		if len(prog) < ip+1+util.Uint160Size {
+			return false
+		}
+		for _, b := range prog[ip+1 : ip+1+util.Uint160Size] {
+			if b != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// recordStorage adds storage access to the invocation effects.
+func (ic *interopContext) recordStorage(hash util.Uint160, key []byte, op state.StorageOp) {
+	if ic.effects == nil {
+		return
+	}
+	ic.effects.Storage = append(ic.effects.Storage, state.StorageAccess{
+		ScriptHash: hash,
+		Key:        key,
+		Op:         op,
+	})
+}
+
 // interopedFunction binds function name, id with the function itself and price,
 // it's supposed to be inited once for all interopContexts, so it doesn't use
 // vm.InteropFuncPrice directly.
@@ -6,9 +6,16 @@ import (
 	"testing"
 
 	"github.com/nspcc-dev/neo-go/pkg/core/dao"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
 	"github.com/stretchr/testify/require"
 )
 
@@ -90,3 +97,62 @@ func TestUnexpectedNonInterops(t *testing.T) {
 		}
 	}
 }
+
+func TestGetTestVMEffects(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	w := io.NewBufBinWriter()
+	emit.Bytes(w.BinWriter, []byte("value"))
+	emit.Bytes(w.BinWriter, []byte("key"))
+	emit.Syscall(w.BinWriter, "System.Storage.GetContext")
+	emit.Syscall(w.BinWriter, "System.Storage.Put")
+	emit.Bytes(w.BinWriter, []byte("other"))
+	emit.Syscall(w.BinWriter, "System.Storage.GetContext")
+	emit.Syscall(w.BinWriter, "System.Storage.Get")
+	emit.Syscall(w.BinWriter, "System.Runtime.Notify")
+	emit.Opcode(w.BinWriter, opcode.RET)
+	cs := &state.Contract{Script: w.Bytes(), Properties: smartcontract.HasStorage}
+	require.NoError(t, bc.dao.PutContractState(cs))
+
+	w = io.NewBufBinWriter()
+	emit.AppCall(w.BinWriter, cs.ScriptHash(), false)
+	script := w.Bytes()
+
+	effects := new(state.Effects)
+	v := bc.GetTestVM(nil, effects)
+	v.LoadScript(script)
+	require.NoError(t, v.Run())
+	require.Equal(t, []state.ContractCall{{
+		Caller: hash.Hash160(script),
+		Callee: cs.ScriptHash(),
+	}}, effects.Calls)
+	require.Equal(t, []state.StorageAccess{
+		{ScriptHash: cs.ScriptHash(), Key: []byte("key"), Op: state.StorageWrite},
+		{ScriptHash: cs.ScriptHash(), Key: []byte("other"), Op: state.StorageRead},
+	}, effects.Storage)
+	require.Equal(t, 1, len(effects.Notifications))
+	require.Equal(t, cs.ScriptHash(), effects.Notifications[0].ScriptHash)
+}
+
+func TestIsDynamicCall(t *testing.T) {
+	h := util.Uint160{1, 2, 3}
+	testCases := map[string]struct {
+		script  []byte
+		dynamic bool
+	}{
+		"APPCALL":         {append([]byte{byte(opcode.APPCALL)}, h.BytesBE()...), false},
+		"dynamic APPCALL": {append([]byte{byte(opcode.APPCALL)}, make([]byte, 20)...), true},
+		"TAILCALL":        {append([]byte{byte(opcode.TAILCALL)}, make([]byte, 20)...), true},
+		"CALLE":           {append([]byte{byte(opcode.CALLE), 0, 0}, h.BytesBE()...), false},
+		"CALLED":          {[]byte{byte(opcode.CALLED), 0, 0}, true},
+		"truncated":       {[]byte{byte(opcode.APPCALL), 0}, false},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			v := vm.New()
+			v.LoadScript(tc.script)
+			require.Equal(t, tc.dynamic, isDynamicCall(v.Context()))
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 )
 
@@ -41,6 +42,19 @@ type TxWithFee struct {
 	Fee util.Fixed8
 }
 
+// EncodeBinary implements io.Serializable interface.
+func (t *TxWithFee) EncodeBinary(w *io.BinWriter) {
+	t.Tx.EncodeBinary(w)
+	w.WriteU64LE(uint64(t.Fee))
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (t *TxWithFee) DecodeBinary(r *io.BinReader) {
+	t.Tx = new(transaction.Transaction)
+	t.Tx.DecodeBinary(r)
+	t.Fee = util.Fixed8(r.ReadU64LE())
+}
+
 // Pool stores the unconfirms transactions.
 type Pool struct {
 	lock         sync.RWMutex
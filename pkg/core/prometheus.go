@@ -30,6 +30,30 @@ var (
 			Namespace: "neogo",
 		},
 	)
+	//memPoolRestored prometheus metric.
+	memPoolRestored = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of memory pool transactions restored on startup",
+			Name:      "mempool_restored_tx",
+			Namespace: "neogo",
+		},
+	)
+	//witnessCacheHits prometheus metric.
+	witnessCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of witnesses found in verification cache",
+			Name:      "witness_cache_hits",
+			Namespace: "neogo",
+		},
+	)
+	//witnessCacheMisses prometheus metric.
+	witnessCacheMisses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of witnesses not found in verification cache",
+			Name:      "witness_cache_misses",
+			Namespace: "neogo",
+		},
+	)
 )
 
 func init() {
@@ -37,6 +61,9 @@ func init() {
 		blockHeight,
 		persistedHeight,
 		headerHeight,
+		memPoolRestored,
+		witnessCacheHits,
+		witnessCacheMisses,
 	)
 }
 
@@ -51,3 +78,7 @@ func updateHeaderHeightMetric(hHeight int) {
 func updateBlockHeightMetric(bHeight uint32) {
 	blockHeight.Set(float64(bHeight))
 }
+
+func updateMemPoolRestoredMetric(n int) {
+	memPoolRestored.Set(float64(n))
+}
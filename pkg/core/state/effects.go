@@ -0,0 +1,92 @@
+package state
+
+import (
+	"math/big"
+
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+)
+
+// Effects contains side effects of script invocation: contract calls,
+// storage accesses and notifications. It's only collected for test
+// invocations.
+type Effects struct {
+	Calls         []ContractCall
+	Storage       []StorageAccess
+	Notifications []NotificationEvent
+}
+
+// ContractCall is a call of one contract from another.
+type ContractCall struct {
+	Caller util.Uint160
+	Callee util.Uint160
+	// Dynamic is true for calls with the callee hash taken from the stack.
+	Dynamic bool
+}
+
+// StorageOp is a kind of storage access.
+type StorageOp byte
+
+// Storage access kinds.
+const (
+	StorageRead StorageOp = iota
+	StorageWrite
+	StorageDelete
+	StorageFind
+)
+
+// String implements fmt.Stringer interface.
+func (op StorageOp) String() string {
+	switch op {
+	case StorageRead:
+		return "read"
+	case StorageWrite:
+		return "write"
+	case StorageDelete:
+		return "delete"
+	case StorageFind:
+		return "find"
+	default:
+		return "unknown"
+	}
+}
+
+// StorageAccess is a single access to contract storage, Key is a prefix for
+// StorageFind.
+type StorageAccess struct {
+	ScriptHash util.Uint160
+	Key        []byte
+	Op         StorageOp
+}
+
+// NEP5Transfer parses the notification as NEP5 'transfer' event returning
+// sender, receiver and the amount transferred, ok is false if it's not
+// a transfer event.
+func (ne *NotificationEvent) NEP5Transfer() (from, to []byte, amount *big.Int, ok bool) {
+	arr, ok := ne.Item.Value().([]vm.StackItem)
+	if !ok || len(arr) != 4 {
+		return nil, nil, nil, false
+	}
+	op, ok := arr[0].Value().([]byte)
+	if !ok || string(op) != "transfer" {
+		return nil, nil, nil, false
+	}
+	from, ok = arr[1].Value().([]byte)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	to, ok = arr[2].Value().([]byte)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	amount, ok = arr[3].Value().(*big.Int)
+	if !ok {
+		bs, ok := arr[3].Value().([]byte)
+		if !ok {
+			return nil, nil, nil, false
+		}
+		amount = emit.BytesToInt(bs)
+	}
+	return from, to, amount, true
+}
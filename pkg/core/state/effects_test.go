@@ -0,0 +1,41 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationEventNEP5Transfer(t *testing.T) {
+	newEvent := func(items ...interface{}) *NotificationEvent {
+		arr := make([]vm.StackItem, len(items))
+		for i := range items {
+			switch v := items[i].(type) {
+			case string:
+				arr[i] = vm.NewByteArrayItem([]byte(v))
+			case int64:
+				arr[i] = vm.NewBigIntegerItem(v)
+			}
+		}
+		return &NotificationEvent{Item: vm.NewArrayItem(arr)}
+	}
+
+	from, to, amount, ok := newEvent("transfer", "from", "to", int64(42)).NEP5Transfer()
+	require.True(t, ok)
+	require.Equal(t, []byte("from"), from)
+	require.Equal(t, []byte("to"), to)
+	require.Equal(t, big.NewInt(42), amount)
+
+	_, _, amount, ok = newEvent("transfer", "from", "to", "\x2a").NEP5Transfer()
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(42), amount)
+
+	_, _, _, ok = newEvent("transfer", "from", "to").NEP5Transfer()
+	require.False(t, ok)
+	_, _, _, ok = newEvent("approve", "from", "to", int64(42)).NEP5Transfer()
+	require.False(t, ok)
+	_, _, _, ok = (&NotificationEvent{Item: vm.NewBoolItem(true)}).NEP5Transfer()
+	require.False(t, ok)
+}
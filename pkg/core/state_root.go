@@ -0,0 +1,96 @@
+package core
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/dao"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// stateRootPrefixes are the key prefixes covered by the state digest. Blocks,
+// transactions, notifications, NEP5 tracking data and system keys are not a
+// part of the ledger state and are excluded.
+var stateRootPrefixes = []storage.KeyPrefix{
+	storage.STAccount,
+	storage.STCoin,
+	storage.STSpentCoin,
+	storage.STValidator,
+	storage.STAsset,
+	storage.STContract,
+	storage.STStorage,
+	storage.IXValidatorsCount,
+}
+
+// stateChange is a single change of the state key.
+type stateChange struct {
+	key     []byte
+	value   []byte
+	deleted bool
+}
+
+// isStateKey checks whether the key belongs to the ledger state.
+func isStateKey(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for _, p := range stateRootPrefixes {
+		if key[0] == byte(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateStateRoot returns the state digest for the block that made changes
+// given on top of the state with prev digest. It's a SHA256 of the previous
+// digest followed by SHA256 of all state changes sorted by key, so it covers
+// the whole chain history and once two nodes diverge their digests never
+// match again. Deletions of missing keys are not changes and are ignored.
+func calculateStateRoot(prev util.Uint256, batch *storage.MemBatch) util.Uint256 {
+	changes := make([]stateChange, 0, len(batch.Put)+len(batch.Deleted))
+	for _, kv := range batch.Put {
+		if isStateKey(kv.Key) {
+			changes = append(changes, stateChange{key: kv.Key, value: kv.Value})
+		}
+	}
+	for _, kv := range batch.Deleted {
+		if kv.Exists && isStateKey(kv.Key) {
+			changes = append(changes, stateChange{key: kv.Key, deleted: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return bytes.Compare(changes[i].key, changes[j].key) < 0
+	})
+
+	w := io.NewBufBinWriter()
+	for _, c := range changes {
+		w.WriteVarBytes(c.key)
+		w.WriteBool(c.deleted)
+		if !c.deleted {
+			w.WriteVarBytes(c.value)
+		}
+	}
+	changesHash := hash.Sha256(w.Bytes())
+	return hash.Sha256(append(prev.BytesBE(), changesHash.BytesBE()...))
+}
+
+// storeStateRoot calculates the state digest for the block at the given height
+// from changes accumulated in d and puts it into d. If there is no digest for
+// the previous block (state roots were enabled for an existing database),
+// the chain of digests starts from this block.
+func storeStateRoot(d *dao.Simple, index uint32) error {
+	var prev util.Uint256
+	if index > 0 {
+		var err error
+		prev, err = d.GetStateRoot(index - 1)
+		if err != nil && err != storage.ErrKeyNotFound {
+			return errors.Wrap(err, "failed to get previous state root")
+		}
+	}
+	return d.PutStateRoot(index, calculateStateRoot(prev, d.GetBatch()))
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateStateRoot(t *testing.T) {
+	prev := util.Uint256{1, 2, 3}
+	acc := storage.AppendPrefix(storage.STAccount, []byte{1})
+	item := storage.AppendPrefix(storage.STStorage, []byte{2})
+	batch := &storage.MemBatch{
+		Put: []storage.KeyValue{
+			{Key: acc, Value: []byte{1}},
+			{Key: item, Value: []byte{2}},
+		},
+	}
+	root := calculateStateRoot(prev, batch)
+
+	t.Run("order", func(t *testing.T) {
+		b := &storage.MemBatch{Put: []storage.KeyValue{batch.Put[1], batch.Put[0]}}
+		require.Equal(t, root, calculateStateRoot(prev, b))
+	})
+	t.Run("non-state keys", func(t *testing.T) {
+		b := &storage.MemBatch{
+			Put: append([]storage.KeyValue{
+				{Key: storage.AppendPrefix(storage.DataBlock, []byte{3}), Value: []byte{3}},
+				{Key: storage.AppendPrefix(storage.STNEP5Balances, []byte{4}), Value: []byte{4}},
+			}, batch.Put...),
+			Deleted: []storage.KeyValue{
+				{Key: storage.AppendPrefix(storage.STStorage, []byte{5})},
+			},
+		}
+		require.Equal(t, root, calculateStateRoot(prev, b))
+	})
+	t.Run("changes", func(t *testing.T) {
+		b := &storage.MemBatch{Put: []storage.KeyValue{batch.Put[0], {Key: item, Value: []byte{3}}}}
+		require.NotEqual(t, root, calculateStateRoot(prev, b))
+
+		b = &storage.MemBatch{
+			Put:     batch.Put[:1],
+			Deleted: []storage.KeyValue{{Key: item, Exists: true}},
+		}
+		require.NotEqual(t, root, calculateStateRoot(prev, b))
+		require.NotEqual(t, root, calculateStateRoot(util.Uint256{}, batch))
+	})
+}
+
+func TestGetStateRoot(t *testing.T) {
+	const size = 3
+	bc := newTestChain(t)
+	defer bc.Close()
+	blocks, err := bc.genBlocks(size)
+	require.NoError(t, err)
+
+	other := newTestChain(t)
+	defer other.Close()
+	for _, b := range blocks {
+		require.NoError(t, other.AddBlock(b))
+	}
+
+	seen := make(map[util.Uint256]bool)
+	for i := uint32(0); i <= size; i++ {
+		root, err := bc.GetStateRoot(i)
+		require.NoError(t, err)
+		require.False(t, seen[root])
+		seen[root] = true
+
+		otherRoot, err := other.GetStateRoot(i)
+		require.NoError(t, err)
+		require.Equal(t, root, otherRoot)
+	}
+	_, err = bc.GetStateRoot(size + 1)
+	require.Error(t, err)
+}
+
+func TestGetStateRoot_Disabled(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	bc.config.StateRootEnabled = false
+	_, err := bc.genBlocks(2)
+	require.NoError(t, err)
+	_, err = bc.GetStateRoot(1)
+	require.Error(t, err)
+
+	// Roots start from the first block stored after enabling them.
+	bc.config.StateRootEnabled = true
+	_, err = bc.genBlocks(2)
+	require.NoError(t, err)
+	_, err = bc.GetStateRoot(2)
+	require.Equal(t, storage.ErrKeyNotFound, err)
+	for i := uint32(3); i <= 4; i++ {
+		_, err = bc.GetStateRoot(i)
+		require.NoError(t, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 const (
 	DataBlock         KeyPrefix = 0x01
 	DataTransaction   KeyPrefix = 0x02
+	DataStateRoot     KeyPrefix = 0x03
 	STAccount         KeyPrefix = 0x40
 	STCoin            KeyPrefix = 0x44
 	STSpentCoin       KeyPrefix = 0x45
@@ -23,6 +24,7 @@ const (
 	IXValidatorsCount KeyPrefix = 0x90
 	SYSCurrentBlock   KeyPrefix = 0xc0
 	SYSCurrentHeader  KeyPrefix = 0xc1
+	SYSMemPool        KeyPrefix = 0xc2
 	SYSVersion        KeyPrefix = 0xf0
 )
 
@@ -20,6 +20,7 @@ var (
 		IXValidatorsCount,
 		SYSCurrentBlock,
 		SYSCurrentHeader,
+		SYSMemPool,
 		SYSVersion,
 	}
 
@@ -36,6 +37,7 @@ var (
 		0x90,
 		0xc0,
 		0xc1,
+		0xc2,
 		0xf0,
 	}
 )
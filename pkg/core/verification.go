@@ -0,0 +1,208 @@
+package core
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/pkg/errors"
+)
+
+// witnessCheck is a single witness verification job.
+type witnessCheck struct {
+	// tx is the hash of the transaction being checked, it's zero for block
+	// witnesses which are not cached.
+	tx         util.Uint256
+	hash       util.Uint160
+	witness    *transaction.Witness
+	checked    util.Uint256
+	interopCtx *interopContext
+	// useKeys enables public key cache, it's used for block witnesses.
+	useKeys bool
+}
+
+// standardWitness is a witness of standard signature or multisignature
+// contract that can be checked without the VM.
+type standardWitness struct {
+	pubs [][]byte
+	sigs [][]byte
+}
+
+// verifyWitnesses verifies given witnesses and returns the index of the first
+// failed check along with the error. Witnesses of standard contracts don't
+// depend on the chain state, so they're checked concurrently without the VM,
+// all others are run in the VM sequentially. Standard transaction witnesses
+// are looked up in the witness cache first, successful checks are added there
+// if cache is set, otherwise cache entries are dropped after use.
+func (bc *Blockchain) verifyWitnesses(checks []witnessCheck, cache bool) (int, error) {
+	var (
+		errs      = make([]error, len(checks))
+		std       = make([]*standardWitness, len(checks))
+		cacheKeys = make([]witnessKey, len(checks))
+		// fallback marks standard witnesses which still need the VM.
+		fallback = make([]bool, len(checks))
+		vmChecks []int
+		jobs     = make(chan int, len(checks))
+		wg       sync.WaitGroup
+	)
+	for i := range checks {
+		w, ok := parseStandardWitness(checks[i].hash, checks[i].witness)
+		if !ok {
+			vmChecks = append(vmChecks, i)
+			continue
+		}
+		if !checks[i].tx.Equals(util.Uint256{}) {
+			cacheKeys[i] = newWitnessKey(checks[i].tx, checks[i].witness)
+			if bc.witnessCache.Has(cacheKeys[i], !cache) {
+				continue
+			}
+		}
+		std[i] = w
+		jobs <- i
+	}
+	close(jobs)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	wg.Add(workers)
+	for k := 0; k < workers; k++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ok, err := bc.verifyStandardWitness(&checks[i], std[i])
+				fallback[i] = !ok
+				errs[i] = err
+			}
+		}()
+	}
+	for _, i := range vmChecks {
+		errs[i] = bc.verifyCheck(&checks[i])
+	}
+	wg.Wait()
+
+	for i := range checks {
+		if fallback[i] {
+			errs[i] = bc.verifyCheck(&checks[i])
+		}
+		if errs[i] != nil {
+			return i, errs[i]
+		}
+		if cache && std[i] != nil && !checks[i].tx.Equals(util.Uint256{}) {
+			bc.witnessCache.Add(cacheKeys[i])
+		}
+	}
+	return 0, nil
+}
+
+// verifyCheck runs witness check in the VM.
+func (bc *Blockchain) verifyCheck(c *witnessCheck) error {
+	return bc.verifyHashAgainstScript(c.hash, c.witness, c.checked, c.interopCtx, c.useKeys)
+}
+
+// parseStandardWitness returns signatures and public keys of the witness if
+// it uses standard signature or multisignature contract and its invocation
+// script only pushes signatures.
+func parseStandardWitness(hash util.Uint160, witness *transaction.Witness) (*standardWitness, bool) {
+	script := witness.VerificationScript
+	if len(script) == 0 {
+		return nil, false
+	}
+	var (
+		w     = new(standardWitness)
+		nsigs int
+	)
+	if vm.IsSignatureContract(script) {
+		w.pubs = [][]byte{script[1:34]}
+		nsigs = 1
+	} else if pubs, ok := vm.ParseMultiSigContract(script); ok {
+		w.pubs = pubs
+		nsigs, _ = vm.GetMultiSigThreshold(script)
+	} else {
+		return nil, false
+	}
+	if witness.ScriptHash() != hash {
+		return nil, false
+	}
+
+	inv := witness.InvocationScript
+	ctx := vm.NewContext(inv)
+	for ctx.NextIP() < len(inv) {
+		op, param, err := ctx.Next()
+		if err != nil || op != opcode.PUSHBYTES64 {
+			return nil, false
+		}
+		w.sigs = append(w.sigs, param)
+	}
+	if len(w.sigs) < nsigs {
+		return nil, false
+	}
+	// Only the topmost signatures are used by the contract.
+	w.sigs = w.sigs[len(w.sigs)-nsigs:]
+	return w, true
+}
+
+// verifyStandardWitness checks signatures of the standard witness the same way
+// CHECKSIG and CHECKMULTISIG do. It returns false if the witness can't be
+// checked this way and should be run in the VM.
+func (bc *Blockchain) verifyStandardWitness(c *witnessCheck, w *standardWitness) (bool, error) {
+	pubs, ok := bc.decodePublicKeys(c.hash, w.pubs, c.useKeys)
+	if !ok {
+		return false, nil
+	}
+	h := c.checked.BytesBE()
+	var i int
+	for j := 0; i < len(w.sigs) && len(pubs)-j >= len(w.sigs)-i; j++ {
+		if pubs[j].Verify(w.sigs[i], h) {
+			i++
+		}
+	}
+	if i != len(w.sigs) {
+		return true, errors.New("signature check failed")
+	}
+	return true, nil
+}
+
+// decodePublicKeys decodes given public keys using key cache if useKeys is
+// set. It returns false if any of the keys is invalid.
+func (bc *Blockchain) decodePublicKeys(hash util.Uint160, raw [][]byte, useKeys bool) ([]*keys.PublicKey, bool) {
+	var cache map[string]*keys.PublicKey
+	if useKeys {
+		bc.keyCacheLock.RLock()
+		cache = bc.keyCache[hash]
+		bc.keyCacheLock.RUnlock()
+	}
+	var (
+		pubs  = make([]*keys.PublicKey, len(raw))
+		fresh = cache == nil
+	)
+	if fresh {
+		cache = make(map[string]*keys.PublicKey, len(raw))
+	}
+	for i := range raw {
+		if pubs[i] = cache[string(raw[i])]; pubs[i] != nil {
+			continue
+		}
+		pubs[i] = new(keys.PublicKey)
+		if err := pubs[i].DecodeBytes(raw[i]); err != nil {
+			return nil, false
+		}
+		// Cached maps are shared with the VM, so only new ones are filled.
+		if fresh {
+			cache[string(raw[i])] = pubs[i]
+		}
+	}
+	if useKeys && fresh {
+		bc.keyCacheLock.Lock()
+		if bc.keyCache[hash] == nil {
+			bc.keyCache[hash] = cache
+		}
+		bc.keyCacheLock.Unlock()
+	}
+	return pubs, true
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyWitnesses(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	data := []byte("data")
+	checked := hash.Sha256(data)
+	privs := make([]*keys.PrivateKey, 3)
+	pubs := make(keys.PublicKeys, 3)
+	for i := range privs {
+		var err error
+		privs[i], err = keys.NewPrivateKey()
+		require.NoError(t, err)
+		pubs[i] = privs[i].PublicKey()
+	}
+	multi, err := smartcontract.CreateMultiSigRedeemScript(2, pubs)
+	require.NoError(t, err)
+	// Keys are sorted in the script, so are signatures.
+	order, ok := vm.ParseMultiSigContract(multi)
+	require.True(t, ok)
+	sigs := make([][]byte, len(order))
+	for i := range order {
+		for _, p := range privs {
+			if string(p.PublicKey().Bytes()) == string(order[i]) {
+				sigs[i] = getInvocationScript(data, p)
+			}
+		}
+	}
+	single := privs[0].PublicKey().GetVerificationScript()
+	concat := func(bs ...[]byte) []byte {
+		var res []byte
+		for _, b := range bs {
+			res = append(res, b...)
+		}
+		return res
+	}
+
+	testCases := []struct {
+		name         string
+		verification []byte
+		invocation   []byte
+		standard     bool
+		ok           bool
+	}{
+		{"signature", single, getInvocationScript(data, privs[0]), true, true},
+		{"bad signature", single, getInvocationScript([]byte("other"), privs[0]), true, false},
+		{"wrong key", single, getInvocationScript(data, privs[1]), true, false},
+		{"extra signature", single, concat(sigs[2], getInvocationScript(data, privs[0])), true, true},
+		{"multisig", multi, concat(sigs[0], sigs[2]), true, true},
+		{"multisig, all keys", multi, concat(sigs[0], sigs[1], sigs[2]), true, true},
+		{"multisig, wrong order", multi, concat(sigs[2], sigs[0]), true, false},
+		{"multisig, duplicate", multi, concat(sigs[1], sigs[1]), true, false},
+		{"multisig, not enough", multi, sigs[0], false, false},
+		{"not a signature", single, []byte{byte(opcode.PUSH1)}, false, false},
+		{"non-standard", []byte{byte(opcode.PUSH1)}, nil, false, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &transaction.Witness{InvocationScript: tc.invocation, VerificationScript: tc.verification}
+			c := witnessCheck{
+				hash:       w.ScriptHash(),
+				witness:    w,
+				checked:    checked,
+				interopCtx: bc.newInteropContext(trigger.Verification, bc.dao, nil, nil),
+			}
+			_, ok := parseStandardWitness(c.hash, w)
+			require.Equal(t, tc.standard, ok)
+
+			_, err := bc.verifyWitnesses([]witnessCheck{c}, false)
+			require.Equal(t, tc.ok, err == nil)
+			// Fast path must agree with the VM.
+			require.Equal(t, tc.ok, bc.verifyCheck(&c) == nil)
+		})
+	}
+
+	t.Run("first failed", func(t *testing.T) {
+		good := &transaction.Witness{InvocationScript: getInvocationScript(data, privs[0]), VerificationScript: single}
+		bad := &transaction.Witness{InvocationScript: []byte{byte(opcode.PUSH0)}, VerificationScript: []byte{byte(opcode.NOP)}}
+		ic := bc.newInteropContext(trigger.Verification, bc.dao, nil, nil)
+		checks := []witnessCheck{
+			{hash: good.ScriptHash(), witness: good, checked: checked, interopCtx: ic},
+			{hash: bad.ScriptHash(), witness: bad, checked: checked, interopCtx: ic},
+			{hash: good.ScriptHash(), witness: good, checked: checked, interopCtx: ic},
+		}
+		i, err := bc.verifyWitnesses(checks, false)
+		require.Error(t, err)
+		require.Equal(t, 1, i)
+	})
+}
+
+func benchmarkChecks(b *testing.B, bc *Blockchain) []witnessCheck {
+	var checks []witnessCheck
+	for i := 1; i <= 2; i++ {
+		blk := getDecodedBlock(b, i)
+		for j := 0; j < 50; j++ {
+			checks = append(checks, witnessCheck{
+				hash:       blk.Script.ScriptHash(),
+				witness:    &blk.Script,
+				checked:    blk.VerificationHash(),
+				interopCtx: bc.newInteropContext(trigger.Verification, bc.dao, nil, nil),
+				useKeys:    true,
+			})
+		}
+	}
+	return checks
+}
+
+func BenchmarkVerifyWitnesses(b *testing.B) {
+	bc := newTestChain(b)
+	defer bc.Close()
+	checks := benchmarkChecks(b, bc)
+
+	b.Run("vm", func(b *testing.B) {
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			for i := range checks {
+				if err := bc.verifyCheck(&checks[i]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			if _, err := bc.verifyWitnesses(checks, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// witnessKey identifies successful witness verification.
+type witnessKey struct {
+	tx      util.Uint256
+	witness util.Uint256
+}
+
+// witnessCache is a bounded LRU cache of successful witness verification
+// results. Only witnesses of standard contracts are stored there as their
+// verification doesn't depend on the chain state, so entries never become
+// invalid and are only dropped when the transaction gets into a block or when
+// the cache is full.
+type witnessCache struct {
+	lock   sync.Mutex
+	maxCap int
+	elems  map[witnessKey]*list.Element
+	queue  *list.List
+}
+
+func newWitnessCache(capacity int) *witnessCache {
+	return &witnessCache{
+		maxCap: capacity,
+		elems:  make(map[witnessKey]*list.Element),
+		queue:  list.New(),
+	}
+}
+
+// newWitnessKey returns cache key for the witness of transaction tx.
+func newWitnessKey(tx util.Uint256, w *transaction.Witness) witnessKey {
+	buf := io.NewBufBinWriter()
+	w.EncodeBinary(buf.BinWriter)
+	return witnessKey{tx: tx, witness: hash.Sha256(buf.Bytes())}
+}
+
+// Add marks the witness as successfully verified.
+func (c *witnessCache) Add(k witnessKey) {
+	if c.maxCap <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.elems[k]; ok {
+		c.queue.MoveToFront(e)
+		return
+	}
+	if c.queue.Len() >= c.maxCap {
+		last := c.queue.Back()
+		c.queue.Remove(last)
+		delete(c.elems, last.Value.(witnessKey))
+	}
+	c.elems[k] = c.queue.PushFront(k)
+}
+
+// Has checks whether the witness was successfully verified, remove flag
+// drops the entry from the cache. It updates hit/miss metrics.
+func (c *witnessCache) Has(k witnessKey, remove bool) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, ok := c.elems[k]
+	if !ok {
+		witnessCacheMisses.Inc()
+		return false
+	}
+	witnessCacheHits.Inc()
+	if remove {
+		c.queue.Remove(e)
+		delete(c.elems, k)
+	} else {
+		c.queue.MoveToFront(e)
+	}
+	return true
+}
+
+// Len returns the number of cached entries.
+func (c *witnessCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.queue.Len()
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWitnessCache(t *testing.T) {
+	c := newWitnessCache(2)
+	w := &transaction.Witness{InvocationScript: []byte{1}, VerificationScript: []byte{2}}
+	k1 := newWitnessKey(util.Uint256{1}, w)
+	k2 := newWitnessKey(util.Uint256{2}, w)
+	k3 := newWitnessKey(util.Uint256{3}, w)
+	require.NotEqual(t, k1, k2)
+
+	c.Add(k1)
+	c.Add(k2)
+	require.True(t, c.Has(k1, false))
+	// k2 is the least recently used one now.
+	c.Add(k3)
+	require.Equal(t, 2, c.Len())
+	require.False(t, c.Has(k2, false))
+	require.True(t, c.Has(k3, false))
+
+	require.True(t, c.Has(k1, true))
+	require.False(t, c.Has(k1, false))
+	require.Equal(t, 1, c.Len())
+
+	t.Run("different witness", func(t *testing.T) {
+		other := &transaction.Witness{InvocationScript: []byte{1, 2}, VerificationScript: nil}
+		require.NotEqual(t, k1, newWitnessKey(util.Uint256{1}, other))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		c := newWitnessCache(0)
+		c.Add(k1)
+		require.False(t, c.Has(k1, false))
+	})
+}
+
+func TestVerifyWitnessesCached(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	data := []byte("data")
+	newCheck := func(w *transaction.Witness) witnessCheck {
+		return witnessCheck{
+			tx:         hash.DoubleSha256(data),
+			hash:       w.ScriptHash(),
+			witness:    w,
+			checked:    hash.Sha256(data),
+			interopCtx: bc.newInteropContext(trigger.Verification, bc.dao, nil, nil),
+		}
+	}
+	good := &transaction.Witness{
+		InvocationScript:   getInvocationScript(data, priv),
+		VerificationScript: priv.PublicKey().GetVerificationScript(),
+	}
+	nonStandard := &transaction.Witness{
+		InvocationScript:   []byte{byte(opcode.PUSH1)},
+		VerificationScript: []byte{byte(opcode.NOP)},
+	}
+	checks := []witnessCheck{newCheck(good), newCheck(nonStandard)}
+
+	// Block verification doesn't populate the cache.
+	_, err = bc.verifyWitnesses(checks, false)
+	require.NoError(t, err)
+	require.Equal(t, 0, bc.witnessCache.Len())
+
+	// State-dependent results are never cached.
+	_, err = bc.verifyWitnesses(checks, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, bc.witnessCache.Len())
+	require.True(t, bc.witnessCache.Has(newWitnessKey(checks[0].tx, good), false))
+
+	// Cache entry is used instead of checking the signature and dropped.
+	cached := newCheck(good)
+	cached.checked = hash.Sha256([]byte("other"))
+	_, err = bc.verifyWitnesses([]witnessCheck{cached}, false)
+	require.NoError(t, err)
+	require.Equal(t, 0, bc.witnessCache.Len())
+	_, err = bc.verifyWitnesses([]witnessCheck{cached}, false)
+	require.Error(t, err)
+
+	// Failed checks are not cached.
+	_, err = bc.verifyWitnesses([]witnessCheck{cached}, true)
+	require.Error(t, err)
+	require.Equal(t, 0, bc.witnessCache.Len())
+}
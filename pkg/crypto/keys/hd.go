@@ -0,0 +1,161 @@
+package keys
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// HardenedKeyStart is the index of the first hardened child key.
+const HardenedKeyStart uint32 = 0x80000000
+
+// hdSeedKey is an HMAC key used to derive master key from seed for
+// secp256r1 curve as specified in SLIP-0010.
+var hdSeedKey = []byte("Nist256p1 seed")
+
+// ExtendedKey is a private key of hierarchical deterministic key tree
+// (BIP-0032) along with its chain code. Keys are derived according to
+// SLIP-0010 for secp256r1 curve.
+type ExtendedKey struct {
+	PrivateKey *PrivateKey
+	ChainCode  []byte
+}
+
+// NewMasterKey creates root extended key from the given seed.
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length: %d", len(seed))
+	}
+	n := elliptic.P256().Params().N
+	data := seed
+	for {
+		mac := hmac.New(sha512.New, hdSeedKey)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		k := new(big.Int).SetBytes(sum[:32])
+		if k.Sign() != 0 && k.Cmp(n) < 0 {
+			return newExtendedKey(k, sum[32:])
+		}
+		data = sum
+	}
+}
+
+// NewExtendedKey creates extended key from private key and chain code.
+func NewExtendedKey(priv *PrivateKey, chainCode []byte) (*ExtendedKey, error) {
+	if len(chainCode) != 32 {
+		return nil, fmt.Errorf("invalid chain code length: %d", len(chainCode))
+	}
+	return &ExtendedKey{
+		PrivateKey: priv,
+		ChainCode:  chainCode,
+	}, nil
+}
+
+func newExtendedKey(k *big.Int, chainCode []byte) (*ExtendedKey, error) {
+	b := k.Bytes()
+	buf := make([]byte, 32)
+	copy(buf[32-len(b):], b)
+	priv, err := NewPrivateKeyFromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	return NewExtendedKey(priv, chainCode)
+}
+
+// Child derives child key with the given index, indexes starting from
+// HardenedKeyStart produce hardened keys.
+func (k *ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
+	var (
+		n    = elliptic.P256().Params().N
+		kpar = new(big.Int).SetBytes(k.PrivateKey.Bytes())
+		data []byte
+	)
+	if i >= HardenedKeyStart {
+		data = append([]byte{0}, k.PrivateKey.Bytes()...)
+	} else {
+		data = k.PrivateKey.PublicKey().Bytes()
+	}
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], i)
+
+	for {
+		mac := hmac.New(sha512.New, k.ChainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		il := new(big.Int).SetBytes(sum[:32])
+		if il.Cmp(n) < 0 {
+			ki := il.Add(il, kpar)
+			ki.Mod(ki, n)
+			if ki.Sign() != 0 {
+				return newExtendedKey(ki, sum[32:])
+			}
+		}
+		data = append([]byte{1}, sum[32:]...)
+		data = append(data, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(data[len(data)-4:], i)
+	}
+}
+
+// Derive derives key using the given path relative to k.
+func (k *ExtendedKey) Derive(path []uint32) (*ExtendedKey, error) {
+	var err error
+
+	key := k
+	for _, i := range path {
+		if key, err = key.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// ParseDerivationPath parses derivation path in the "m/44'/888'/0'/0/0"
+// form, both ' and h can be used to mark hardened indexes.
+func ParseDerivationPath(s string) ([]uint32, error) {
+	parts := strings.Split(s, "/")
+	if parts[0] != "m" {
+		return nil, errors.New("derivation path must start with m")
+	}
+	path := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		var hardened bool
+		if strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h") {
+			hardened = true
+			p = p[:len(p)-1]
+		}
+		i, err := strconv.ParseUint(p, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path element %q", p)
+		}
+		if hardened {
+			i += uint64(HardenedKeyStart)
+		}
+		path = append(path, uint32(i))
+	}
+	return path, nil
+}
+
+// FormatDerivationPath returns string representation of derivation path.
+func FormatDerivationPath(path []uint32) string {
+	var b strings.Builder
+
+	b.WriteString("m")
+	for _, i := range path {
+		b.WriteByte('/')
+		if i >= HardenedKeyStart {
+			b.WriteString(strconv.FormatUint(uint64(i-HardenedKeyStart), 10))
+			b.WriteByte('\'')
+		} else {
+			b.WriteString(strconv.FormatUint(uint64(i), 10))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package keys
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test vector 1 for nist256p1 from SLIP-0010.
+func TestExtendedKey(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	testCases := []struct {
+		path      string
+		chainCode string
+		key       string
+	}{
+		{
+			path:      "m",
+			chainCode: "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea",
+			key:       "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2",
+		},
+		{
+			path:      "m/0'",
+			chainCode: "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11",
+			key:       "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c",
+		},
+		{
+			path:      "m/0'/1",
+			chainCode: "4187afff1aafa8445010097fb99d23aee9f599450c7bd140b6826ac22ba21d0c",
+			key:       "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129",
+		},
+	}
+
+	master, err := NewMasterKey(seed)
+	require.NoError(t, err)
+	for _, tc := range testCases {
+		path, err := ParseDerivationPath(tc.path)
+		require.NoError(t, err)
+		require.Equal(t, tc.path, FormatDerivationPath(path))
+
+		k, err := master.Derive(path)
+		require.NoError(t, err)
+		require.Equal(t, tc.chainCode, hex.EncodeToString(k.ChainCode), tc.path)
+		require.Equal(t, tc.key, hex.EncodeToString(k.PrivateKey.Bytes()), tc.path)
+	}
+
+	_, err = NewMasterKey(seed[:8])
+	require.Error(t, err)
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	path, err := ParseDerivationPath("m/44h/888'/0'/0/1")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{HardenedKeyStart + 44, HardenedKeyStart + 888, HardenedKeyStart, 0, 1}, path)
+
+	for _, s := range []string{"", "44'/0", "m/x", "m/-1", "m/2147483648", "m//0"} {
+		_, err := ParseDerivationPath(s)
+		require.Error(t, err, s)
+	}
+}
@@ -44,6 +44,21 @@ func TestNEP2Decrypt(t *testing.T) {
 	}
 }
 
+func TestNEP2EncryptWithParams(t *testing.T) {
+	privKey, err := NewPrivateKey()
+	assert.Nil(t, err)
+
+	params := ScryptParams{N: 2, R: 1, P: 1}
+	encryptedWif, err := NEP2EncryptWithParams(privKey, "pass", params)
+	assert.Nil(t, err)
+
+	_, err = NEP2Decrypt(encryptedWif, "pass")
+	assert.Error(t, err)
+	decrypted, err := NEP2DecryptWithParams(encryptedWif, "pass", params)
+	assert.Nil(t, err)
+	assert.Equal(t, privKey.Bytes(), decrypted.Bytes())
+}
+
 func TestNEP2DecryptErrors(t *testing.T) {
 	p := "qwerty"
 
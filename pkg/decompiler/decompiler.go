@@ -0,0 +1,375 @@
+/*
+Package decompiler converts NeoVM bytecode into readable pseudo-Go code.
+
+Program is split into functions starting at the entry point and at every
+CALL target, each function is split into basic blocks and evaluation stack
+is tracked symbolically to recover expressions. Local variables are
+recovered from the array kept on the alt stack the way pkg/compiler does it,
+if/else statements and loops are recovered from the jumps layout, everything
+else is expressed with labels and gotos. Syscalls are shown with names of
+their pkg/interop counterparts.
+
+The result is meant to be read by humans, it's not guaranteed to compile.
+*/
+package decompiler
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// maxRounds is the maximum number of analysis rounds made to settle
+// function signatures.
+const maxRounds = 4
+
+// instruction is a decoded program instruction.
+type instruction struct {
+	offset int
+	op     opcode.Opcode
+	param  []byte
+	// invalid is set for unknown opcodes and truncated instructions.
+	invalid bool
+}
+
+// program is a program being decompiled.
+type program struct {
+	instrs []instruction
+	// index maps instruction offsets to their indexes in instrs.
+	index map[int]int
+	funcs []*function
+	// byOffset maps function start offsets to functions.
+	byOffset map[int]*function
+}
+
+// Decompile writes pseudo-Go representation of prog to w.
+func Decompile(w io.Writer, prog []byte) error {
+	p := newProgram(prog)
+	p.findFunctions()
+	for i := 0; i < maxRounds; i++ {
+		changed := false
+		for _, f := range p.funcs {
+			params, results := f.params, f.results
+			f.analyze()
+			if f.params != params || f.results != results {
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, f := range p.funcs {
+		if i != 0 {
+			fmt.Fprintln(bw)
+		}
+		f.print(bw)
+	}
+	return bw.Flush()
+}
+
+// newProgram decodes prog. The instruction after the last one is a virtual
+// RET, as that's what the VM does when the program ends.
+func newProgram(prog []byte) *program {
+	p := &program{
+		index:    make(map[int]int),
+		byOffset: make(map[int]*function),
+	}
+	ctx := vm.NewContext(prog)
+	for ctx.NextIP() < len(prog) {
+		start := ctx.NextIP()
+		op, param, err := ctx.Next()
+		invalid := err != nil || strings.HasPrefix(op.String(), "Opcode(")
+		p.index[start] = len(p.instrs)
+		p.instrs = append(p.instrs, instruction{offset: start, op: op, param: param, invalid: invalid})
+		if err != nil {
+			break
+		}
+	}
+	if _, ok := p.index[len(prog)]; !ok {
+		p.index[len(prog)] = len(p.instrs)
+		p.instrs = append(p.instrs, instruction{offset: len(prog), op: opcode.RET})
+	}
+	return p
+}
+
+// target returns the offset the instruction jumps to or calls.
+func (in *instruction) target() (int, bool) {
+	switch in.op {
+	case opcode.JMP, opcode.JMPIF, opcode.JMPIFNOT, opcode.CALL, opcode.CALLI:
+		if in.invalid {
+			return 0, false
+		}
+		off := in.offset + int(int16(binary.LittleEndian.Uint16(in.param[len(in.param)-2:])))
+		if in.op == opcode.CALLI {
+			off += 2
+		}
+		return off, true
+	}
+	return 0, false
+}
+
+// isJump returns true for instructions transferring control within the
+// function.
+func (in *instruction) isJump() bool {
+	return in.op == opcode.JMP || in.op == opcode.JMPIF || in.op == opcode.JMPIFNOT
+}
+
+// isTerminal returns true for instructions after which the execution of the
+// function doesn't continue with the next instruction.
+func (in *instruction) isTerminal() bool {
+	if in.invalid {
+		return true
+	}
+	switch in.op {
+	case opcode.JMP, opcode.RET, opcode.THROW, opcode.TAILCALL, opcode.CALLET, opcode.CALLEDT:
+		return true
+	}
+	return false
+}
+
+// findFunctions splits the program into functions.
+func (p *program) findFunctions() {
+	queue := []int{0}
+	for len(queue) != 0 {
+		start := queue[0]
+		queue = queue[1:]
+		if _, ok := p.byOffset[start]; ok {
+			continue
+		}
+		f := newFunction(p, start)
+		p.funcs = append(p.funcs, f)
+		p.byOffset[start] = f
+		for _, i := range f.members {
+			in := &p.instrs[i]
+			if in.op != opcode.CALL && in.op != opcode.CALLI {
+				continue
+			}
+			if t, ok := in.target(); ok {
+				if _, ok := p.index[t]; ok {
+					queue = append(queue, t)
+				}
+			}
+		}
+	}
+}
+
+// successors returns indexes of instructions which can be executed after the
+// i-th one within the same function.
+func (p *program) successors(i int) []int {
+	in := &p.instrs[i]
+	var res []int
+	if in.isJump() {
+		if t, ok := in.target(); ok {
+			if j, ok := p.index[t]; ok {
+				res = append(res, j)
+			}
+		}
+	}
+	if !in.isTerminal() && i+1 < len(p.instrs) {
+		res = append(res, i+1)
+	}
+	return res
+}
+
+// block is a basic block of a function.
+type block struct {
+	// first and last are indexes of the first and the last instructions.
+	first, last int
+	// succs are indexes of successor blocks.
+	succs []int
+
+	stmts []string
+	term  terminator
+}
+
+type termKind int
+
+const (
+	// termNone is used for blocks falling through to the next one.
+	termNone termKind = iota
+	termJump
+	termCond
+	// termStmt is used for blocks ending with a statement (return or
+	// panic) after which the execution doesn't continue.
+	termStmt
+)
+
+// terminator describes how the block ends.
+type terminator struct {
+	kind termKind
+	// target is an index of the block jumped to, it's -1 for invalid jumps.
+	target int
+	// cond is the condition of the jump.
+	cond expr
+	// stmt is the final statement of termStmt blocks.
+	stmt string
+}
+
+// offset returns the offset of the block.
+func (f *function) offset(b int) int {
+	return f.p.instrs[f.blocks[b].first].offset
+}
+
+// function is a function recovered from the program.
+type function struct {
+	p     *program
+	start int
+	name  string
+	// members are indexes of function instructions in ascending order.
+	members []int
+	blocks  []*block
+	entry   int
+
+	params  int
+	results int
+	locals  map[int64]string
+	vars    []string
+	temps   int
+	labels  map[int]bool
+}
+
+func newFunction(p *program, start int) *function {
+	f := &function{
+		p:     p,
+		start: start,
+		name:  fmt.Sprintf("fn%d", start),
+	}
+	if start == 0 {
+		f.name = "Main"
+	}
+
+	first := p.index[start]
+	seen := map[int]bool{first: true}
+	queue := []int{first}
+	for len(queue) != 0 {
+		i := queue[0]
+		queue = queue[1:]
+		f.members = append(f.members, i)
+		for _, j := range p.successors(i) {
+			if !seen[j] {
+				seen[j] = true
+				queue = append(queue, j)
+			}
+		}
+	}
+	sort.Ints(f.members)
+
+	leaders := map[int]bool{first: true}
+	for _, i := range f.members {
+		in := &p.instrs[i]
+		if in.isJump() {
+			if t, ok := in.target(); ok {
+				if j, ok := p.index[t]; ok {
+					leaders[j] = true
+				}
+			}
+		}
+		if in.isJump() || in.isTerminal() {
+			leaders[i+1] = true
+		}
+	}
+	blockOf := make(map[int]int)
+	for k, i := range f.members {
+		if k == 0 || leaders[i] || f.members[k-1] != i-1 {
+			f.blocks = append(f.blocks, &block{first: i})
+		}
+		b := f.blocks[len(f.blocks)-1]
+		b.last = i
+		blockOf[i] = len(f.blocks) - 1
+	}
+	f.entry = blockOf[first]
+	for _, b := range f.blocks {
+		for _, j := range p.successors(b.last) {
+			b.succs = append(b.succs, blockOf[j])
+		}
+		in := &p.instrs[b.last]
+		b.term.target = -1
+		if t, ok := in.target(); ok && in.isJump() {
+			if j, ok := p.index[t]; ok {
+				b.term.target = blockOf[j]
+			}
+		}
+	}
+	return f
+}
+
+// analyze recovers statements of all function blocks.
+func (f *function) analyze() {
+	f.params = 0
+	f.results = 0
+	f.locals = make(map[int64]string)
+	f.vars = nil
+	f.temps = 0
+
+	entries := make([]*state, len(f.blocks))
+	entries[f.entry] = &state{}
+	queue := []int{f.entry}
+	for len(queue) != 0 {
+		b := queue[0]
+		queue = queue[1:]
+		e := newExec(f, entries[b], b == f.entry)
+		e.run(f.blocks[b])
+		for _, s := range f.blocks[b].succs {
+			if entries[s] == nil {
+				entries[s] = e.exitState()
+				queue = append(queue, s)
+			} else if len(entries[s].stack) != len(e.stack) {
+				f.blocks[b].stmts = append(f.blocks[b].stmts,
+					fmt.Sprintf("// stack depth mismatch at %d: %d vs %d", f.offset(s), len(e.stack), len(entries[s].stack)))
+			}
+		}
+	}
+}
+
+// newArg returns the next function parameter.
+func (f *function) newArg() expr {
+	name := fmt.Sprintf("arg%d", f.params)
+	f.params++
+	return ident{name: name}
+}
+
+// newTemp returns a new temporary variable.
+func (f *function) newTemp() ident {
+	name := fmt.Sprintf("t%d", f.temps)
+	f.temps++
+	f.declare(name)
+	return ident{name: name}
+}
+
+// local returns a variable stored in the i-th slot of locals array.
+func (f *function) local(i int64) ident {
+	name, ok := f.locals[i]
+	if !ok {
+		name = fmt.Sprintf("v%d", i)
+		f.locals[i] = name
+		f.declare(name)
+	}
+	return ident{name: name}
+}
+
+// declare adds variable declaration to the function.
+func (f *function) declare(name string) {
+	for _, v := range f.vars {
+		if v == name {
+			return
+		}
+	}
+	f.vars = append(f.vars, name)
+}
+
+// signature returns function parameters and results counts.
+func (p *program) signature(offset int) (string, int, int) {
+	if f, ok := p.byOffset[offset]; ok {
+		return f.name, f.params, f.results
+	}
+	return fmt.Sprintf("fn%d", offset), 0, 0
+}
@@ -0,0 +1,71 @@
+package decompiler
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/vm/asm"
+	"github.com/stretchr/testify/require"
+)
+
+func decompile(t *testing.T, prog []byte) string {
+	buf := new(bytes.Buffer)
+	require.NoError(t, Decompile(buf, prog))
+	return buf.String()
+}
+
+func TestDecompileAssembled(t *testing.T) {
+	src := `PUSH5
+	CALL fn
+	RET
+	fn:
+	PUSH1
+	ADD
+	RET`
+	prog, err := asm.Assemble(strings.NewReader(src))
+	require.NoError(t, err)
+
+	out := decompile(t, prog)
+	require.Contains(t, out, "// Main is at offset 0.\nfunc Main() interface{} {")
+	require.Contains(t, out, "// fn5 is at offset 5.\nfunc fn5(arg0 interface{}) interface{} {")
+	require.Contains(t, out, "fn5(5)")
+	require.Contains(t, out, "return arg0 + 1")
+}
+
+func TestDecompileContract(t *testing.T) {
+	src := `package foo
+	import "github.com/nspcc-dev/neo-go/pkg/interop/storage"
+	func Main(op string, args []interface{}) int {
+		ctx := storage.GetContext()
+		sum := 0
+		for i := 0; i < len(args); i++ {
+			if i > 2 {
+				sum += i
+			}
+		}
+		storage.Put(ctx, op, sum)
+		return sum
+	}`
+	prog, err := compiler.Compile(strings.NewReader(src))
+	require.NoError(t, err)
+
+	out := decompile(t, prog)
+	require.Contains(t, out, "func Main(arg0, arg1 interface{}) interface{} {")
+	require.Contains(t, out, "storage.GetContext()")
+	require.Contains(t, out, "storage.Put(")
+	require.Contains(t, out, "len(arg1)")
+	require.Contains(t, out, "for ")
+	require.Contains(t, out, "if ")
+	require.NotContains(t, out, "locals")
+}
+
+func TestDecompileGarbage(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		prog := make([]byte, 1+rand.Intn(200))
+		rand.Read(prog)
+		require.NotPanics(t, func() { decompile(t, prog) })
+	}
+}
@@ -0,0 +1,607 @@
+package decompiler
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// state is the symbolic state of the VM at block boundary.
+type state struct {
+	stack []expr
+	alt   []expr
+}
+
+// exec executes block instructions symbolically.
+type exec struct {
+	f     *function
+	stack []expr
+	alt   []expr
+	stmts []string
+	// prologue is set while function arguments are being stored into
+	// locals, these stores are not shown.
+	prologue bool
+}
+
+func newExec(f *function, s *state, entry bool) *exec {
+	return &exec{
+		f:        f,
+		stack:    append([]expr(nil), s.stack...),
+		alt:      append([]expr(nil), s.alt...),
+		prologue: entry,
+	}
+}
+
+// exitState returns the state successors are entered with, stack items are
+// stored in variables named after their depth.
+func (e *exec) exitState() *state {
+	s := &state{
+		stack: make([]expr, len(e.stack)),
+		alt:   append([]expr(nil), e.alt...),
+	}
+	for i := range e.stack {
+		s.stack[i] = ident{name: stackVar(i)}
+	}
+	return s
+}
+
+// stackVar returns the name of the variable keeping stack item at depth i
+// between blocks.
+func stackVar(i int) string {
+	return fmt.Sprintf("s%d", i)
+}
+
+// spillChanges checks whether spilling changes any stack variable x uses.
+func (e *exec) spillChanges(x expr) bool {
+	for i := range e.stack {
+		name := stackVar(i)
+		if id, ok := e.stack[i].(ident); (!ok || id.name != name) && usesVar(x, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// spill stores stack items into stack variables.
+func (e *exec) spill() {
+	// Items using stack variables which are overwritten before them are
+	// evaluated first.
+	for i := range e.stack {
+		for k := 0; k < i; k++ {
+			name := stackVar(k)
+			if id, ok := e.stack[k].(ident); (!ok || id.name != name) && usesVar(e.stack[i], name) {
+				e.stack[i] = e.temp(e.stack[i])
+				break
+			}
+		}
+	}
+	for i, x := range e.stack {
+		name := stackVar(i)
+		if id, ok := x.(ident); ok && id.name == name {
+			continue
+		}
+		e.f.declare(name)
+		e.stmt(name + " = " + x.String())
+		e.stack[i] = ident{name: name}
+	}
+}
+
+// need ensures there are at least n items on the stack, missing ones are
+// function arguments.
+func (e *exec) need(n int) {
+	for len(e.stack) < n {
+		e.stack = append([]expr{e.f.newArg()}, e.stack...)
+	}
+}
+
+func (e *exec) pop() expr {
+	e.need(1)
+	x := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	return x
+}
+
+// popN pops n items, the top one is the first.
+func (e *exec) popN(n int) []expr {
+	res := make([]expr, n)
+	for i := range res {
+		res[i] = e.pop()
+	}
+	return res
+}
+
+func (e *exec) push(x expr) {
+	e.stack = append(e.stack, x)
+}
+
+// dup returns the i-th item from the top making sure it can be duplicated.
+func (e *exec) dup(i int) expr {
+	e.need(i + 1)
+	n := len(e.stack) - 1 - i
+	if e.stack[n].sideEffects() {
+		e.stack[n] = e.temp(e.stack[n])
+	}
+	return e.stack[n]
+}
+
+func (e *exec) stmt(s string) {
+	e.prologue = false
+	e.stmts = append(e.stmts, s)
+}
+
+// temp stores x in a temporary variable.
+func (e *exec) temp(x expr) expr {
+	t := e.f.newTemp()
+	e.stmt(t.name + " = " + x.String())
+	return t
+}
+
+// flush stores all stack items with side effects into temporary variables so
+// that they're evaluated before the statement following.
+func (e *exec) flush() {
+	for i := range e.stack {
+		if e.stack[i].sideEffects() {
+			e.stack[i] = e.temp(e.stack[i])
+		}
+	}
+}
+
+// call pushes the result of a call with side effects or emits a statement.
+func (e *exec) call(fn string, args []expr, results int) {
+	e.flush()
+	c := callExpr{fn: fn, args: args}
+	switch results {
+	case 0:
+		e.stmt(c.String())
+	case 1:
+		e.push(c)
+	default:
+		ts := make([]expr, results)
+		for i := range ts {
+			ts[i] = e.f.newTemp()
+		}
+		e.stmt(joinExprs(ts) + " = " + c.String())
+		for i := len(ts) - 1; i >= 0; i-- {
+			e.push(ts[i])
+		}
+	}
+}
+
+// run executes the block.
+func (e *exec) run(b *block) {
+	p := e.f.p
+	b.term.kind = termNone
+	for i := b.first; i <= b.last; i++ {
+		in := &p.instrs[i]
+		if in.invalid {
+			e.flush()
+			b.term.kind = termStmt
+			b.term.stmt = fmt.Sprintf("panic(\"invalid instruction at %d\")", in.offset)
+			break
+		}
+		switch in.op {
+		case opcode.JMP:
+			e.spill()
+			b.term.kind = termJump
+		case opcode.JMPIF, opcode.JMPIFNOT:
+			cond := e.pop()
+			if in.op == opcode.JMPIFNOT {
+				cond = negate(cond)
+			}
+			if e.spillChanges(cond) {
+				cond = e.temp(cond)
+			}
+			e.spill()
+			b.term.kind = termCond
+			b.term.cond = cond
+		case opcode.RET:
+			e.flush()
+			b.term.kind = termStmt
+			b.term.stmt = "return"
+			if n := len(e.stack); n != 0 {
+				if n > e.f.results {
+					e.f.results = n
+				}
+				b.term.stmt += " " + joinExprs(e.popN(n))
+			}
+		case opcode.THROW:
+			e.flush()
+			b.term.kind = termStmt
+			b.term.stmt = `panic("THROW")`
+		case opcode.TAILCALL, opcode.CALLET, opcode.CALLEDT:
+			e.instr(in)
+			b.term.kind = termStmt
+			b.term.stmt = "return " + joinExprs(e.popN(len(e.stack)))
+		default:
+			e.instr(in)
+		}
+	}
+	if b.term.kind == termNone {
+		e.spill()
+	}
+	b.stmts = e.stmts
+}
+
+// binaryOps maps opcodes to Go binary operators.
+var binaryOps = map[opcode.Opcode]string{
+	opcode.ADD:         "+",
+	opcode.SUB:         "-",
+	opcode.MUL:         "*",
+	opcode.DIV:         "/",
+	opcode.MOD:         "%",
+	opcode.SHL:         "<<",
+	opcode.SHR:         ">>",
+	opcode.AND:         "&",
+	opcode.OR:          "|",
+	opcode.XOR:         "^",
+	opcode.BOOLAND:     "&&",
+	opcode.BOOLOR:      "||",
+	opcode.EQUAL:       "==",
+	opcode.NUMEQUAL:    "==",
+	opcode.NUMNOTEQUAL: "!=",
+	opcode.LT:          "<",
+	opcode.GT:          ">",
+	opcode.LTE:         "<=",
+	opcode.GTE:         ">=",
+}
+
+// pureFuncs maps opcodes to builtin functions, the number of arguments
+// they take is given too.
+var pureFuncs = map[opcode.Opcode]struct {
+	name string
+	args int
+}{
+	opcode.SIZE:          {"len", 1},
+	opcode.ARRAYSIZE:     {"len", 1},
+	opcode.ABS:           {"abs", 1},
+	opcode.SIGN:          {"sign", 1},
+	opcode.MIN:           {"min", 2},
+	opcode.MAX:           {"max", 2},
+	opcode.WITHIN:        {"within", 3},
+	opcode.CAT:           {"concat", 2},
+	opcode.SHA1:          {"crypto.SHA1", 1},
+	opcode.SHA256:        {"crypto.SHA256", 1},
+	opcode.HASH160:       {"crypto.Hash160", 1},
+	opcode.HASH256:       {"crypto.Hash256", 1},
+	opcode.CHECKSIG:      {"checkSig", 2},
+	opcode.VERIFY:        {"crypto.VerifySignature", 3},
+	opcode.HASKEY:        {"hasKey", 2},
+	opcode.KEYS:          {"keys", 1},
+	opcode.VALUES:        {"values", 1},
+	opcode.CHECKMULTISIG: {"checkMultisig", 2},
+}
+
+// instr executes a single instruction which doesn't end the block.
+func (e *exec) instr(in *instruction) {
+	op := in.op
+	if op >= opcode.PUSHBYTES1 && op <= opcode.PUSHBYTES75 ||
+		op == opcode.PUSHDATA1 || op == opcode.PUSHDATA2 || op == opcode.PUSHDATA4 {
+		e.push(bytesLit{val: in.param})
+		return
+	}
+	if op == opcode.PUSH0 || op == opcode.PUSHM1 || op >= opcode.PUSH1 && op <= opcode.PUSH16 {
+		n := int64(op) - int64(opcode.PUSH1) + 1
+		if op == opcode.PUSH0 {
+			n = 0
+		}
+		e.push(intLit{val: big.NewInt(n)})
+		return
+	}
+	if sym, ok := binaryOps[op]; ok {
+		y, x := e.pop(), e.pop()
+		e.push(binaryExpr{op: sym, x: x, y: y})
+		return
+	}
+	if fn, ok := pureFuncs[op]; ok {
+		args := e.popN(fn.args)
+		reverse(args)
+		e.push(callExpr{fn: fn.name, args: args, pure: true})
+		return
+	}
+
+	switch op {
+	case opcode.NOP:
+	case opcode.INC:
+		e.push(binaryExpr{op: "+", x: e.pop(), y: intLit{val: big.NewInt(1)}})
+	case opcode.DEC:
+		e.push(binaryExpr{op: "-", x: e.pop(), y: intLit{val: big.NewInt(1)}})
+	case opcode.NEGATE:
+		e.push(unaryExpr{op: "-", x: e.pop()})
+	case opcode.INVERT:
+		e.push(unaryExpr{op: "^", x: e.pop()})
+	case opcode.NOT:
+		e.push(negate(e.pop()))
+	case opcode.NZ:
+		e.push(binaryExpr{op: "!=", x: e.pop(), y: intLit{val: big.NewInt(0)}})
+
+	case opcode.SUBSTR:
+		n, idx, x := e.pop(), e.pop(), e.pop()
+		e.push(sliceExpr{x: x, lo: idx, hi: binaryExpr{op: "+", x: idx, y: n}})
+	case opcode.LEFT:
+		n, x := e.pop(), e.pop()
+		e.push(sliceExpr{x: x, hi: n})
+	case opcode.RIGHT:
+		n, x := e.pop(), e.pop()
+		lo := binaryExpr{op: "-", x: callExpr{fn: "len", args: []expr{x}, pure: true}, y: n}
+		e.push(sliceExpr{x: x, lo: lo})
+
+	case opcode.DUP:
+		e.push(e.dup(0))
+	case opcode.OVER:
+		e.push(e.dup(1))
+	case opcode.PICK:
+		if n, ok := e.popInt(); ok {
+			e.push(e.dup(int(n)))
+		} else {
+			e.unknown(in)
+		}
+	case opcode.TUCK:
+		x := e.dup(0)
+		e.need(2)
+		e.stack = append(e.stack[:len(e.stack)-2], append([]expr{x}, e.stack[len(e.stack)-2:]...)...)
+	case opcode.DROP:
+		e.drop(e.pop())
+	case opcode.NIP:
+		x := e.pop()
+		e.drop(e.pop())
+		e.push(x)
+	case opcode.SWAP:
+		e.need(2)
+		n := len(e.stack)
+		e.stack[n-1], e.stack[n-2] = e.stack[n-2], e.stack[n-1]
+	case opcode.ROT:
+		e.roll(2)
+	case opcode.ROLL:
+		if n, ok := e.popInt(); ok {
+			e.roll(int(n))
+		} else {
+			e.unknown(in)
+		}
+	case opcode.XDROP:
+		if n, ok := e.popInt(); ok {
+			e.need(int(n) + 1)
+			i := len(e.stack) - 1 - int(n)
+			e.drop(e.stack[i])
+			e.stack = append(e.stack[:i], e.stack[i+1:]...)
+		} else {
+			e.unknown(in)
+		}
+	case opcode.XSWAP:
+		if n, ok := e.popInt(); ok {
+			e.need(int(n) + 1)
+			top, i := len(e.stack)-1, len(e.stack)-1-int(n)
+			e.stack[top], e.stack[i] = e.stack[i], e.stack[top]
+		} else {
+			e.unknown(in)
+		}
+	case opcode.XTUCK:
+		if n, ok := e.popInt(); ok {
+			x := e.dup(0)
+			e.need(int(n) + 1)
+			i := len(e.stack) - int(n)
+			e.stack = append(e.stack[:i], append([]expr{x}, e.stack[i:]...)...)
+		} else {
+			e.unknown(in)
+		}
+	case opcode.DEPTH:
+		e.push(callExpr{fn: "depth", pure: true})
+
+	case opcode.TOALTSTACK:
+		x := e.pop()
+		if c, ok := x.(callExpr); ok && c.fn == newArrayFunc && e.prologue && len(e.alt) == 0 {
+			x = localsArray{}
+		}
+		e.alt = append(e.alt, x)
+	case opcode.DUPFROMALTSTACK:
+		e.push(e.altTop(false))
+	case opcode.FROMALTSTACK:
+		e.push(e.altTop(true))
+
+	case opcode.PICKITEM:
+		key, x := e.pop(), e.pop()
+		if _, ok := x.(localsArray); ok {
+			if n, ok := intValue(key); ok {
+				e.push(e.f.local(n))
+				return
+			}
+		}
+		e.push(indexExpr{x: x, key: key})
+	case opcode.SETITEM:
+		val, key, x := e.pop(), e.pop(), e.pop()
+		if _, ok := x.(localsArray); ok {
+			if n, ok := intValue(key); ok {
+				e.setLocal(n, val)
+				return
+			}
+		}
+		e.flush()
+		e.stmt(indexExpr{x: x, key: key}.String() + " = " + val.String())
+	case opcode.NEWARRAY, opcode.NEWSTRUCT:
+		n := e.pop()
+		fn := newArrayFunc
+		if op == opcode.NEWSTRUCT {
+			fn = "newStruct"
+		}
+		e.push(callExpr{fn: fn, args: []expr{n}, pure: true})
+	case opcode.NEWMAP:
+		e.push(compositeLit{typ: "map[interface{}]interface{}"})
+	case opcode.PACK:
+		if n, ok := e.popInt(); ok {
+			e.push(compositeLit{typ: "[]interface{}", elems: e.popN(int(n))})
+		} else {
+			e.unknown(in)
+		}
+	case opcode.UNPACK:
+		x := e.pop()
+		if c, ok := x.(compositeLit); ok && c.typ == "[]interface{}" {
+			for i := len(c.elems) - 1; i >= 0; i-- {
+				e.push(c.elems[i])
+			}
+			e.push(intLit{val: big.NewInt(int64(len(c.elems)))})
+		} else {
+			e.push(x)
+			e.unknown(in)
+		}
+	case opcode.APPEND:
+		item, x := e.pop(), e.pop()
+		e.flush()
+		e.stmt(fmt.Sprintf("%s = append(%s, %s)", x, x, item))
+	case opcode.REVERSE:
+		e.flush()
+		e.stmt(fmt.Sprintf("reverse(%s)", e.pop()))
+	case opcode.REMOVE:
+		key, x := e.pop(), e.pop()
+		e.flush()
+		e.stmt(fmt.Sprintf("delete(%s, %s)", x, key))
+	case opcode.THROWIFNOT:
+		cond := e.pop()
+		e.flush()
+		e.stmt(fmt.Sprintf("if %s {\n\tpanic(\"THROWIFNOT\")\n}", negate(cond)))
+
+	case opcode.SYSCALL:
+		name, info, ok := getInterop(in.param)
+		if !ok {
+			e.flush()
+			e.stmt(fmt.Sprintf("syscall(%q) // unknown stack effect", name))
+			return
+		}
+		e.call(name, e.popN(info.params), info.results)
+	case opcode.CALL, opcode.CALLI:
+		var (
+			name            string
+			params, results int
+		)
+		if t, ok := in.target(); ok {
+			name, params, results = e.f.p.signature(t)
+		}
+		if op == opcode.CALLI {
+			results, params = int(in.param[0]), int(in.param[1])
+		}
+		e.call(name, e.popN(params), results)
+	case opcode.APPCALL, opcode.TAILCALL:
+		// Standard contracts take operation and arguments array.
+		args := append([]expr{scriptHash(in.param)}, e.popN(2)...)
+		e.call("engine.AppCall", args, 1)
+	case opcode.CALLE, opcode.CALLET, opcode.CALLED, opcode.CALLEDT:
+		results, params := int(in.param[0]), int(in.param[1])
+		var h expr
+		if op == opcode.CALLED || op == opcode.CALLEDT {
+			h = e.pop()
+		} else {
+			h = scriptHash(in.param[2:])
+		}
+		e.call("engine.DynamicCall", append([]expr{h}, e.popN(params)...), results)
+	default:
+		e.unknown(in)
+	}
+}
+
+// scriptHash returns script hash literal for the given big-endian bytes.
+func scriptHash(b []byte) expr {
+	h, err := util.Uint160DecodeBytesBE(b)
+	if err != nil {
+		return bytesLit{val: b}
+	}
+	return ident{name: "0x" + h.StringLE()}
+}
+
+// newArrayFunc is the name used for arrays created with NEWARRAY.
+const newArrayFunc = "newArray"
+
+// popInt pops a constant integer.
+func (e *exec) popInt() (int64, bool) {
+	n, ok := intValue(e.pop())
+	if !ok || n < 0 || n > 1024 {
+		return 0, false
+	}
+	return n, true
+}
+
+// roll moves the n-th item to the top.
+func (e *exec) roll(n int) {
+	e.need(n + 1)
+	i := len(e.stack) - 1 - n
+	x := e.stack[i]
+	e.stack = append(append(e.stack[:i], e.stack[i+1:]...), x)
+}
+
+// drop drops x, it's evaluated as a statement if it has side effects.
+func (e *exec) drop(x expr) {
+	if x.sideEffects() {
+		e.flush()
+		e.stmt(x.String())
+	}
+}
+
+// altTop returns the top item of the alt stack.
+func (e *exec) altTop(remove bool) expr {
+	if len(e.alt) == 0 {
+		return callExpr{fn: "altstack", pure: true}
+	}
+	x := e.alt[len(e.alt)-1]
+	if remove {
+		e.alt = e.alt[:len(e.alt)-1]
+	}
+	return x
+}
+
+// setLocal stores val into the n-th local variable.
+func (e *exec) setLocal(n int64, val expr) {
+	if id, ok := val.(ident); ok && e.prologue && len(id.name) > 3 && id.name[:3] == "arg" {
+		if _, ok := e.f.locals[n]; !ok {
+			e.f.locals[n] = id.name
+			return
+		}
+	}
+	name := e.f.local(n).name
+	for i := range e.stack {
+		if usesVar(e.stack[i], name) {
+			e.stack[i] = e.temp(e.stack[i])
+		}
+	}
+	e.stmt(name + " = " + val.String())
+}
+
+// unknown handles instructions with stack effect which can't be recovered.
+func (e *exec) unknown(in *instruction) {
+	e.flush()
+	e.stmt(fmt.Sprintf("// %s: unknown stack effect", in.op))
+}
+
+// usesVar checks whether x depends on the variable with the given name.
+func usesVar(x expr, name string) bool {
+	switch t := x.(type) {
+	case ident:
+		return t.name == name
+	case callExpr:
+		return anyUsesVar(name, t.args...)
+	case binaryExpr:
+		return anyUsesVar(name, t.x, t.y)
+	case unaryExpr:
+		return usesVar(t.x, name)
+	case indexExpr:
+		return anyUsesVar(name, t.x, t.key)
+	case sliceExpr:
+		return anyUsesVar(name, t.x, t.lo, t.hi)
+	case compositeLit:
+		return anyUsesVar(name, t.elems...)
+	}
+	return false
+}
+
+func anyUsesVar(name string, es ...expr) bool {
+	for _, x := range es {
+		if x != nil && usesVar(x, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func reverse(es []expr) {
+	for i, j := 0, len(es)-1; i < j; i, j = i+1, j-1 {
+		es[i], es[j] = es[j], es[i]
+	}
+}
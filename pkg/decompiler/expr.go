@@ -0,0 +1,214 @@
+package decompiler
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+)
+
+// expr is an expression recovered from the evaluation stack.
+type expr interface {
+	String() string
+	// prec returns operator precedence the same way Go defines it, primary
+	// expressions have the highest one.
+	prec() int
+	// sideEffects returns true if the expression can't be evaluated twice
+	// or moved around.
+	sideEffects() bool
+}
+
+const primaryPrec = 6
+
+type (
+	// ident is a variable.
+	ident struct {
+		name string
+	}
+	// intLit is an integer constant.
+	intLit struct {
+		val *big.Int
+	}
+	// bytesLit is a byte array constant.
+	bytesLit struct {
+		val []byte
+	}
+	// localsArray is the array of local variables created by the compiler
+	// in function prologue.
+	localsArray struct{}
+	// callExpr is a function call.
+	callExpr struct {
+		fn   string
+		args []expr
+		// pure is set for builtins without side effects.
+		pure bool
+	}
+	// binaryExpr is a binary operation.
+	binaryExpr struct {
+		op   string
+		x, y expr
+	}
+	// unaryExpr is a unary operation.
+	unaryExpr struct {
+		op string
+		x  expr
+	}
+	// indexExpr is array or map element access.
+	indexExpr struct {
+		x, key expr
+	}
+	// sliceExpr is a slice of byte array, lo and hi are optional.
+	sliceExpr struct {
+		x, lo, hi expr
+	}
+	// compositeLit is an array or struct literal.
+	compositeLit struct {
+		typ   string
+		elems []expr
+	}
+)
+
+func (e ident) String() string       { return e.name }
+func (e ident) prec() int            { return primaryPrec }
+func (e ident) sideEffects() bool    { return false }
+func (e intLit) String() string      { return e.val.String() }
+func (e intLit) prec() int           { return primaryPrec }
+func (e intLit) sideEffects() bool   { return false }
+func (e bytesLit) prec() int         { return primaryPrec }
+func (e bytesLit) sideEffects() bool { return false }
+
+func (e bytesLit) String() string {
+	if len(e.val) != 0 && utf8.Valid(e.val) {
+		printable := true
+		for _, r := range string(e.val) {
+			if !unicode.IsPrint(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return strconv.Quote(string(e.val))
+		}
+	}
+	elems := make([]string, len(e.val))
+	for i := range e.val {
+		elems[i] = fmt.Sprintf("0x%02x", e.val[i])
+	}
+	return "[]byte{" + strings.Join(elems, ", ") + "}"
+}
+
+func (e localsArray) String() string     { return "locals" }
+func (e localsArray) prec() int          { return primaryPrec }
+func (e localsArray) sideEffects() bool  { return false }
+func (e callExpr) String() string        { return e.fn + "(" + joinExprs(e.args) + ")" }
+func (e callExpr) prec() int             { return primaryPrec }
+func (e callExpr) sideEffects() bool     { return !e.pure || anySideEffects(e.args...) }
+func (e binaryExpr) prec() int           { return binaryPrec[e.op] }
+func (e binaryExpr) sideEffects() bool   { return anySideEffects(e.x, e.y) }
+func (e unaryExpr) String() string       { return e.op + paren(e.x, primaryPrec) }
+func (e unaryExpr) prec() int            { return primaryPrec - 1 }
+func (e unaryExpr) sideEffects() bool    { return e.x.sideEffects() }
+func (e indexExpr) String() string       { return paren(e.x, primaryPrec) + "[" + e.key.String() + "]" }
+func (e indexExpr) prec() int            { return primaryPrec }
+func (e indexExpr) sideEffects() bool    { return anySideEffects(e.x, e.key) }
+func (e sliceExpr) prec() int            { return primaryPrec }
+func (e sliceExpr) sideEffects() bool    { return anySideEffects(e.x, e.lo, e.hi) }
+func (e compositeLit) String() string    { return e.typ + "{" + joinExprs(e.elems) + "}" }
+func (e compositeLit) prec() int         { return primaryPrec }
+func (e compositeLit) sideEffects() bool { return anySideEffects(e.elems...) }
+
+func (e binaryExpr) String() string {
+	p := e.prec()
+	// Left-associative operators need parens only for the right operand of
+	// the same precedence.
+	return paren(e.x, p) + " " + e.op + " " + paren(e.y, p+1)
+}
+
+func (e sliceExpr) String() string {
+	var lo, hi string
+	if e.lo != nil {
+		lo = e.lo.String()
+	}
+	if e.hi != nil {
+		hi = e.hi.String()
+	}
+	return paren(e.x, primaryPrec) + "[" + lo + ":" + hi + "]"
+}
+
+// binaryPrec contains precedences of binary operators.
+var binaryPrec = map[string]int{
+	"*": 5, "/": 5, "%": 5, "<<": 5, ">>": 5, "&": 5,
+	"+": 4, "-": 4, "|": 4, "^": 4,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"&&": 2,
+	"||": 1,
+}
+
+// negatedOps contains comparison operators along with their negations.
+var negatedOps = map[string]string{
+	"==": "!=", "!=": "==",
+	"<": ">=", ">=": "<",
+	">": "<=", "<=": ">",
+}
+
+// negate returns logical negation of e.
+func negate(e expr) expr {
+	switch t := e.(type) {
+	case binaryExpr:
+		if op, ok := negatedOps[t.op]; ok {
+			return binaryExpr{op: op, x: t.x, y: t.y}
+		}
+	case unaryExpr:
+		if t.op == "!" {
+			return t.x
+		}
+	}
+	return unaryExpr{op: "!", x: e}
+}
+
+// intValue returns integer value of constant e.
+func intValue(e expr) (int64, bool) {
+	switch t := e.(type) {
+	case intLit:
+		if t.val.IsInt64() {
+			return t.val.Int64(), true
+		}
+	case bytesLit:
+		if len(t.val) <= 8 {
+			if n := emit.BytesToInt(t.val); n.IsInt64() {
+				return n.Int64(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// paren returns e string representation wrapped in parentheses if its
+// precedence is lower than p.
+func paren(e expr, p int) string {
+	if e.prec() < p {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+func joinExprs(es []expr) string {
+	ss := make([]string, len(es))
+	for i := range es {
+		ss[i] = es[i].String()
+	}
+	return strings.Join(ss, ", ")
+}
+
+func anySideEffects(es ...expr) bool {
+	for _, e := range es {
+		if e != nil && e.sideEffects() {
+			return true
+		}
+	}
+	return false
+}
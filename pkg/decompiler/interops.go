@@ -0,0 +1,160 @@
+package decompiler
+
+import (
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+)
+
+// interopInfo describes stack effect of an interop function.
+type interopInfo struct {
+	params  int
+	results int
+}
+
+// interops contains stack effects of standard interop functions, names are
+// given without Neo./System./AntShares. prefix as they're the same for all
+// of them.
+var interops = map[string]interopInfo{
+	"Account.GetBalance":                     {2, 1},
+	"Account.GetScriptHash":                  {1, 1},
+	"Account.GetVotes":                       {1, 1},
+	"Account.IsStandard":                     {1, 1},
+	"Asset.Create":                           {7, 1},
+	"Asset.GetAdmin":                         {1, 1},
+	"Asset.GetAmount":                        {1, 1},
+	"Asset.GetAssetId":                       {1, 1},
+	"Asset.GetAssetID":                       {1, 1},
+	"Asset.GetAssetType":                     {1, 1},
+	"Asset.GetAvailable":                     {1, 1},
+	"Asset.GetIssuer":                        {1, 1},
+	"Asset.GetOwner":                         {1, 1},
+	"Asset.GetPrecision":                     {1, 1},
+	"Asset.Renew":                            {2, 1},
+	"Attribute.GetData":                      {1, 1},
+	"Attribute.GetUsage":                     {1, 1},
+	"Block.GetTransaction":                   {2, 1},
+	"Block.GetTransactionCount":              {1, 1},
+	"Block.GetTransactions":                  {1, 1},
+	"Blockchain.GetAccount":                  {1, 1},
+	"Blockchain.GetAsset":                    {1, 1},
+	"Blockchain.GetBlock":                    {1, 1},
+	"Blockchain.GetContract":                 {1, 1},
+	"Blockchain.GetHeader":                   {1, 1},
+	"Blockchain.GetHeight":                   {0, 1},
+	"Blockchain.GetTransaction":              {1, 1},
+	"Blockchain.GetTransactionHeight":        {1, 1},
+	"Blockchain.GetValidators":               {0, 1},
+	"Contract.Create":                        {9, 1},
+	"Contract.Destroy":                       {0, 0},
+	"Contract.GetScript":                     {1, 1},
+	"Contract.GetStorageContext":             {1, 1},
+	"Contract.IsPayable":                     {1, 1},
+	"Contract.Migrate":                       {9, 1},
+	"Enumerator.Concat":                      {2, 1},
+	"Enumerator.Create":                      {1, 1},
+	"Enumerator.Next":                        {1, 1},
+	"Enumerator.Value":                       {1, 1},
+	"ExecutionEngine.GetCallingScriptHash":   {0, 1},
+	"ExecutionEngine.GetEntryScriptHash":     {0, 1},
+	"ExecutionEngine.GetExecutingScriptHash": {0, 1},
+	"ExecutionEngine.GetScriptContainer":     {0, 1},
+	"Header.GetConsensusData":                {1, 1},
+	"Header.GetHash":                         {1, 1},
+	"Header.GetIndex":                        {1, 1},
+	"Header.GetMerkleRoot":                   {1, 1},
+	"Header.GetNextConsensus":                {1, 1},
+	"Header.GetPrevHash":                     {1, 1},
+	"Header.GetTimestamp":                    {1, 1},
+	"Header.GetVersion":                      {1, 1},
+	"Input.GetHash":                          {1, 1},
+	"Input.GetIndex":                         {1, 1},
+	"InvocationTransaction.GetScript":        {1, 1},
+	"Iterator.Concat":                        {2, 1},
+	"Iterator.Create":                        {1, 1},
+	"Iterator.Key":                           {1, 1},
+	"Iterator.Keys":                          {1, 1},
+	"Iterator.Next":                          {1, 1},
+	"Iterator.Value":                         {1, 1},
+	"Iterator.Values":                        {1, 1},
+	"Output.GetAssetId":                      {1, 1},
+	"Output.GetAssetID":                      {1, 1},
+	"Output.GetScriptHash":                   {1, 1},
+	"Output.GetValue":                        {1, 1},
+	"Runtime.CheckWitness":                   {1, 1},
+	"Runtime.Deserialize":                    {1, 1},
+	"Runtime.GetTime":                        {0, 1},
+	"Runtime.GetTrigger":                     {0, 1},
+	"Runtime.Log":                            {1, 0},
+	"Runtime.Notify":                         {1, 0},
+	"Runtime.Platform":                       {0, 1},
+	"Runtime.Serialize":                      {1, 1},
+	"Storage.Delete":                         {2, 0},
+	"Storage.Find":                           {2, 1},
+	"Storage.Get":                            {2, 1},
+	"Storage.GetContext":                     {0, 1},
+	"Storage.GetReadOnlyContext":             {0, 1},
+	"Storage.Put":                            {3, 0},
+	"Storage.PutEx":                          {4, 0},
+	"StorageContext.AsReadOnly":              {1, 1},
+	"Transaction.GetAttributes":              {1, 1},
+	"Transaction.GetHash":                    {1, 1},
+	"Transaction.GetInputs":                  {1, 1},
+	"Transaction.GetOutputs":                 {1, 1},
+	"Transaction.GetReferences":              {1, 1},
+	"Transaction.GetScript":                  {1, 1},
+	"Transaction.GetType":                    {1, 1},
+	"Transaction.GetUnspentCoins":            {1, 1},
+	"Transaction.GetWitnesses":               {1, 1},
+	"Witness.GetVerificationScript":          {1, 1},
+}
+
+// interopPrefixes are prefixes of standard interop function names.
+var interopPrefixes = []string{"Neo.", "System.", "AntShares."}
+
+// interopPackages maps interop classes to pkg/interop packages where they
+// differ from lowercased class name.
+var interopPackages = map[string]string{
+	"ExecutionEngine":       "engine",
+	"InvocationTransaction": "transaction",
+	"StorageContext":        "storage",
+}
+
+// interopIDs maps interop IDs to their names to resolve 4-byte syscalls.
+var interopIDs = make(map[uint32]string)
+
+func init() {
+	for name := range interops {
+		for _, prefix := range interopPrefixes {
+			interopIDs[vm.InteropNameToID([]byte(prefix+name))] = prefix + name
+		}
+	}
+}
+
+// getInterop returns pkg/interop function name for the given syscall
+// parameter along with its stack effect.
+func getInterop(param []byte) (string, interopInfo, bool) {
+	api := string(param)
+	if len(param) == 4 {
+		if name, ok := interopIDs[vm.GetInteropID(param)]; ok {
+			api = name
+		}
+	}
+	for _, prefix := range interopPrefixes {
+		if !strings.HasPrefix(api, prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(api, prefix)
+		info, ok := interops[name]
+		if !ok {
+			break
+		}
+		i := strings.IndexByte(name, '.')
+		pkg, ok := interopPackages[name[:i]]
+		if !ok {
+			pkg = strings.ToLower(name[:i])
+		}
+		return pkg + name[i:], info, true
+	}
+	return api, interopInfo{}, false
+}
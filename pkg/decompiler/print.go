@@ -0,0 +1,203 @@
+package decompiler
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// loop is a loop being printed, header and exit are block indexes.
+type loop struct {
+	header, exit int
+}
+
+// printer prints function body recovering its structure.
+type printer struct {
+	f      *function
+	w      io.Writer
+	indent int
+	loops  []loop
+	// labels are blocks which are jumped to with goto.
+	labels map[int]bool
+}
+
+// print writes function to w.
+func (f *function) print(w io.Writer) {
+	// Labels are only known after the body is printed, so it's done twice.
+	p := &printer{f: f, w: ioutil.Discard, indent: 1, labels: make(map[int]bool)}
+	p.body()
+	p.w = w
+
+	fmt.Fprintf(w, "// %s is at offset %d.\nfunc %s(", f.name, f.start, f.name)
+	for i := 0; i < f.params; i++ {
+		if i != 0 {
+			fmt.Fprint(w, ", ")
+		}
+		fmt.Fprintf(w, "arg%d", i)
+	}
+	if f.params != 0 {
+		fmt.Fprint(w, " interface{}")
+	}
+	fmt.Fprint(w, ")")
+	switch {
+	case f.results == 1:
+		fmt.Fprint(w, " interface{}")
+	case f.results > 1:
+		fmt.Fprint(w, " ("+strings.TrimSuffix(strings.Repeat("interface{}, ", f.results), ", ")+")")
+	}
+	fmt.Fprintln(w, " {")
+	if len(f.vars) != 0 {
+		p.line("var " + strings.Join(f.vars, ", ") + " interface{}")
+	}
+	p.body()
+	fmt.Fprintln(w, "}")
+}
+
+// body prints all function blocks.
+func (p *printer) body() {
+	if p.f.entry != 0 {
+		p.line(p.jump(p.f.entry, -1))
+	}
+	p.blocks(0, len(p.f.blocks), len(p.f.blocks))
+}
+
+// line prints a possibly multiline statement.
+func (p *printer) line(s string) {
+	if s == "" {
+		return
+	}
+	for _, l := range strings.Split(s, "\n") {
+		fmt.Fprintln(p.w, strings.Repeat("\t", p.indent)+l)
+	}
+}
+
+// jump returns a statement transferring control to the block to, next is
+// the block executed after the current one.
+func (p *printer) jump(to, next int) string {
+	switch {
+	case to < 0:
+		return `panic("jump to invalid offset")`
+	case to == next:
+		return ""
+	case len(p.loops) != 0 && to == p.loops[len(p.loops)-1].exit:
+		return "break"
+	case len(p.loops) != 0 && to == p.loops[len(p.loops)-1].header:
+		return "continue"
+	}
+	p.labels[to] = true
+	return fmt.Sprintf("goto L%d", p.f.offset(to))
+}
+
+// backEdge returns the last block in [i, hi) jumping to the i-th one.
+func (p *printer) backEdge(i, hi int) (int, bool) {
+	for j := hi - 1; j >= i; j-- {
+		if t := p.f.blocks[j].term; (t.kind == termJump || t.kind == termCond) && t.target == i {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// blocks prints blocks [lo, hi), cont is the block executed after them.
+func (p *printer) blocks(lo, hi, cont int) {
+	for i := lo; i < hi; {
+		inLoop := len(p.loops) != 0 && p.loops[len(p.loops)-1].header == i
+		if j, ok := p.backEdge(i, hi); ok && !inLoop {
+			p.loop(i, j)
+			i = j + 1
+			continue
+		}
+
+		b := p.f.blocks[i]
+		// Loop header label is printed before the loop.
+		if p.labels[i] && !inLoop {
+			fmt.Fprintf(p.w, "%sL%d:\n", strings.Repeat("\t", p.indent-1), p.f.offset(i))
+		}
+		for _, s := range b.stmts {
+			p.line(s)
+		}
+		next := i + 1
+		if next == hi {
+			next = cont
+		}
+		switch b.term.kind {
+		case termNone:
+			p.line(p.jump(i+1, next))
+		case termJump:
+			p.line(p.jump(b.term.target, next))
+		case termStmt:
+			p.line(b.term.stmt)
+		case termCond:
+			if end, ok := p.ifStmt(i, hi, cont); ok {
+				i = end
+				continue
+			}
+			if s := p.jump(b.term.target, next); s != "" {
+				p.line("if " + b.term.cond.String() + " {")
+				p.indent++
+				p.line(s)
+				p.indent--
+				p.line("}")
+			}
+			p.line(p.jump(i+1, next))
+		}
+		i++
+	}
+}
+
+// ifStmt tries to print if statement ending the i-th block and returns the
+// index of the block following it.
+func (p *printer) ifStmt(i, hi, cont int) (int, bool) {
+	term := p.f.blocks[i].term
+	t := term.target
+	if t <= i+1 || t > hi || t == hi && cont != hi {
+		return 0, false
+	}
+	if len(p.loops) != 0 {
+		if l := p.loops[len(p.loops)-1]; t == l.exit || t == l.header {
+			return 0, false
+		}
+	}
+	p.line("if " + negate(term.cond).String() + " {")
+	p.indent++
+	end := t
+	if last := p.f.blocks[t-1].term; last.kind == termJump {
+		e := last.target
+		if e > t && (e < hi || e == hi && cont == hi) {
+			end = e
+		}
+	}
+	p.blocks(i+1, t, end)
+	p.indent--
+	if end != t {
+		p.line("} else {")
+		p.indent++
+		p.blocks(t, end, end)
+		p.indent--
+	}
+	p.line("}")
+	return end, true
+}
+
+// loop prints the loop consisting of blocks [h, j].
+func (p *printer) loop(h, j int) {
+	l := loop{header: h, exit: j + 1}
+	hb := p.f.blocks[h]
+	if p.labels[h] {
+		fmt.Fprintf(p.w, "%sL%d:\n", strings.Repeat("\t", p.indent-1), p.f.offset(h))
+	}
+	body := h
+	if len(hb.stmts) == 0 && hb.term.kind == termCond && hb.term.target == l.exit && h != j {
+		p.line("for " + negate(hb.term.cond).String() + " {")
+		body = h + 1
+	} else {
+		p.line("for {")
+	}
+	p.loops = append(p.loops, l)
+	p.indent++
+	p.blocks(body, l.exit, h)
+	p.indent--
+	p.loops = p.loops[:len(p.loops)-1]
+	p.line("}")
+}
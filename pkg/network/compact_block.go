@@ -0,0 +1,127 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+const (
+	// maxPendingCompactBlocks is the maximum number of compact blocks waiting
+	// for the missing transactions.
+	maxPendingCompactBlocks = 8
+	// maxPeerCompactBlocks is the maximum number of compact blocks waiting
+	// for the missing transactions from a single peer.
+	maxPeerCompactBlocks = 2
+	// compactBlockTimeout is the time to wait for the missing transactions,
+	// the block is fetched the usual way after it.
+	compactBlockTimeout = 10 * time.Second
+)
+
+// compactBlockState is a block being reconstructed from the compact block
+// payload.
+type compactBlockState struct {
+	cb      *payload.CompactBlock
+	shortID func(util.Uint256) uint64
+	txes    []*transaction.Transaction
+	// ids maps transaction index to its short ID for non-prefilled
+	// transactions.
+	ids map[uint16]uint64
+	// missing is a sorted list of transaction indexes that were not found
+	// in the memory pool.
+	missing []uint16
+	// peer is the peer missing transactions were requested from.
+	peer Peer
+	// expires is the time the request for missing transactions expires at.
+	expires time.Time
+}
+
+var errInvalidCompactBlock = errors.New("invalid compact block")
+
+// newCompactBlockState prepares the block for reconstruction filling in
+// prefilled transactions and the ones that can be found in the memory pool.
+func newCompactBlockState(cb *payload.CompactBlock, mp *mempool.Pool) (*compactBlockState, error) {
+	n := cb.TxCount()
+	if n == 0 || n > payload.MaxCompactBlockTxs {
+		return nil, errInvalidCompactBlock
+	}
+	st := &compactBlockState{
+		cb:      cb,
+		shortID: cb.ShortIDFunc(),
+		txes:    make([]*transaction.Transaction, n),
+		ids:     make(map[uint16]uint64, len(cb.ShortIDs)),
+	}
+	for _, p := range cb.Prefilled {
+		if int(p.Index) >= n || st.txes[p.Index] != nil || p.Tx == nil {
+			return nil, errInvalidCompactBlock
+		}
+		st.txes[p.Index] = p.Tx
+	}
+
+	// Transactions with colliding short IDs can't be used for
+	// reconstruction, they're requested from the peer.
+	pooled := make(map[uint64]*transaction.Transaction)
+	if mp != nil {
+		for _, txf := range mp.GetVerifiedTransactions() {
+			id := st.shortID(txf.Tx.Hash())
+			if _, ok := pooled[id]; ok {
+				pooled[id] = nil
+				continue
+			}
+			pooled[id] = txf.Tx
+		}
+	}
+
+	var j int
+	for i := range st.txes {
+		if st.txes[i] != nil {
+			continue
+		}
+		id := cb.ShortIDs[j]
+		j++
+		st.ids[uint16(i)] = id
+		if tx := pooled[id]; tx != nil {
+			st.txes[i] = tx
+		} else {
+			st.missing = append(st.missing, uint16(i))
+		}
+	}
+	return st, nil
+}
+
+// fill puts transactions received from the peer into the missing slots.
+func (st *compactBlockState) fill(txes []*transaction.Transaction) error {
+	if len(txes) != len(st.missing) {
+		return fmt.Errorf("expected %d transactions, got %d", len(st.missing), len(txes))
+	}
+	for i, idx := range st.missing {
+		if txes[i] == nil || st.shortID(txes[i].Hash()) != st.ids[idx] {
+			return fmt.Errorf("unexpected transaction at index %d", idx)
+		}
+		st.txes[idx] = txes[i]
+	}
+	st.missing = nil
+	return nil
+}
+
+// block returns reconstructed block, the block is checked to match its
+// merkle root so that short ID collisions are detected.
+func (st *compactBlockState) block() (*block.Block, error) {
+	if len(st.missing) != 0 {
+		return nil, errors.New("block is incomplete")
+	}
+	b := &block.Block{
+		Base:         st.cb.Base,
+		Transactions: st.txes,
+	}
+	if err := b.Verify(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
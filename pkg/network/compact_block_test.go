@@ -0,0 +1,281 @@
+package network
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+type feerStub struct{}
+
+func (fs *feerStub) NetworkFee(*transaction.Transaction) util.Fixed8 { return 0 }
+func (fs *feerStub) IsLowPriority(util.Fixed8) bool                  { return false }
+func (fs *feerStub) FeePerByte(*transaction.Transaction) util.Fixed8 { return 0 }
+func (fs *feerStub) SystemFee(*transaction.Transaction) util.Fixed8  { return 0 }
+
+func newCompactTestBlock(t *testing.T, n int) *block.Block {
+	b := &block.Block{
+		Base: block.Base{Index: 1},
+		Transactions: []*transaction.Transaction{{
+			Type: transaction.MinerType,
+			Data: &transaction.MinerTX{Nonce: 42},
+		}},
+	}
+	for i := 0; i < n; i++ {
+		b.Transactions = append(b.Transactions, transaction.NewInvocationTX([]byte{byte(i)}, 0))
+	}
+	require.NoError(t, b.RebuildMerkleRoot())
+	return b
+}
+
+func TestCompactBlockReconstruction(t *testing.T) {
+	b := newCompactTestBlock(t, 4)
+	cb := payload.NewCompactBlock(b, 7)
+
+	mp := mempool.NewMemPool(10)
+	require.NoError(t, mp.Add(b.Transactions[1], &feerStub{}))
+	require.NoError(t, mp.Add(b.Transactions[3], &feerStub{}))
+
+	st, err := newCompactBlockState(cb, &mp)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{2, 4}, st.missing)
+	_, err = st.block()
+	require.Error(t, err)
+
+	require.Error(t, st.fill([]*transaction.Transaction{b.Transactions[2]}))
+	require.Error(t, st.fill([]*transaction.Transaction{b.Transactions[4], b.Transactions[2]}))
+	require.NoError(t, st.fill([]*transaction.Transaction{b.Transactions[2], b.Transactions[4]}))
+
+	actual, err := st.block()
+	require.NoError(t, err)
+	require.Equal(t, b.Hash(), actual.Hash())
+	require.Equal(t, len(b.Transactions), len(actual.Transactions))
+	for i := range b.Transactions {
+		require.Equal(t, b.Transactions[i].Hash(), actual.Transactions[i].Hash())
+	}
+}
+
+func TestCompactBlockInvalid(t *testing.T) {
+	b := newCompactTestBlock(t, 1)
+	cb := payload.NewCompactBlock(b, 7)
+	cb.Prefilled = append(cb.Prefilled, cb.Prefilled[0])
+	_, err := newCompactBlockState(cb, nil)
+	require.Error(t, err)
+
+	cb = payload.NewCompactBlock(b, 7)
+	cb.Prefilled[0].Index = 5
+	_, err = newCompactBlockState(cb, nil)
+	require.Error(t, err)
+}
+
+// compactTestChain keeps header hashes for compact block handler tests,
+// headers are only checked to be linked to the previous one.
+type compactTestChain struct {
+	testChain
+	headers []util.Uint256
+	mp      mempool.Pool
+}
+
+func (chain *compactTestChain) AddHeaders(hs ...*block.Header) error {
+	for _, h := range hs {
+		if int(h.Index) != len(chain.headers) || h.PrevHash != chain.headers[len(chain.headers)-1] {
+			return errors.New("invalid header")
+		}
+		chain.headers = append(chain.headers, h.Hash())
+	}
+	return nil
+}
+func (chain *compactTestChain) HeaderHeight() uint32 {
+	return uint32(len(chain.headers) - 1)
+}
+func (chain *compactTestChain) GetHeaderHash(i int) util.Uint256 {
+	if i >= len(chain.headers) {
+		return util.Uint256{}
+	}
+	return chain.headers[i]
+}
+func (chain *compactTestChain) GetMemPool() *mempool.Pool {
+	return &chain.mp
+}
+
+func newCompactTestServer(t *testing.T) (*Server, *compactTestChain) {
+	chain := &compactTestChain{
+		headers: []util.Uint256{{}},
+		mp:      mempool.NewMemPool(10),
+	}
+	s := newTestServer(t)
+	s.chain = chain
+	s.compactBlocks = make(map[util.Uint256]*compactBlockState)
+	s.bQueue = newBlockQueue(10, chain, s.log, nil)
+	return s, chain
+}
+
+func TestHandleCompactBlock(t *testing.T) {
+	s, chain := newCompactTestServer(t)
+	b := newCompactTestBlock(t, 2)
+	cb := payload.NewCompactBlock(b, 7)
+
+	var (
+		requests      []*payload.GetBlockTxn
+		blockRequests []util.Uint256
+	)
+	p1 := newLocalPeer(t, s)
+	p1.messageHandler = func(t *testing.T, msg *Message) {
+		switch msg.CommandType() {
+		case CMDGetBlockTxn:
+			requests = append(requests, msg.Payload.(*payload.GetBlockTxn))
+		case CMDGetData:
+			inv := msg.Payload.(*payload.Inventory)
+			require.Equal(t, payload.BlockType, inv.Type)
+			blockRequests = append(blockRequests, inv.Hashes...)
+		default:
+			t.Fatalf("unexpected message %s", msg.CommandType())
+		}
+	}
+	p2 := newLocalPeer(t, s)
+	p2.messageHandler = p1.messageHandler
+
+	t.Run("future block", func(t *testing.T) {
+		future := newCompactTestBlock(t, 2)
+		future.Index = 5
+		require.NoError(t, s.handleCompactBlockCmd(p1, payload.NewCompactBlock(future, 7)))
+		require.Empty(t, requests)
+		require.Equal(t, []util.Uint256{future.Hash()}, blockRequests)
+		require.Empty(t, s.compactBlocks)
+		require.Equal(t, uint32(0), chain.HeaderHeight())
+		blockRequests = nil
+	})
+	t.Run("invalid header", func(t *testing.T) {
+		bad := newCompactTestBlock(t, 2)
+		bad.PrevHash = util.Uint256{1}
+		require.Error(t, s.handleCompactBlockCmd(p1, payload.NewCompactBlock(bad, 7)))
+		require.Empty(t, requests)
+		require.Empty(t, s.compactBlocks)
+	})
+
+	require.NoError(t, s.handleCompactBlockCmd(p1, cb))
+	require.Equal(t, 1, len(requests))
+	require.Equal(t, b.Hash(), requests[0].BlockHash)
+	require.Equal(t, []uint16{1, 2}, requests[0].Indexes)
+	require.Equal(t, b.Hash(), chain.GetHeaderHash(1))
+
+	t.Run("already pending", func(t *testing.T) {
+		require.NoError(t, s.handleCompactBlockCmd(p2, cb))
+		require.Equal(t, 1, len(requests))
+		require.Empty(t, blockRequests)
+	})
+	t.Run("header mismatch", func(t *testing.T) {
+		other := newCompactTestBlock(t, 3)
+		require.Error(t, s.handleCompactBlockCmd(p2, payload.NewCompactBlock(other, 7)))
+		require.Equal(t, 1, len(s.compactBlocks))
+	})
+
+	resp := &payload.BlockTxn{
+		BlockHash:    b.Hash(),
+		Transactions: b.Transactions[1:],
+	}
+	t.Run("response from another peer", func(t *testing.T) {
+		require.NoError(t, s.handleBlockTxnCmd(p2, resp))
+		require.Equal(t, 1, len(s.compactBlocks))
+		require.Equal(t, 0, s.bQueue.length())
+	})
+	require.NoError(t, s.handleBlockTxnCmd(p1, resp))
+	require.Empty(t, s.compactBlocks)
+	require.Equal(t, 1, s.bQueue.length())
+	require.Empty(t, blockRequests)
+}
+
+func TestHandleCompactBlockFallback(t *testing.T) {
+	s, _ := newCompactTestServer(t)
+	b := newCompactTestBlock(t, 2)
+
+	var blockRequests []util.Uint256
+	p := newLocalPeer(t, s)
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		if msg.CommandType() == CMDGetData {
+			blockRequests = append(blockRequests, msg.Payload.(*payload.Inventory).Hashes...)
+		}
+	}
+	other := newLocalPeer(t, s)
+	other.messageHandler = p.messageHandler
+
+	t.Run("limit exceeded", func(t *testing.T) {
+		for i := 0; i < maxPeerCompactBlocks; i++ {
+			s.compactBlocks[util.Uint256{byte(i + 1)}] = &compactBlockState{
+				cb:      &payload.CompactBlock{Base: block.Base{Index: 1}},
+				peer:    p,
+				expires: time.Now().Add(time.Minute),
+			}
+		}
+		require.NoError(t, s.handleCompactBlockCmd(p, payload.NewCompactBlock(b, 7)))
+		require.Equal(t, []util.Uint256{b.Hash()}, blockRequests)
+		require.Equal(t, maxPeerCompactBlocks, len(s.compactBlocks))
+	})
+
+	blockRequests = nil
+	s.compactBlocks = make(map[util.Uint256]*compactBlockState)
+	t.Run("expired", func(t *testing.T) {
+		cb := &payload.CompactBlock{Base: block.Base{Index: 1}}
+		h := cb.Hash()
+		s.compactBlocks[h] = &compactBlockState{
+			cb:      cb,
+			peer:    other,
+			expires: time.Now().Add(-time.Second),
+		}
+		s.expireCompactBlocks()
+		require.Equal(t, []util.Uint256{h}, blockRequests)
+		require.Empty(t, s.compactBlocks)
+	})
+}
+
+func TestCanAddCompactBlock(t *testing.T) {
+	s, _ := newCompactTestServer(t)
+	p1 := newLocalPeer(t, s)
+	p2 := newLocalPeer(t, s)
+	add := func(p Peer, index uint32, expires time.Time) util.Uint256 {
+		h := util.Uint256{byte(len(s.compactBlocks) + 1)}
+		s.compactBlocks[h] = &compactBlockState{
+			cb:      &payload.CompactBlock{Base: block.Base{Index: index}},
+			peer:    p,
+			expires: expires,
+		}
+		return h
+	}
+	future := time.Now().Add(time.Minute)
+
+	check := func(p Peer, h util.Uint256, pending, ok bool) {
+		actualPending, actualOk := s.canAddCompactBlock(p, h)
+		require.Equal(t, pending, actualPending)
+		require.Equal(t, ok, actualOk)
+	}
+
+	h := add(p1, 1, future)
+	check(p2, h, true, false)
+	for i := 1; i < maxPeerCompactBlocks; i++ {
+		add(p1, 1, future)
+	}
+	check(p1, util.Uint256{0xff}, false, false)
+	check(p2, util.Uint256{0xff}, false, true)
+
+	for len(s.compactBlocks) < maxPendingCompactBlocks {
+		add(newLocalPeer(t, s), 1, future)
+	}
+	check(p2, util.Uint256{0xff}, false, false)
+
+	// Stale blocks are removed, expired ones are returned.
+	s.compactBlocks = make(map[util.Uint256]*compactBlockState)
+	add(p1, 0, future)
+	h = add(p1, 1, time.Now().Add(-time.Second))
+	expired := s.pruneCompactBlocks()
+	require.Equal(t, 1, len(expired))
+	require.Equal(t, uint32(1), expired[0].cb.Index)
+	require.Empty(t, s.compactBlocks)
+	check(p1, h, false, true)
+}
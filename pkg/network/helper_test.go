@@ -96,6 +96,9 @@ func (chain testChain) GetNEP5TransferLog(util.Uint160) *state.NEP5TransferLog {
 func (chain testChain) GetNEP5Balances(util.Uint160) *state.NEP5Balances {
 	panic("TODO")
 }
+func (chain testChain) GetStateRoot(uint32) (util.Uint256, error) {
+	panic("TODO")
+}
 func (chain testChain) GetValidators(...*transaction.Transaction) ([]*keys.PublicKey, error) {
 	panic("TODO")
 }
@@ -108,7 +111,7 @@ func (chain testChain) GetScriptHashesForVerifying(*transaction.Transaction) ([]
 func (chain testChain) GetStorageItem(scripthash util.Uint160, key []byte) *state.StorageItem {
 	panic("TODO")
 }
-func (chain testChain) GetTestVM() *vm.VM {
+func (chain testChain) GetTestVM(*transaction.Transaction, *state.Effects) *vm.VM {
 	panic("TODO")
 }
 func (chain testChain) GetStorageItems(hash util.Uint160) (map[string]*state.StorageItem, error) {
@@ -142,6 +145,10 @@ func (chain testChain) IsLowPriority(util.Fixed8) bool {
 	panic("TODO")
 }
 
+func (chain testChain) MinNetworkFee(int) util.Fixed8 {
+	panic("TODO")
+}
+
 func (chain testChain) PoolTx(*transaction.Transaction) error {
 	panic("TODO")
 }
@@ -51,12 +51,15 @@ type CommandType string
 const (
 	CMDAddr        CommandType = "addr"
 	CMDBlock       CommandType = "block"
+	CMDBlockTxn    CommandType = "blocktxn"
+	CMDCmpctBlock  CommandType = "cmpctblock"
 	CMDConsensus   CommandType = "consensus"
 	CMDFilterAdd   CommandType = "filteradd"
 	CMDFilterClear CommandType = "filterclear"
 	CMDFilterLoad  CommandType = "filterload"
 	CMDGetAddr     CommandType = "getaddr"
 	CMDGetBlocks   CommandType = "getblocks"
+	CMDGetBlockTxn CommandType = "getblocktxn"
 	CMDGetData     CommandType = "getdata"
 	CMDGetHeaders  CommandType = "getheaders"
 	CMDHeaders     CommandType = "headers"
@@ -108,6 +111,10 @@ func (m *Message) CommandType() CommandType {
 		return CMDAddr
 	case "block":
 		return CMDBlock
+	case "blocktxn":
+		return CMDBlockTxn
+	case "cmpctblock":
+		return CMDCmpctBlock
 	case "consensus":
 		return CMDConsensus
 	case "filteradd":
@@ -120,6 +127,8 @@ func (m *Message) CommandType() CommandType {
 		return CMDGetAddr
 	case "getblocks":
 		return CMDGetBlocks
+	case "getblocktxn":
+		return CMDGetBlockTxn
 	case "getdata":
 		return CMDGetData
 	case "getheaders":
@@ -185,6 +194,12 @@ func (m *Message) decodePayload(br *io.BinReader) error {
 		p = &payload.AddressList{}
 	case CMDBlock:
 		p = &block.Block{}
+	case CMDCmpctBlock:
+		p = &payload.CompactBlock{}
+	case CMDGetBlockTxn:
+		p = &payload.GetBlockTxn{}
+	case CMDBlockTxn:
+		p = &payload.BlockTxn{}
 	case CMDConsensus:
 		p = &consensus.Payload{}
 	case CMDGetBlocks:
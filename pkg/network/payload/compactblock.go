@@ -0,0 +1,195 @@
+package payload
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// ShortIDSize is the size of short transaction ID used in compact blocks.
+const ShortIDSize = 6
+
+// MaxCompactBlockTxs is the maximum number of transactions that can be
+// described by a single compact block.
+const MaxCompactBlockTxs = 0xffff
+
+// ErrTooManyTransactions is returned when the compact block or its
+// transaction request describe more transactions than allowed.
+var ErrTooManyTransactions = errors.New("too many transactions")
+
+// PrefilledTx is a transaction sent as a part of the compact block along
+// with its index in the block.
+type PrefilledTx struct {
+	Index uint16
+	Tx    *transaction.Transaction
+}
+
+// CompactBlock represents a block header along with short IDs of its
+// transactions, the receiver is expected to reconstruct the block using
+// transactions from its memory pool.
+type CompactBlock struct {
+	block.Base
+	// Nonce is used to salt short transaction IDs.
+	Nonce uint64
+	// ShortIDs are short IDs of transactions that are not prefilled.
+	ShortIDs []uint64
+	// Prefilled are transactions that the receiver is unlikely to have,
+	// like the miner transaction.
+	Prefilled []PrefilledTx
+}
+
+// GetBlockTxn is a request for the transactions of the compact block
+// that the node was not able to find in its memory pool.
+type GetBlockTxn struct {
+	BlockHash util.Uint256
+	Indexes   []uint16
+}
+
+// BlockTxn is a response to GetBlockTxn.
+type BlockTxn struct {
+	BlockHash    util.Uint256
+	Transactions []*transaction.Transaction
+}
+
+// NewCompactBlock creates compact block from the given block using nonce
+// to salt short transaction IDs. Miner transaction is always prefilled.
+func NewCompactBlock(b *block.Block, nonce uint64) *CompactBlock {
+	c := &CompactBlock{
+		Base:  b.Base,
+		Nonce: nonce,
+	}
+	key := c.shortIDKey()
+	for i, tx := range b.Transactions {
+		if tx.Type == transaction.MinerType {
+			c.Prefilled = append(c.Prefilled, PrefilledTx{Index: uint16(i), Tx: tx})
+			continue
+		}
+		c.ShortIDs = append(c.ShortIDs, shortID(key, tx.Hash()))
+	}
+	return c
+}
+
+// TxCount returns the number of transactions in the block.
+func (c *CompactBlock) TxCount() int {
+	return len(c.ShortIDs) + len(c.Prefilled)
+}
+
+// ShortIDFunc returns a function calculating short transaction IDs for this
+// compact block.
+func (c *CompactBlock) ShortIDFunc() func(util.Uint256) uint64 {
+	key := c.shortIDKey()
+	return func(h util.Uint256) uint64 {
+		return shortID(key, h)
+	}
+}
+
+func (c *CompactBlock) shortIDKey() []byte {
+	buf := make([]byte, util.Uint256Size+8)
+	h := c.Hash()
+	copy(buf, h.BytesBE())
+	binary.LittleEndian.PutUint64(buf[util.Uint256Size:], c.Nonce)
+	return buf
+}
+
+func shortID(key []byte, h util.Uint256) uint64 {
+	buf := make([]byte, 0, len(key)+util.Uint256Size)
+	buf = append(buf, key...)
+	buf = append(buf, h.BytesBE()...)
+	sum := hash.Sha256(buf)
+	var id [8]byte
+	copy(id[:], sum[:ShortIDSize])
+	return binary.LittleEndian.Uint64(id[:])
+}
+
+// DecodeBinary implements Serializable interface.
+func (c *CompactBlock) DecodeBinary(br *io.BinReader) {
+	c.Base.DecodeBinary(br)
+	c.Nonce = br.ReadU64LE()
+	n := br.ReadVarUint()
+	if n > MaxCompactBlockTxs {
+		br.Err = ErrTooManyTransactions
+		return
+	}
+	c.ShortIDs = make([]uint64, n)
+	var id [8]byte
+	for i := range c.ShortIDs {
+		br.ReadBytes(id[:ShortIDSize])
+		c.ShortIDs[i] = binary.LittleEndian.Uint64(id[:])
+	}
+	n = br.ReadVarUint()
+	if n > MaxCompactBlockTxs-uint64(len(c.ShortIDs)) {
+		br.Err = ErrTooManyTransactions
+		return
+	}
+	c.Prefilled = make([]PrefilledTx, n)
+	for i := range c.Prefilled {
+		c.Prefilled[i].Index = br.ReadU16LE()
+		c.Prefilled[i].Tx = new(transaction.Transaction)
+		c.Prefilled[i].Tx.DecodeBinary(br)
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (c *CompactBlock) EncodeBinary(bw *io.BinWriter) {
+	c.Base.EncodeBinary(bw)
+	bw.WriteU64LE(c.Nonce)
+	bw.WriteVarUint(uint64(len(c.ShortIDs)))
+	var id [8]byte
+	for _, sid := range c.ShortIDs {
+		binary.LittleEndian.PutUint64(id[:], sid)
+		bw.WriteBytes(id[:ShortIDSize])
+	}
+	bw.WriteVarUint(uint64(len(c.Prefilled)))
+	for _, p := range c.Prefilled {
+		bw.WriteU16LE(p.Index)
+		p.Tx.EncodeBinary(bw)
+	}
+}
+
+// NewGetBlockTxn returns a pointer to a GetBlockTxn object.
+func NewGetBlockTxn(h util.Uint256, indexes []uint16) *GetBlockTxn {
+	return &GetBlockTxn{
+		BlockHash: h,
+		Indexes:   indexes,
+	}
+}
+
+// DecodeBinary implements Serializable interface.
+func (g *GetBlockTxn) DecodeBinary(br *io.BinReader) {
+	g.BlockHash.DecodeBinary(br)
+	n := br.ReadVarUint()
+	if n > MaxCompactBlockTxs {
+		br.Err = ErrTooManyTransactions
+		return
+	}
+	g.Indexes = make([]uint16, n)
+	for i := range g.Indexes {
+		g.Indexes[i] = br.ReadU16LE()
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (g *GetBlockTxn) EncodeBinary(bw *io.BinWriter) {
+	g.BlockHash.EncodeBinary(bw)
+	bw.WriteVarUint(uint64(len(g.Indexes)))
+	for _, i := range g.Indexes {
+		bw.WriteU16LE(i)
+	}
+}
+
+// DecodeBinary implements Serializable interface.
+func (b *BlockTxn) DecodeBinary(br *io.BinReader) {
+	b.BlockHash.DecodeBinary(br)
+	br.ReadArray(&b.Transactions, MaxCompactBlockTxs)
+}
+
+// EncodeBinary implements Serializable interface.
+func (b *BlockTxn) EncodeBinary(bw *io.BinWriter) {
+	b.BlockHash.EncodeBinary(bw)
+	bw.WriteArray(b.Transactions)
+}
@@ -0,0 +1,81 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/internal/testserdes"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlock(n int) *block.Block {
+	b := &block.Block{
+		Base: block.Base{
+			Index: 1,
+			Script: transaction.Witness{
+				InvocationScript:   []byte{0x0},
+				VerificationScript: []byte{0x1},
+			},
+		},
+		Transactions: []*transaction.Transaction{{
+			Type: transaction.MinerType,
+			Data: &transaction.MinerTX{Nonce: 42},
+		}},
+	}
+	for i := 0; i < n; i++ {
+		b.Transactions = append(b.Transactions, transaction.NewInvocationTX([]byte{byte(i)}, 0))
+	}
+	return b
+}
+
+func TestCompactBlock(t *testing.T) {
+	b := newTestBlock(3)
+	cb := NewCompactBlock(b, 0x0102030405060708)
+	require.Equal(t, 4, cb.TxCount())
+	require.Equal(t, 1, len(cb.Prefilled))
+	require.Equal(t, uint16(0), cb.Prefilled[0].Index)
+	require.Equal(t, 3, len(cb.ShortIDs))
+
+	shortID := cb.ShortIDFunc()
+	for i, tx := range b.Transactions[1:] {
+		require.Equal(t, shortID(tx.Hash()), cb.ShortIDs[i])
+		require.True(t, cb.ShortIDs[i] < 1<<(8*ShortIDSize))
+	}
+
+	other := NewCompactBlock(b, 1)
+	require.NotEqual(t, cb.ShortIDs, other.ShortIDs)
+
+	data, err := testserdes.EncodeBinary(cb)
+	require.NoError(t, err)
+	actual := new(CompactBlock)
+	require.NoError(t, testserdes.DecodeBinary(data, actual))
+	require.Equal(t, cb.Hash(), actual.Hash())
+	require.Equal(t, cb.Nonce, actual.Nonce)
+	require.Equal(t, cb.ShortIDs, actual.ShortIDs)
+	require.Equal(t, len(cb.Prefilled), len(actual.Prefilled))
+	require.Equal(t, cb.Prefilled[0].Tx.Hash(), actual.Prefilled[0].Tx.Hash())
+}
+
+func TestGetBlockTxnEncodeDecode(t *testing.T) {
+	g := NewGetBlockTxn(util.Uint256{1, 2, 3}, []uint16{1, 5, 7})
+	testserdes.EncodeDecodeBinary(t, g, new(GetBlockTxn))
+}
+
+func TestBlockTxnEncodeDecode(t *testing.T) {
+	b := newTestBlock(2)
+	bt := &BlockTxn{
+		BlockHash:    b.Hash(),
+		Transactions: b.Transactions[1:],
+	}
+	data, err := testserdes.EncodeBinary(bt)
+	require.NoError(t, err)
+	actual := new(BlockTxn)
+	require.NoError(t, testserdes.DecodeBinary(data, actual))
+	require.Equal(t, bt.BlockHash, actual.BlockHash)
+	require.Equal(t, 2, len(actual.Transactions))
+	for i := range bt.Transactions {
+		require.Equal(t, bt.Transactions[i].Hash(), actual.Transactions[i].Hash())
+	}
+}
@@ -17,6 +17,9 @@ const (
 	// PrunedNode        uint64 = 3 // Not implemented
 	// LightNode         uint64 = 4 // Not implemented
 
+	// CompactBlockService is set by nodes that are able to process compact
+	// blocks relayed via cmpctblock message.
+	CompactBlockService uint64 = 1 << 8
 )
 
 // Version payload.
@@ -53,6 +56,11 @@ func NewVersion(id uint32, p uint16, ua string, h uint32, r bool) *Version {
 	}
 }
 
+// HasService returns true if the node offers given service.
+func (p *Version) HasService(service uint64) bool {
+	return p.Services&service != 0
+}
+
 // DecodeBinary implements Serializable interface.
 func (p *Version) DecodeBinary(br *io.BinReader) {
 	p.Version = br.ReadU32LE()
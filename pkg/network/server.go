@@ -66,6 +66,12 @@ type (
 
 		transactions chan *transaction.Transaction
 
+		// compactLock protects compactBlocks.
+		compactLock sync.Mutex
+		// compactBlocks are compact blocks waiting for the missing
+		// transactions to be received.
+		compactBlocks map[util.Uint256]*compactBlockState
+
 		consensusStarted *atomic.Bool
 
 		log *zap.Logger
@@ -100,6 +106,7 @@ func NewServer(config ServerConfig, chain core.Blockchainer, log *zap.Logger) (*
 		consensusStarted: atomic.NewBool(false),
 		log:              log,
 		transactions:     make(chan *transaction.Transaction, 64),
+		compactBlocks:    make(map[util.Uint256]*compactBlockState),
 	}
 	s.bQueue = newBlockQueue(maxBlockBatch, chain, log, func(b *block.Block) {
 		if s.consensusStarted.Load() {
@@ -117,6 +124,10 @@ func NewServer(config ServerConfig, chain core.Blockchainer, log *zap.Logger) (*
 		Chain:      chain,
 		RequestTx:  s.requestTx,
 		Wallet:     config.Wallet,
+		WatchOnly:  config.ConsensusWatchOnly,
+
+		RemoteSigner: config.ConsensusSigner,
+		JournalPath:  config.ConsensusJournal,
 
 		TimePerBlock: config.TimePerBlock,
 	})
@@ -192,6 +203,9 @@ func (s *Server) Shutdown() {
 	for p := range s.peers {
 		p.Disconnect(errServerShutdown)
 	}
+	if s.consensus != nil {
+		s.consensus.Shutdown()
+	}
 	s.bQueue.discard()
 	close(s.quit)
 }
@@ -282,6 +296,8 @@ func (s *Server) run() {
 // runProto is a goroutine that manages server-wide protocol events.
 func (s *Server) runProto() {
 	pingTimer := time.NewTimer(s.PingInterval)
+	compactTicker := time.NewTicker(compactBlockTimeout)
+	defer compactTicker.Stop()
 	for {
 		prevHeight := s.chain.BlockHeight()
 		select {
@@ -295,12 +311,14 @@ func (s *Server) runProto() {
 				}
 			}
 			pingTimer.Reset(s.PingInterval)
+		case <-compactTicker.C:
+			s.expireCompactBlocks()
 		}
 	}
 }
 
 func (s *Server) tryStartConsensus() {
-	if s.Wallet == nil || s.consensusStarted.Load() {
+	if (s.Wallet == nil && s.ConsensusSigner == "" && !s.ConsensusWatchOnly) || s.consensusStarted.Load() {
 		return
 	}
 
@@ -312,6 +330,25 @@ func (s *Server) tryStartConsensus() {
 	}
 }
 
+// ConsensusState returns consensus state snapshot for the current height,
+// it returns nil if consensus is not running.
+func (s *Server) ConsensusState() *consensus.State {
+	if !s.consensusStarted.Load() {
+		return nil
+	}
+	return s.consensus.State()
+}
+
+// ConsensusStateAt returns consensus state snapshot for the given recent
+// height, it returns nil if consensus is not running or there is no state
+// for this height.
+func (s *Server) ConsensusStateAt(height uint32) *consensus.State {
+	if !s.consensusStarted.Load() {
+		return nil
+	}
+	return s.consensus.StateAt(height)
+}
+
 // Peers returns the current list of peers connected to
 // the server.
 func (s *Server) Peers() map[Peer]bool {
@@ -359,6 +396,9 @@ func (s *Server) getVersionMsg() *Message {
 		s.chain.BlockHeight(),
 		s.Relay,
 	)
+	if s.CompactBlocks {
+		payload.Services |= payload.CompactBlockService
+	}
 	return s.MkMsg(CMDVersion, payload)
 }
 
@@ -445,6 +485,199 @@ func (s *Server) handleBlockCmd(p Peer, block *block.Block) error {
 	return s.bQueue.putBlock(block)
 }
 
+// handleCompactBlockCmd processes the compact block received from its peer.
+// Only the next block is reconstructed, its header is verified before the
+// block is rebuilt using memory pool transactions, missing ones are requested
+// from the peer. Compact blocks that can't be reconstructed (future ones or
+// ones exceeding pending limits) are treated like inventory announcements and
+// full blocks are requested for them.
+func (s *Server) handleCompactBlockCmd(p Peer, cb *payload.CompactBlock) error {
+	h := cb.Hash()
+	height := s.chain.BlockHeight()
+	if cb.Index <= height || s.chain.HasBlock(h) {
+		return nil
+	}
+	if cb.Index != height+1 {
+		// Block queue will keep it until the previous ones arrive.
+		return s.requestBlock(p, h)
+	}
+	s.compactLock.Lock()
+	expired := s.pruneCompactBlocks()
+	pending, ok := s.canAddCompactBlock(p, h)
+	s.compactLock.Unlock()
+	s.requestExpiredBlocks(expired)
+	if pending {
+		return nil
+	}
+	if !ok {
+		return s.requestBlock(p, h)
+	}
+	if err := s.verifyCompactBlockHeader(cb); err != nil {
+		return err
+	}
+	st, err := newCompactBlockState(cb, s.chain.GetMemPool())
+	if err != nil {
+		return err
+	}
+	if len(st.missing) == 0 {
+		return s.putCompactBlock(p, st)
+	}
+	st.peer = p
+	st.expires = time.Now().Add(compactBlockTimeout)
+
+	s.compactLock.Lock()
+	expired = s.pruneCompactBlocks()
+	pending, ok = s.canAddCompactBlock(p, h)
+	if ok {
+		s.compactBlocks[h] = st
+	}
+	s.compactLock.Unlock()
+	s.requestExpiredBlocks(expired)
+	if pending {
+		return nil
+	}
+	if !ok {
+		return s.requestBlock(p, h)
+	}
+	return p.EnqueueP2PMessage(s.MkMsg(CMDGetBlockTxn, payload.NewGetBlockTxn(h, st.missing)))
+}
+
+// canAddCompactBlock checks whether the block with the given hash from the
+// peer can be added to the list of blocks waiting for transactions. The first
+// value returned is true if this block is already pending. It must be called
+// with compactLock held.
+func (s *Server) canAddCompactBlock(p Peer, h util.Uint256) (bool, bool) {
+	if _, ok := s.compactBlocks[h]; ok {
+		return true, false
+	}
+	var fromPeer int
+	for _, pending := range s.compactBlocks {
+		if pending.peer == p {
+			fromPeer++
+		}
+	}
+	return false, len(s.compactBlocks) < maxPendingCompactBlocks && fromPeer < maxPeerCompactBlocks
+}
+
+// pruneCompactBlocks removes compact blocks that are already in the chain and
+// the ones whose transactions weren't received in time, the latter are
+// returned. It must be called with compactLock held.
+func (s *Server) pruneCompactBlocks() []*compactBlockState {
+	var (
+		height  = s.chain.BlockHeight()
+		now     = time.Now()
+		expired []*compactBlockState
+	)
+	for hash, pending := range s.compactBlocks {
+		if pending.cb.Index <= height {
+			delete(s.compactBlocks, hash)
+		} else if now.After(pending.expires) {
+			delete(s.compactBlocks, hash)
+			expired = append(expired, pending)
+		}
+	}
+	return expired
+}
+
+// requestExpiredBlocks requests full blocks for expired compact blocks from
+// the peers that announced them.
+func (s *Server) requestExpiredBlocks(expired []*compactBlockState) {
+	for _, st := range expired {
+		h := st.cb.Hash()
+		if err := s.requestBlock(st.peer, h); err != nil {
+			s.log.Debug("failed to request expired compact block",
+				zap.Stringer("hash", h),
+				zap.Error(err))
+		}
+	}
+}
+
+// expireCompactBlocks requests full blocks for expired compact blocks.
+func (s *Server) expireCompactBlocks() {
+	s.compactLock.Lock()
+	expired := s.pruneCompactBlocks()
+	s.compactLock.Unlock()
+	s.requestExpiredBlocks(expired)
+}
+
+// verifyCompactBlockHeader checks compact block header against the chain.
+// Unknown headers are verified by adding them to the chain, known ones must
+// match the header stored.
+func (s *Server) verifyCompactBlockHeader(cb *payload.CompactBlock) error {
+	if s.chain.HeaderHeight() < cb.Index {
+		if err := s.chain.AddHeaders(&block.Header{Base: cb.Base}); err != nil {
+			return fmt.Errorf("invalid compact block header: %v", err)
+		}
+	}
+	if s.chain.GetHeaderHash(int(cb.Index)) != cb.Hash() {
+		return errors.New("compact block header doesn't match the chain")
+	}
+	return nil
+}
+
+// handleGetBlockTxnCmd processes the request for compact block transactions.
+func (s *Server) handleGetBlockTxnCmd(p Peer, req *payload.GetBlockTxn) error {
+	b, err := s.chain.GetBlock(req.BlockHash)
+	if err != nil {
+		// We may not have it yet or not have it at all, the peer
+		// requests the full block when its compact block expires.
+		return nil
+	}
+	resp := &payload.BlockTxn{
+		BlockHash:    req.BlockHash,
+		Transactions: make([]*transaction.Transaction, len(req.Indexes)),
+	}
+	for i, idx := range req.Indexes {
+		if int(idx) >= len(b.Transactions) {
+			return fmt.Errorf("invalid transaction index %d for block %s", idx, req.BlockHash.StringLE())
+		}
+		resp.Transactions[i] = b.Transactions[idx]
+	}
+	return p.EnqueueP2PMessage(s.MkMsg(CMDBlockTxn, resp))
+}
+
+// handleBlockTxnCmd processes the transactions requested for the compact
+// block, only the response from the peer they were requested from is
+// accepted.
+func (s *Server) handleBlockTxnCmd(p Peer, resp *payload.BlockTxn) error {
+	s.compactLock.Lock()
+	st := s.compactBlocks[resp.BlockHash]
+	if st == nil || st.peer != p {
+		// Not requested or requested from another peer.
+		s.compactLock.Unlock()
+		return nil
+	}
+	delete(s.compactBlocks, resp.BlockHash)
+	s.compactLock.Unlock()
+	if err := st.fill(resp.Transactions); err != nil {
+		s.log.Warn("bad compact block transactions",
+			zap.Stringer("hash", resp.BlockHash),
+			zap.Error(err))
+		return s.requestBlock(p, resp.BlockHash)
+	}
+	return s.putCompactBlock(p, st)
+}
+
+// putCompactBlock puts reconstructed block into the block queue. If the
+// reconstruction failed (which can happen because of short ID collisions)
+// full block is requested from the peer.
+func (s *Server) putCompactBlock(p Peer, st *compactBlockState) error {
+	b, err := st.block()
+	if err != nil {
+		h := st.cb.Hash()
+		s.log.Debug("compact block reconstruction failed",
+			zap.Stringer("hash", h),
+			zap.Error(err))
+		return s.requestBlock(p, h)
+	}
+	return s.bQueue.putBlock(b)
+}
+
+// requestBlock sends a getdata message for a single block to the peer.
+func (s *Server) requestBlock(p Peer, h util.Uint256) error {
+	return p.EnqueueP2PMessage(s.MkMsg(CMDGetData, payload.NewInventory(payload.BlockType, []util.Uint256{h})))
+}
+
 // handlePing processes ping request.
 func (s *Server) handlePing(p Peer, ping *payload.Ping) error {
 	return p.EnqueueP2PMessage(s.MkMsg(CMDPong, payload.NewPing(s.chain.BlockHeight(), s.id)))
@@ -709,6 +942,15 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 		case CMDBlock:
 			block := msg.Payload.(*block.Block)
 			return s.handleBlockCmd(peer, block)
+		case CMDCmpctBlock:
+			cb := msg.Payload.(*payload.CompactBlock)
+			return s.handleCompactBlockCmd(peer, cb)
+		case CMDGetBlockTxn:
+			req := msg.Payload.(*payload.GetBlockTxn)
+			return s.handleGetBlockTxnCmd(peer, req)
+		case CMDBlockTxn:
+			resp := msg.Payload.(*payload.BlockTxn)
+			return s.handleBlockTxnCmd(peer, resp)
 		case CMDConsensus:
 			cp := msg.Payload.(*consensus.Payload)
 			return s.handleConsensusCmd(cp)
@@ -790,14 +1032,30 @@ func (s *Server) broadcastHPMessage(msg *Message) {
 	s.iteratePeersWithSendMsg(msg, Peer.EnqueueHPPacket, nil)
 }
 
-// relayBlock tells all the other connected nodes about the given block.
+// relayBlock tells all the other connected nodes about the given block. If
+// compact blocks are enabled peers supporting them get the compact block
+// right away instead of an inventory message.
 func (s *Server) relayBlock(b *block.Block) {
-	msg := s.MkMsg(CMDInv, payload.NewInventory(payload.BlockType, []util.Uint256{b.Hash()}))
 	// Filter out nodes that are more current (avoid spamming the network
 	// during initial sync).
-	s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
+	needsBlock := func(p Peer) bool {
 		return p.Handshaked() && p.LastBlockIndex() < b.Index
+	}
+	supportsCompact := func(p Peer) bool {
+		return s.CompactBlocks && p.Version().HasService(payload.CompactBlockService)
+	}
+
+	msg := s.MkMsg(CMDInv, payload.NewInventory(payload.BlockType, []util.Uint256{b.Hash()}))
+	s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
+		return needsBlock(p) && !supportsCompact(p)
 	})
+	if s.CompactBlocks {
+		nonce := uint64(randomID())<<32 | uint64(randomID())
+		msg = s.MkMsg(CMDCmpctBlock, payload.NewCompactBlock(b, nonce))
+		s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
+			return needsBlock(p) && supportsCompact(p)
+		})
+	}
 }
 
 // verifyAndPoolTX verifies the TX and adds it to the local mempool.
@@ -43,6 +43,10 @@ type (
 		// Relay determines whether the server is forwarding its inventory.
 		Relay bool
 
+		// CompactBlocks enables compact block relay to the peers
+		// supporting it.
+		CompactBlocks bool
+
 		// Seeds are a list of initial nodes used to establish connectivity.
 		Seeds []string
 
@@ -64,6 +68,18 @@ type (
 		// Wallet is a wallet configuration.
 		Wallet *wallet.Config
 
+		// ConsensusWatchOnly makes the node run dBFT in watch-only mode,
+		// tracking consensus state without taking part in it.
+		ConsensusWatchOnly bool
+
+		// ConsensusJournal is a path to the consensus journal file,
+		// journal is disabled if it's empty.
+		ConsensusJournal string
+
+		// ConsensusSigner is an address of the remote signer holding
+		// validator keys, it's used instead of Wallet if set.
+		ConsensusSigner string
+
 		// TimePerBlock is an interval which should pass between two successive blocks.
 		TimePerBlock time.Duration
 	}
@@ -81,20 +97,24 @@ func NewServerConfig(cfg config.Config) ServerConfig {
 	}
 
 	return ServerConfig{
-		UserAgent:         cfg.GenerateUserAgent(),
-		Address:           appConfig.Address,
-		Port:              appConfig.NodePort,
-		Net:               protoConfig.Magic,
-		Relay:             appConfig.Relay,
-		Seeds:             protoConfig.SeedList,
-		DialTimeout:       appConfig.DialTimeout * time.Second,
-		ProtoTickInterval: appConfig.ProtoTickInterval * time.Second,
-		PingInterval:      appConfig.PingInterval * time.Second,
-		PingTimeout:       appConfig.PingTimeout * time.Second,
-		MaxPeers:          appConfig.MaxPeers,
-		AttemptConnPeers:  appConfig.AttemptConnPeers,
-		MinPeers:          appConfig.MinPeers,
-		Wallet:            wc,
-		TimePerBlock:      time.Duration(protoConfig.SecondsPerBlock) * time.Second,
+		UserAgent:          cfg.GenerateUserAgent(),
+		Address:            appConfig.Address,
+		Port:               appConfig.NodePort,
+		Net:                protoConfig.Magic,
+		Relay:              appConfig.Relay,
+		CompactBlocks:      appConfig.CompactBlocks,
+		Seeds:              protoConfig.SeedList,
+		DialTimeout:        appConfig.DialTimeout * time.Second,
+		ProtoTickInterval:  appConfig.ProtoTickInterval * time.Second,
+		PingInterval:       appConfig.PingInterval * time.Second,
+		PingTimeout:        appConfig.PingTimeout * time.Second,
+		MaxPeers:           appConfig.MaxPeers,
+		AttemptConnPeers:   appConfig.AttemptConnPeers,
+		MinPeers:           appConfig.MinPeers,
+		Wallet:             wc,
+		ConsensusWatchOnly: appConfig.ConsensusWatchOnly,
+		ConsensusJournal:   appConfig.ConsensusJournal,
+		ConsensusSigner:    appConfig.ConsensusSigner,
+		TimePerBlock:       time.Duration(protoConfig.SecondsPerBlock) * time.Second,
 	}
 }
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+)
+
+// AssetPayment is a transfer of UTXO asset.
+type AssetPayment struct {
+	To     util.Uint160
+	Asset  util.Uint256
+	Amount util.Fixed8
+}
+
+// TokenPayment is a transfer of NEP5 token, Amount is in FixedN format
+// using token's number of decimals.
+type TokenPayment struct {
+	To     util.Uint160
+	Token  util.Uint160
+	Amount int64
+}
+
+// Batch creates transactions making payments from the account using unspent
+// outputs fetched once, outputs used by one transaction are not used by the
+// others, so all transactions of the batch can be sent at once.
+type Batch struct {
+	acc   *wallet.Account
+	utxos map[util.Uint256]state.UnspentBalances
+	sel   CoinSelector
+}
+
+// NewBatch creates a batch of transactions for acc using client's coin
+// selector for inputs.
+func (c *Client) NewBatch(acc *wallet.Account) (*Batch, error) {
+	utxos, err := c.getUnspentsByAsset(acc.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &Batch{
+		acc:   acc,
+		utxos: utxos,
+		sel:   c.coinSelector(),
+	}, nil
+}
+
+// CreateBatchContractTxs creates the minimal number of contract transactions
+// making all payments from acc, so that every transaction signed by acc fits
+// into maxSize bytes (if it's not 0). Transactions use different unspent
+// outputs, so they can be sent at once. Transactions are not signed.
+func (c *Client) CreateBatchContractTxs(acc *wallet.Account, payments []AssetPayment, maxSize int) ([]*transaction.Transaction, error) {
+	b, err := c.NewBatch(acc)
+	if err != nil {
+		return nil, err
+	}
+	return b.CreateContractTxs(payments, maxSize)
+}
+
+// CreateContractTxs creates the minimal number of contract transactions
+// making all payments, so that every signed transaction fits into maxSize
+// bytes (if it's not 0). Unspent outputs used are not available for the
+// subsequent transactions of the batch. Transactions are not signed.
+func (b *Batch) CreateContractTxs(payments []AssetPayment, maxSize int) ([]*transaction.Transaction, error) {
+	return createBatchContractTxs(b.acc.Contract, payments, b.utxos, b.sel, maxSize)
+}
+
+// CreateContractTx creates a contract transaction making all payments from acc
+// and paying netfee as a network fee. The transaction is not signed.
+func (c *Client) CreateContractTx(acc *wallet.Account, payments []AssetPayment, netfee util.Fixed8) (*transaction.Transaction, error) {
+	utxos, err := c.getUnspentsByAsset(acc.Address)
+	if err != nil {
+		return nil, err
+	}
+	tx, _, err := createPaymentTx(acc.Contract.ScriptHash(), payments, netfee, utxos, c.coinSelector())
+	return tx, err
+}
+
+func (c *Client) getUnspentsByAsset(address string) (map[util.Uint256]state.UnspentBalances, error) {
+	resp, err := c.GetUnspents(address)
+	if err != nil {
+		return nil, err
+	}
+	utxos := make(map[util.Uint256]state.UnspentBalances, len(resp.Balance))
+	for _, ubi := range resp.Balance {
+		utxos[ubi.AssetHash] = ubi.Unspents
+	}
+	return utxos, nil
+}
+
+func (c *Client) coinSelector() CoinSelector {
+	if sel := c.CoinSelector(); sel != nil {
+		return sel
+	}
+	return SelectSmallestFirst
+}
+
+func createBatchContractTxs(ctr *wallet.Contract, payments []AssetPayment, utxos map[util.Uint256]state.UnspentBalances, sel CoinSelector, maxSize int) ([]*transaction.Transaction, error) {
+	var txs []*transaction.Transaction
+	for len(payments) != 0 {
+		var (
+			tx   *transaction.Transaction
+			used map[util.Uint256]state.UnspentBalances
+			n    int
+		)
+		for n < len(payments) {
+			t, u, err := createPaymentTx(ctr.ScriptHash(), payments[:n+1], 0, utxos, sel)
+			if err != nil {
+				return nil, err
+			}
+			if maxSize != 0 && EstimateTxSize(t, ctr) > maxSize {
+				break
+			}
+			tx, used, n = t, u, n+1
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("payment to %s doesn't fit into transaction", address.Uint160ToString(payments[0].To))
+		}
+		for asset := range used {
+			utxos[asset] = subtractUnspents(utxos[asset], used[asset])
+		}
+		txs = append(txs, tx)
+		payments = payments[n:]
+	}
+	return txs, nil
+}
+
+// createPaymentTx creates a transaction with outputs for all payments and
+// change outputs to from, inputs are selected by sel from utxos so that netfee
+// GAS is left for network fee. Selected unspent outputs are returned along
+// with the transaction.
+func createPaymentTx(from util.Uint160, payments []AssetPayment, netfee util.Fixed8, utxos map[util.Uint256]state.UnspentBalances, sel CoinSelector) (*transaction.Transaction, map[util.Uint256]state.UnspentBalances, error) {
+	var (
+		tx      = transaction.NewContractTX()
+		assets  []util.Uint256
+		amounts = make(map[util.Uint256]util.Fixed8)
+		used    = make(map[util.Uint256]state.UnspentBalances)
+	)
+	if netfee > 0 {
+		assets = append(assets, core.UtilityTokenID())
+		amounts[core.UtilityTokenID()] = netfee
+	}
+	for _, p := range payments {
+		if p.Amount <= 0 {
+			return nil, nil, errors.New("payment amount must be positive")
+		}
+		if _, ok := amounts[p.Asset]; !ok {
+			assets = append(assets, p.Asset)
+		}
+		amounts[p.Asset] += p.Amount
+		tx.AddOutput(&transaction.Output{
+			AssetID:    p.Asset,
+			Amount:     p.Amount,
+			ScriptHash: p.To,
+		})
+	}
+	for _, asset := range assets {
+		chosen, err := sel(utxos[asset], amounts[asset])
+		if err != nil {
+			return nil, nil, fmt.Errorf("asset %s: %v", asset.StringLE(), err)
+		}
+		var total util.Fixed8
+		for i := range chosen {
+			total += chosen[i].Value
+			tx.AddInput(&transaction.Input{
+				PrevHash:  chosen[i].Tx,
+				PrevIndex: chosen[i].Index,
+			})
+		}
+		if change := total - amounts[asset]; change > 0 {
+			tx.AddOutput(&transaction.Output{
+				AssetID:    asset,
+				Amount:     change,
+				ScriptHash: from,
+			})
+		}
+		used[asset] = chosen
+	}
+	return tx, used, nil
+}
+
+func subtractUnspents(utxos, used state.UnspentBalances) state.UnspentBalances {
+	res := make(state.UnspentBalances, 0, len(utxos))
+	for i := range utxos {
+		var found bool
+		for j := range used {
+			if utxos[i].Tx.Equals(used[j].Tx) && utxos[i].Index == used[j].Index {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, utxos[i])
+		}
+	}
+	return res
+}
+
+// CreateBatchNEP5Tx creates an invocation transaction calling 'transfer'
+// method of the token for every payment from acc, sysfee is attached to the
+// transaction as its GAS and netfee is paid as network fee. The transaction
+// is not signed.
+func (c *Client) CreateBatchNEP5Tx(acc *wallet.Account, payments []TokenPayment, sysfee, netfee util.Fixed8) (*transaction.Transaction, error) {
+	b, err := c.NewBatch(acc)
+	if err != nil {
+		return nil, err
+	}
+	return b.CreateNEP5Tx(payments, sysfee, netfee)
+}
+
+// CreateNEP5Tx creates an invocation transaction calling 'transfer' method
+// of the token for every payment, sysfee is attached to the transaction as
+// its GAS and netfee is paid as network fee. GAS inputs are selected from
+// the unspent outputs not used by the previous transactions of the batch.
+// They're not marked as used, so that the transaction can be recreated with
+// different fees, thus it must be the last transaction of the batch. The
+// transaction is not signed.
+func (b *Batch) CreateNEP5Tx(payments []TokenPayment, sysfee, netfee util.Fixed8) (*transaction.Transaction, error) {
+	from, err := address.StringToUint160(b.acc.Address)
+	if err != nil {
+		return nil, fmt.Errorf("bad account address: %v", err)
+	}
+	script, err := CreateBatchNEP5Script(from, payments)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := transaction.NewInvocationTX(script, sysfee)
+	tx.Attributes = append(tx.Attributes, transaction.Attribute{
+		Usage: transaction.Script,
+		Data:  from.BytesBE(),
+	})
+
+	if gas := sysfee + netfee; gas > 0 {
+		chosen, err := b.sel(b.utxos[core.UtilityTokenID()], gas)
+		if err != nil {
+			return nil, fmt.Errorf("can't add GAS to transaction: %v", err)
+		}
+		var total util.Fixed8
+		for i := range chosen {
+			total += chosen[i].Value
+			tx.AddInput(&transaction.Input{
+				PrevHash:  chosen[i].Tx,
+				PrevIndex: chosen[i].Index,
+			})
+		}
+		if change := total - gas; change > 0 {
+			tx.AddOutput(&transaction.Output{
+				AssetID:    core.UtilityTokenID(),
+				Amount:     change,
+				ScriptHash: from,
+			})
+		}
+	}
+
+	return tx, nil
+}
+
+// CreateBatchNEP5Script creates a script calling 'transfer' method of the
+// token for every payment from the given account, the script fails if any
+// of transfers fails.
+func CreateBatchNEP5Script(from util.Uint160, payments []TokenPayment) ([]byte, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("no payments")
+	}
+	w := io.NewBufBinWriter()
+	for _, p := range payments {
+		emit.AppCallWithOperationAndArgs(w.BinWriter, p.Token, "transfer", from, p.To, p.Amount)
+		emit.Opcode(w.BinWriter, opcode.THROWIFNOT)
+	}
+	if w.Err != nil {
+		return nil, w.Err
+	}
+	return w.Bytes(), nil
+}
+
+// EstimateTxSize returns the size of tx after it's signed by the contract.
+func EstimateTxSize(tx *transaction.Transaction, ctr *wallet.Contract) int {
+	var size int
+	withFakeWitness(tx, ctr, func() {
+		size = io.GetVarSize(tx)
+	})
+	return size
+}
+
+// withFakeWitness calls f while tx has an additional witness for ctr of the
+// same size as a real one.
+func withFakeWitness(tx *transaction.Transaction, ctr *wallet.Contract, f func()) {
+	scripts := tx.Scripts
+	tx.Scripts = append(scripts[:len(scripts):len(scripts)], transaction.Witness{
+		InvocationScript:   make([]byte, signatureSize*len(ctr.Parameters)),
+		VerificationScript: ctr.Script,
+	})
+	f()
+	tx.Scripts = scripts
+}
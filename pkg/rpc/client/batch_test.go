@@ -0,0 +1,145 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBatchContractTxs(t *testing.T) {
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	acc, err := wallet.NewAccountFromWIF(priv.WIF())
+	require.NoError(t, err)
+	neo, gas := util.Uint256{1}, util.Uint256{2}
+
+	getUnspents := func() map[util.Uint256]state.UnspentBalances {
+		return map[util.Uint256]state.UnspentBalances{
+			neo: getTestUnspents(100, 100, 100),
+			gas: {{Tx: util.Uint256{0xff}, Value: util.Fixed8FromInt64(7)}},
+		}
+	}
+
+	var payments []AssetPayment
+	for i := 0; i < 30; i++ {
+		payments = append(payments, AssetPayment{
+			To:     util.Uint160{byte(i)},
+			Asset:  neo,
+			Amount: util.Fixed8FromInt64(5),
+		})
+	}
+	payments = append(payments, AssetPayment{
+		To:     util.Uint160{0xff},
+		Asset:  gas,
+		Amount: util.Fixed8FromInt64(3),
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		txs, err := createBatchContractTxs(acc.Contract, payments, getUnspents(), SelectSmallestFirst, 0)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		// 31 payments and 2 change outputs.
+		require.Len(t, txs[0].Outputs, 33)
+		require.Len(t, txs[0].Inputs, 3)
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		txs, err := createBatchContractTxs(acc.Contract, payments, getUnspents(), SelectSmallestFirst, 1024)
+		require.NoError(t, err)
+		require.True(t, len(txs) > 1)
+
+		var outputs int
+		inputs := make(map[util.Uint256]bool)
+		for _, tx := range txs {
+			require.NoError(t, acc.SignTx(tx))
+			require.True(t, io.GetVarSize(tx) <= 1024)
+			for _, out := range tx.Outputs {
+				if !out.ScriptHash.Equals(acc.Contract.ScriptHash()) {
+					outputs++
+				}
+			}
+			for _, in := range tx.Inputs {
+				require.False(t, inputs[in.PrevHash], "input is used twice")
+				inputs[in.PrevHash] = true
+			}
+		}
+		require.Equal(t, len(payments), outputs)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		_, err := createBatchContractTxs(acc.Contract, payments, getUnspents(), SelectSmallestFirst, 400)
+		require.Error(t, err)
+	})
+}
+
+func TestBatchMixed(t *testing.T) {
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	acc, err := wallet.NewAccountFromWIF(priv.WIF())
+	require.NoError(t, err)
+	neo, gas := util.Uint256{1}, core.UtilityTokenID()
+
+	newBatch := func() *Batch {
+		gasUnspents := getTestUnspents(1, 2, 7)
+		for i := range gasUnspents {
+			gasUnspents[i].Tx = util.Uint256{0xff, byte(i)}
+		}
+		return &Batch{
+			acc: acc,
+			utxos: map[util.Uint256]state.UnspentBalances{
+				neo: getTestUnspents(100, 100),
+				gas: gasUnspents,
+			},
+			sel: SelectSmallestFirst,
+		}
+	}
+	assetPayments := []AssetPayment{
+		{To: util.Uint160{1}, Asset: neo, Amount: util.Fixed8FromInt64(150)},
+		{To: util.Uint160{2}, Asset: gas, Amount: util.Fixed8FromInt64(3)},
+	}
+	tokenPayments := []TokenPayment{
+		{To: util.Uint160{3}, Token: util.Uint160{0xaa}, Amount: 100},
+		{To: util.Uint160{4}, Token: util.Uint160{0xbb}, Amount: 200},
+	}
+
+	b := newBatch()
+	txs, err := b.CreateContractTxs(assetPayments, 0)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	inputs := make(map[transaction.Input]bool)
+	for _, in := range txs[0].Inputs {
+		inputs[*in] = true
+	}
+	// Fees are taken from the GAS left, recreating the transaction with
+	// another fee doesn't use more outputs.
+	for _, netfee := range []util.Fixed8{0, util.Fixed8FromFloat(0.5)} {
+		tx, err := b.CreateNEP5Tx(tokenPayments, util.Fixed8FromInt64(5), netfee)
+		require.NoError(t, err)
+		require.Equal(t, transaction.InvocationType, tx.Type)
+		require.Len(t, tx.Inputs, 1)
+		require.False(t, inputs[*tx.Inputs[0]], "input is used twice")
+
+		var change util.Fixed8
+		for _, out := range tx.Outputs {
+			require.Equal(t, gas, out.AssetID)
+			require.Equal(t, acc.Contract.ScriptHash(), out.ScriptHash)
+			change += out.Amount
+		}
+		require.Equal(t, util.Fixed8FromInt64(2)-netfee, change)
+	}
+
+	_, err = b.CreateNEP5Tx(tokenPayments, util.Fixed8FromInt64(8), 0)
+	require.Error(t, err)
+	_, err = newBatch().CreateNEP5Tx(tokenPayments, util.Fixed8FromInt64(8), 0)
+	require.NoError(t, err)
+	_, err = b.CreateNEP5Tx(nil, 0, 0)
+	require.Error(t, err)
+}
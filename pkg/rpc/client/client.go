@@ -40,6 +40,8 @@ type Client struct {
 	wif        *keys.WIF
 	balancerMu *sync.Mutex
 	balancer   request.BalanceGetter
+	selectorMu *sync.Mutex
+	selector   CoinSelector
 }
 
 // Options defines options for the RPC client.
@@ -55,6 +57,9 @@ type Options struct {
 	// along with the request body. If no version is specified
 	// the default version (currently 2.0) will be used.
 	Version string
+	// CoinSelector is used to choose transaction inputs, if it's not
+	// specified SelectSmallestFirst will be used.
+	CoinSelector CoinSelector
 }
 
 // New returns a new Client ready to use.
@@ -91,6 +96,8 @@ func New(ctx context.Context, endpoint string, opts Options) (*Client, error) {
 		cli:        opts.Client,
 		cliMu:      new(sync.Mutex),
 		balancerMu: new(sync.Mutex),
+		selectorMu: new(sync.Mutex),
+		selector:   opts.CoinSelector,
 		wifMu:      new(sync.Mutex),
 		endpoint:   url,
 		version:    opts.Version,
@@ -139,6 +146,20 @@ func (c *Client) SetBalancer(b request.BalanceGetter) {
 	}
 }
 
+// CoinSelector is a getter for coin selector field.
+func (c *Client) CoinSelector() CoinSelector {
+	c.selectorMu.Lock()
+	defer c.selectorMu.Unlock()
+	return c.selector
+}
+
+// SetCoinSelector is a setter for coin selector field.
+func (c *Client) SetCoinSelector(sel CoinSelector) {
+	c.selectorMu.Lock()
+	defer c.selectorMu.Unlock()
+	c.selector = sel
+}
+
 // Client is a getter for client field.
 func (c *Client) Client() *http.Client {
 	c.cliMu.Lock()
@@ -172,7 +193,7 @@ func (c *Client) CalculateInputs(address string, asset util.Uint256, cost util.F
 			break
 		}
 	}
-	return unspentsToInputs(utxos, cost)
+	return unspentsToInputs(utxos, cost, c.CoinSelector())
 
 }
 
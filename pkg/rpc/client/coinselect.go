@@ -0,0 +1,142 @@
+package client
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// CoinSelector chooses unspent outputs to use as transaction inputs so that
+// their total value is not less than required. It must not modify utxos.
+type CoinSelector func(utxos state.UnspentBalances, required util.Fixed8) (state.UnspentBalances, error)
+
+// maxBnBTries is the maximum number of branches SelectBranchAndBound
+// explores before giving up on finding an exact match.
+const maxBnBTries = 100000
+
+var errInsufficientFunds = errors.New("cannot compose inputs for transaction; check sender balance")
+
+// coinSelectors maps coin selection strategy names to selectors.
+var coinSelectors = map[string]CoinSelector{
+	"smallest": SelectSmallestFirst,
+	"largest":  SelectLargestFirst,
+	"exact":    SelectBranchAndBound,
+	"random":   SelectRandom,
+}
+
+// GetCoinSelector returns coin selector by its name, it's one of "smallest",
+// "largest", "exact" or "random".
+func GetCoinSelector(name string) (CoinSelector, error) {
+	sel, ok := coinSelectors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown coin selection strategy: %s", name)
+	}
+	return sel, nil
+}
+
+// SelectSmallestFirst takes the smallest outputs first, it spends dust, but
+// produces big transactions.
+func SelectSmallestFirst(utxos state.UnspentBalances, required util.Fixed8) (state.UnspentBalances, error) {
+	sorted := copyUnspents(utxos)
+	sort.Stable(sorted)
+	return selectInOrder(sorted, required)
+}
+
+// SelectLargestFirst takes the largest outputs first, it produces the
+// smallest transactions.
+func SelectLargestFirst(utxos state.UnspentBalances, required util.Fixed8) (state.UnspentBalances, error) {
+	sorted := copyUnspents(utxos)
+	sort.Stable(sort.Reverse(sorted))
+	return selectInOrder(sorted, required)
+}
+
+// SelectRandom takes outputs in random order, so that the choice of
+// outputs reveals less about the wallet.
+func SelectRandom(utxos state.UnspentBalances, required util.Fixed8) (state.UnspentBalances, error) {
+	var seed [8]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	shuffled := copyUnspents(utxos)
+	r := rand.New(rand.NewSource(int64(binary.LittleEndian.Uint64(seed[:]))))
+	r.Shuffle(len(shuffled), shuffled.Swap)
+	return selectInOrder(shuffled, required)
+}
+
+// SelectBranchAndBound searches for a set of outputs with the total value
+// equal to required, so that no change output is needed. If there is no
+// such set (or it's not found in a reasonable number of tries) it falls back
+// to SelectLargestFirst.
+func SelectBranchAndBound(utxos state.UnspentBalances, required util.Fixed8) (state.UnspentBalances, error) {
+	sorted := copyUnspents(utxos)
+	sort.Stable(sort.Reverse(sorted))
+
+	// rest[i] is the total value of sorted[i:].
+	rest := make([]util.Fixed8, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		rest[i] = rest[i+1] + sorted[i].Value
+	}
+	if rest[0] < required {
+		return nil, errInsufficientFunds
+	}
+
+	var (
+		tries    int
+		selected = make([]bool, len(sorted))
+		search   func(i int, sum util.Fixed8) bool
+	)
+	search = func(i int, sum util.Fixed8) bool {
+		if sum == required {
+			return true
+		}
+		tries++
+		if i == len(sorted) || sum > required || sum+rest[i] < required || tries > maxBnBTries {
+			return false
+		}
+		selected[i] = true
+		if search(i+1, sum+sorted[i].Value) {
+			return true
+		}
+		selected[i] = false
+		return search(i+1, sum)
+	}
+	if !search(0, 0) {
+		return SelectLargestFirst(utxos, required)
+	}
+
+	var res state.UnspentBalances
+	for i := range sorted {
+		if selected[i] {
+			res = append(res, sorted[i])
+		}
+	}
+	return res, nil
+}
+
+// selectInOrder takes outputs from utxos in the given order until their
+// total value is enough.
+func selectInOrder(utxos state.UnspentBalances, required util.Fixed8) (state.UnspentBalances, error) {
+	var selected util.Fixed8
+	for i := range utxos {
+		if selected >= required {
+			return utxos[:i], nil
+		}
+		selected += utxos[i].Value
+	}
+	if selected < required {
+		return nil, errInsufficientFunds
+	}
+	return utxos, nil
+}
+
+func copyUnspents(utxos state.UnspentBalances) state.UnspentBalances {
+	res := make(state.UnspentBalances, len(utxos))
+	copy(res, utxos)
+	return res
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestUnspents(values ...int64) state.UnspentBalances {
+	utxos := make(state.UnspentBalances, len(values))
+	for i := range values {
+		utxos[i] = state.UnspentBalance{
+			Tx:    util.Uint256{byte(i)},
+			Index: uint16(i),
+			Value: util.Fixed8FromInt64(values[i]),
+		}
+	}
+	return utxos
+}
+
+func sumUnspents(utxos state.UnspentBalances) util.Fixed8 {
+	var sum util.Fixed8
+	for i := range utxos {
+		sum += utxos[i].Value
+	}
+	return sum
+}
+
+func TestCoinSelectors(t *testing.T) {
+	utxos := getTestUnspents(5, 1, 8, 3, 2)
+	required := util.Fixed8FromInt64(6)
+
+	t.Run("smallest", func(t *testing.T) {
+		res, err := SelectSmallestFirst(utxos, required)
+		require.NoError(t, err)
+		require.Equal(t, utxos[1], res[0])
+		require.Len(t, res, 3)
+		require.Equal(t, util.Fixed8FromInt64(6), sumUnspents(res))
+	})
+	t.Run("largest", func(t *testing.T) {
+		res, err := SelectLargestFirst(utxos, required)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, util.Fixed8FromInt64(8), res[0].Value)
+	})
+	t.Run("exact", func(t *testing.T) {
+		res, err := SelectBranchAndBound(utxos, util.Fixed8FromInt64(12))
+		require.NoError(t, err)
+		require.Equal(t, util.Fixed8FromInt64(12), sumUnspents(res))
+
+		// No exact match, the largest output is enough.
+		res, err = SelectBranchAndBound(getTestUnspents(5, 5), util.Fixed8FromInt64(3))
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+	t.Run("random", func(t *testing.T) {
+		res, err := SelectRandom(utxos, required)
+		require.NoError(t, err)
+		require.True(t, sumUnspents(res) >= required)
+	})
+	// Source slice must stay intact.
+	require.Equal(t, getTestUnspents(5, 1, 8, 3, 2), utxos)
+
+	for name := range coinSelectors {
+		sel, err := GetCoinSelector(name)
+		require.NoError(t, err)
+		_, err = sel(utxos, util.Fixed8FromInt64(20))
+		require.Error(t, err, name)
+	}
+	_, err := GetCoinSelector("unknown")
+	require.Error(t, err)
+}
+
+func TestUnspentsToInputs(t *testing.T) {
+	utxos := getTestUnspents(5, 1, 8)
+	inputs, total, err := unspentsToInputs(utxos, util.Fixed8FromInt64(4), nil)
+	require.NoError(t, err)
+	require.Equal(t, util.Fixed8FromInt64(6), total)
+	require.Len(t, inputs, 2)
+	require.Equal(t, uint16(1), inputs[0].PrevIndex)
+
+	inputs, total, err = unspentsToInputs(utxos, util.Fixed8FromInt64(4), SelectLargestFirst)
+	require.NoError(t, err)
+	require.Equal(t, util.Fixed8FromInt64(8), total)
+	require.Len(t, inputs, 1)
+}
+
+func TestCreateConsolidationTx(t *testing.T) {
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	acc, err := wallet.NewAccountFromWIF(priv.WIF())
+	require.NoError(t, err)
+	asset := util.Uint256{1, 2, 3}
+
+	values := make([]int64, 100)
+	for i := range values {
+		values[i] = int64(i + 1)
+	}
+	utxos := getTestUnspents(values...)
+
+	_, err = createConsolidationTx(acc.Contract, utxos[:1], asset, 1024, 0)
+	require.Equal(t, ErrNothingToConsolidate, err)
+
+	tx, err := createConsolidationTx(acc.Contract, utxos, asset, 1024, 0)
+	require.NoError(t, err)
+	require.True(t, len(tx.Inputs) > 1 && len(tx.Inputs) < len(utxos))
+	require.Len(t, tx.Outputs, 1)
+	n := int64(len(tx.Inputs))
+	require.Equal(t, util.Fixed8FromInt64(n*(n+1)/2), tx.Outputs[0].Amount)
+	require.Equal(t, acc.Contract.ScriptHash(), tx.Outputs[0].ScriptHash)
+
+	require.NoError(t, acc.SignTx(tx))
+	require.True(t, io.GetVarSize(tx) <= 1024)
+
+	tx, err = createConsolidationTx(acc.Contract, utxos, asset, 0, util.Fixed8FromInt64(10))
+	require.NoError(t, err)
+	require.Len(t, tx.Inputs, 10)
+	require.Equal(t, util.Fixed8FromInt64(55), tx.Outputs[0].Amount)
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+)
+
+// signatureSize is the size of one signature push in invocation script.
+const signatureSize = 1 + 64
+
+// ErrNothingToConsolidate is returned when there are less than two unspent
+// outputs that can be merged.
+var ErrNothingToConsolidate = errors.New("nothing to consolidate")
+
+// CreateConsolidationTx creates a contract transaction which merges unspent
+// outputs of the given asset belonging to acc into one output to the same
+// account. The smallest outputs not exceeding maxValue (if it's not 0) are
+// taken while the transaction signed by acc fits into maxSize bytes (if it's
+// not 0), so it can be sent without network fee as long as maxSize is not
+// bigger than node's MaxFreeTransactionSize. The transaction is not signed.
+func (c *Client) CreateConsolidationTx(acc *wallet.Account, asset util.Uint256, maxSize int, maxValue util.Fixed8) (*transaction.Transaction, error) {
+	resp, err := c.GetUnspents(acc.Address)
+	if err != nil {
+		return nil, err
+	}
+	var utxos state.UnspentBalances
+	for _, ubi := range resp.Balance {
+		if asset.Equals(ubi.AssetHash) {
+			utxos = ubi.Unspents
+			break
+		}
+	}
+	return createConsolidationTx(acc.Contract, utxos, asset, maxSize, maxValue)
+}
+
+func createConsolidationTx(ctr *wallet.Contract, utxos state.UnspentBalances, asset util.Uint256, maxSize int, maxValue util.Fixed8) (*transaction.Transaction, error) {
+	sorted := make(state.UnspentBalances, 0, len(utxos))
+	for i := range utxos {
+		if maxValue == 0 || utxos[i].Value <= maxValue {
+			sorted = append(sorted, utxos[i])
+		}
+	}
+	sort.Stable(sorted)
+
+	tx := transaction.NewContractTX()
+	tx.AddOutput(&transaction.Output{
+		AssetID:    asset,
+		ScriptHash: ctr.ScriptHash(),
+	})
+
+	var total util.Fixed8
+	for i := range sorted {
+		tx.AddInput(&transaction.Input{
+			PrevHash:  sorted[i].Tx,
+			PrevIndex: sorted[i].Index,
+		})
+		if maxSize != 0 && EstimateTxSize(tx, ctr) > maxSize {
+			tx.Inputs = tx.Inputs[:len(tx.Inputs)-1]
+			break
+		}
+		total += sorted[i].Value
+	}
+	if len(tx.Inputs) < 2 {
+		return nil, ErrNothingToConsolidate
+	}
+	tx.Outputs[0].Amount = total
+	return tx, nil
+}
@@ -18,6 +18,7 @@ TODO:
 
 Supported methods
 
+	calculatenetworkfee
 	getaccountstate
 	getapplicationlog
 	getassetstate
@@ -35,6 +36,7 @@ Supported methods
 	getpeers
 	getrawmempool
 	getrawtransaction
+	getstateroot
 	getstorage
 	gettransactionheight
 	gettxout
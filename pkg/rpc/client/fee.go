@@ -0,0 +1,83 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+)
+
+// FeePriority is the priority transaction should have in node's memory pool.
+type FeePriority byte
+
+const (
+	// LowPriority is the minimum network fee for transaction to be accepted,
+	// it's zero for transactions not bigger than MaxFreeTransactionSize.
+	LowPriority FeePriority = iota
+	// HighPriority is the network fee not less than LowPriorityThreshold, so
+	// that transaction is not delayed when there are a lot of free ones.
+	HighPriority
+)
+
+// maxFeeIterations is the maximum number of times transaction is recreated
+// to pay enough network fee.
+const maxFeeIterations = 8
+
+// GetFeePriority returns fee priority by its name, it's either "low" or
+// "high".
+func GetFeePriority(name string) (FeePriority, error) {
+	switch name {
+	case "low":
+		return LowPriority, nil
+	case "high":
+		return HighPriority, nil
+	default:
+		return 0, fmt.Errorf("unknown fee priority: %s", name)
+	}
+}
+
+// EstimateNetworkFee returns network fee tx needs to pay to be accepted with
+// the given priority after it's signed by the contract. It uses
+// calculatenetworkfee RPC call.
+func (c *Client) EstimateNetworkFee(tx *transaction.Transaction, ctr *wallet.Contract, prio FeePriority) (util.Fixed8, error) {
+	var (
+		res *result.NetworkFee
+		err error
+	)
+	withFakeWitness(tx, ctr, func() {
+		res, err = c.CalculateNetworkFee(tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if prio == HighPriority {
+		return res.PriorityFee, nil
+	}
+	return res.MinimumFee, nil
+}
+
+// CreateTxWithNetworkFee creates transaction with create paying network fee
+// needed for it to be accepted with the given priority after it's signed by
+// the contract. Paying network fee makes transaction bigger (which can require
+// more fee), so create can be called several times with growing fee.
+func (c *Client) CreateTxWithNetworkFee(ctr *wallet.Contract, prio FeePriority, create func(netfee util.Fixed8) (*transaction.Transaction, error)) (*transaction.Transaction, error) {
+	var netfee util.Fixed8
+	for i := 0; i < maxFeeIterations; i++ {
+		tx, err := create(netfee)
+		if err != nil {
+			return nil, err
+		}
+		fee, err := c.EstimateNetworkFee(tx, ctr, prio)
+		if err != nil {
+			return nil, fmt.Errorf("can't estimate network fee: %v", err)
+		}
+		if fee <= netfee {
+			return tx, nil
+		}
+		netfee = fee
+	}
+	return nil, errors.New("can't find suitable network fee")
+}
@@ -2,9 +2,7 @@ package client
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
-	"sort"
 
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
@@ -24,6 +22,9 @@ type (
 	NeoScanServer struct {
 		URL  string // "protocol://host:port/"
 		Path string // path to API endpoint without wallet address
+		// CoinSelector is used to choose inputs, SelectSmallestFirst
+		// is used if it's not set.
+		CoinSelector CoinSelector
 	}
 
 	// Unspent stores Unspents per asset
@@ -90,36 +91,33 @@ func (s NeoScanServer) CalculateInputs(address string, assetIDUint util.Uint256,
 		return nil, util.Fixed8(0), errs.Wrapf(err, "Cannot get balance for address %v", address)
 	}
 	filterSpecificAsset(assetID, us, &assetUnspent)
-	return unspentsToInputs(assetUnspent.Unspent, cost)
+	return unspentsToInputs(assetUnspent.Unspent, cost, s.CoinSelector)
 }
 
 // unspentsToInputs uses UnspentBalances to create a slice of inputs for a new
-// transcation containing the required amount of asset.
-func unspentsToInputs(utxos state.UnspentBalances, required util.Fixed8) ([]transaction.Input, util.Fixed8, error) {
-	var (
-		num, i   uint16
-		selected = util.Fixed8(0)
-	)
-	sort.Sort(utxos)
-
-	for _, us := range utxos {
-		if selected >= required {
-			break
-		}
-		selected += us.Value
-		num++
+// transcation containing the required amount of asset. Outputs are chosen by
+// sel, SelectSmallestFirst is used if it's nil.
+func unspentsToInputs(utxos state.UnspentBalances, required util.Fixed8, sel CoinSelector) ([]transaction.Input, util.Fixed8, error) {
+	if sel == nil {
+		sel = SelectSmallestFirst
 	}
-	if selected < required {
-		return nil, util.Fixed8(0), errors.New("cannot compose inputs for transaction; check sender balance")
+	chosen, err := sel(utxos, required)
+	if err != nil {
+		return nil, util.Fixed8(0), err
 	}
 
-	inputs := make([]transaction.Input, 0, num)
-	for i = 0; i < num; i++ {
+	var selected util.Fixed8
+	inputs := make([]transaction.Input, 0, len(chosen))
+	for i := range chosen {
+		selected += chosen[i].Value
 		inputs = append(inputs, transaction.Input{
-			PrevHash:  utxos[i].Tx,
-			PrevIndex: utxos[i].Index,
+			PrevHash:  chosen[i].Tx,
+			PrevIndex: chosen[i].Index,
 		})
 	}
+	if selected < required {
+		return nil, util.Fixed8(0), errInsufficientFunds
+	}
 
 	return inputs, selected, nil
 }
@@ -4,15 +4,10 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
-	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
-	"github.com/nspcc-dev/neo-go/pkg/io"
-	"github.com/nspcc-dev/neo-go/pkg/rpc/request"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
-	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
 )
 
@@ -93,28 +88,27 @@ func (c *Client) NEP5TokenInfo(tokenHash util.Uint160) (*wallet.Token, error) {
 	return wallet.NewToken(tokenHash, name, symbol, decimals), nil
 }
 
+// CreateNEP5TransferTx creates an invocation transaction that invokes
+// 'transfer' method on a given token to move specified amount of NEP5 assets
+// (in FixedN format using contract's number of decimals) to given account
+// attaching gas to it and paying netfee as a network fee. The transaction is
+// not signed.
+func (c *Client) CreateNEP5TransferTx(acc *wallet.Account, to util.Uint160, token *wallet.Token, amount int64, gas, netfee util.Fixed8) (*transaction.Transaction, error) {
+	return c.CreateBatchNEP5Tx(acc, []TokenPayment{{
+		To:     to,
+		Token:  token.Hash,
+		Amount: amount,
+	}}, gas, netfee)
+}
+
 // TransferNEP5 creates an invocation transaction that invokes 'transfer' method
 // on a given token to move specified amount of NEP5 assets (in FixedN format
-// using contract's number of decimals) to given account.
+// using contract's number of decimals) to given account and sends it to the
+// network.
 func (c *Client) TransferNEP5(acc *wallet.Account, to util.Uint160, token *wallet.Token, amount int64, gas util.Fixed8) (util.Uint256, error) {
-	from, err := address.StringToUint160(acc.Address)
+	tx, err := c.CreateNEP5TransferTx(acc, to, token, amount, gas, 0)
 	if err != nil {
-		return util.Uint256{}, fmt.Errorf("bad account address: %v", err)
-	}
-	// Note: we don't use invoke function here because it requires
-	// 2 round trips instead of one.
-	w := io.NewBufBinWriter()
-	emit.AppCallWithOperationAndArgs(w.BinWriter, token.Hash, "transfer", from, to, amount)
-	emit.Opcode(w.BinWriter, opcode.THROWIFNOT)
-
-	tx := transaction.NewInvocationTX(w.Bytes(), gas)
-	tx.Attributes = append(tx.Attributes, transaction.Attribute{
-		Usage: transaction.Script,
-		Data:  from.BytesBE(),
-	})
-
-	if err := request.AddInputsAndUnspentsToTx(tx, acc.Address, core.UtilityTokenID(), gas, c); err != nil {
-		return util.Uint256{}, fmt.Errorf("can't add GAS to transaction: %v", err)
+		return util.Uint256{}, err
 	}
 
 	if err := acc.SignTx(tx); err != nil {
@@ -16,6 +16,20 @@ import (
 	"github.com/pkg/errors"
 )
 
+// CalculateNetworkFee returns network fees for the given transaction. Its
+// witnesses can have empty invocation scripts, signatures for standard
+// verification scripts are accounted for by the node then.
+func (c *Client) CalculateNetworkFee(tx *transaction.Transaction) (*result.NetworkFee, error) {
+	var (
+		params = request.NewRawParams(hex.EncodeToString(tx.Bytes()))
+		resp   = &result.NetworkFee{}
+	)
+	if err := c.performRequest("calculatenetworkfee", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetAccountState returns detailed information about a NEO account.
 func (c *Client) GetAccountState(address string) (*result.AccountState, error) {
 	var (
@@ -303,6 +317,18 @@ func (c *Client) GetRawTransactionVerbose(hash util.Uint256) (*result.Transactio
 	return resp, nil
 }
 
+// GetStateRoot returns the state digest of the block with the given index.
+func (c *Client) GetStateRoot(index uint32) (*result.StateRoot, error) {
+	var (
+		params = request.NewRawParams(index)
+		resp   = &result.StateRoot{}
+	)
+	if err := c.performRequest("getstateroot", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetStorage returns the stored value, according to the contract script hash and the stored key.
 func (c *Client) GetStorage(hash util.Uint160, key []byte) ([]byte, error) {
 	var (
@@ -405,6 +431,24 @@ func (c *Client) InvokeScript(script string) (*result.Invoke, error) {
 	return resp, nil
 }
 
+// InvokeScriptWithHashes is the same as InvokeScript, but witnesses are
+// checked successfully for the given script hashes, as if the transaction
+// was signed by them.
+func (c *Client) InvokeScriptWithHashes(script string, hashes []util.Uint160) (*result.Invoke, error) {
+	strHashes := make([]string, len(hashes))
+	for i := range hashes {
+		strHashes[i] = hashes[i].StringLE()
+	}
+	var (
+		params = request.NewRawParams(script, strHashes)
+		resp   = &result.Invoke{}
+	)
+	if err := c.performRequest("invokescript", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // InvokeFunction returns the results after calling the smart contract scripthash
 // with the given operation and parameters.
 // NOTE: this is test invoke and will not affect the blockchain.
@@ -432,6 +476,39 @@ func (c *Client) Invoke(script string, params []smartcontract.Parameter) (*resul
 	return resp, nil
 }
 
+// InvokeFunctionWithEffects is the same as InvokeFunction, but it also
+// requests side effects of the invocation (contract calls, storage accesses,
+// notifications and transfers). This is a neo-go specific extension.
+func (c *Client) InvokeFunctionWithEffects(script, operation string, params []smartcontract.Parameter) (*result.Invoke, error) {
+	if params == nil {
+		params = []smartcontract.Parameter{}
+	}
+	var (
+		p    = request.NewRawParams(script, operation, params, 1)
+		resp = &result.Invoke{}
+	)
+	if err := c.performRequest("invokefunction", p, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// InvokeWithEffects is the same as Invoke, but it also requests side effects
+// of the invocation. This is a neo-go specific extension.
+func (c *Client) InvokeWithEffects(script string, params []smartcontract.Parameter) (*result.Invoke, error) {
+	if params == nil {
+		params = []smartcontract.Parameter{}
+	}
+	var (
+		p    = request.NewRawParams(script, params, 1)
+		resp = &result.Invoke{}
+	)
+	if err := c.performRequest("invoke", p, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // SendRawTransaction broadcasts a transaction over the NEO network.
 // The given hex string needs to be signed with a keypair.
 // When the result of the response object is true, the TX has successfully
@@ -498,28 +575,37 @@ func (c *Client) TransferAsset(asset util.Uint256, address string, amount util.F
 	return rawTx.Hash(), nil
 }
 
-// SignAndPushInvocationTx signs and pushes given script as an invocation
-// transaction  using given wif to sign it and spending the amount of gas
-// specified. It returns a hash of the invocation transaction and an error.
-func (c *Client) SignAndPushInvocationTx(script []byte, acc *wallet.Account, sysfee util.Fixed8, netfee util.Fixed8) (util.Uint256, error) {
-	var txHash util.Uint256
-	var err error
-
+// CreateInvocationTx creates an invocation transaction for the given script
+// with sysfee GAS and netfee network fee paid by acc. The transaction is not
+// signed.
+func (c *Client) CreateInvocationTx(script []byte, acc *wallet.Account, sysfee util.Fixed8, netfee util.Fixed8) (*transaction.Transaction, error) {
 	tx := transaction.NewInvocationTX(script, sysfee)
 	gas := sysfee + netfee
 
 	if gas > 0 {
-		if err = request.AddInputsAndUnspentsToTx(tx, acc.Address, core.UtilityTokenID(), gas, c); err != nil {
-			return txHash, errors.Wrap(err, "failed to add inputs and unspents to transaction")
+		if err := request.AddInputsAndUnspentsToTx(tx, acc.Address, core.UtilityTokenID(), gas, c); err != nil {
+			return nil, errors.Wrap(err, "failed to add inputs and unspents to transaction")
 		}
 	} else {
 		addr, err := address.StringToUint160(acc.Address)
 		if err != nil {
-			return txHash, errors.Wrap(err, "failed to get address")
+			return nil, errors.Wrap(err, "failed to get address")
 		}
 		tx.AddVerificationHash(addr)
 	}
+	return tx, nil
+}
 
+// SignAndPushInvocationTx signs and pushes given script as an invocation
+// transaction  using given wif to sign it and spending the amount of gas
+// specified. It returns a hash of the invocation transaction and an error.
+func (c *Client) SignAndPushInvocationTx(script []byte, acc *wallet.Account, sysfee util.Fixed8, netfee util.Fixed8) (util.Uint256, error) {
+	var txHash util.Uint256
+
+	tx, err := c.CreateInvocationTx(script, acc, sysfee, netfee)
+	if err != nil {
+		return txHash, err
+	}
 	if err = acc.SignTx(tx); err != nil {
 		return txHash, errors.Wrap(err, "failed to sign tx")
 	}
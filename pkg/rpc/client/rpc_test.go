@@ -15,6 +15,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -31,6 +32,40 @@ type rpcClientTestCase struct {
 // published in official C# JSON-RPC API v2.10.3 reference
 // (see https://docs.neo.org/docs/en-us/reference/rpc/latest-version/api.html)
 var rpcClientTestCases = map[string][]rpcClientTestCase{
+	"calculatenetworkfee": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.CalculateNetworkFee(transaction.NewContractTX())
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"size":1100,"networkfee":"0","minimumfee":"0.001","priorityfee":"0.001"}}`,
+			result: func(c *Client) interface{} {
+				return &result.NetworkFee{
+					Size:        1100,
+					MinimumFee:  util.Fixed8FromFloat(0.001),
+					PriorityFee: util.Fixed8FromFloat(0.001),
+				}
+			},
+		},
+		{
+			name: "estimate high priority",
+			invoke: func(c *Client) (interface{}, error) {
+				priv, err := keys.NewPrivateKey()
+				if err != nil {
+					panic(err)
+				}
+				acc, err := wallet.NewAccountFromWIF(priv.WIF())
+				if err != nil {
+					panic(err)
+				}
+				return c.EstimateNetworkFee(transaction.NewContractTX(), acc.Contract, HighPriority)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"size":200,"networkfee":"0","minimumfee":"0","priorityfee":"0.001"}}`,
+			result: func(c *Client) interface{} {
+				return util.Fixed8FromFloat(0.001)
+			},
+		},
+	},
 	"getaccountstate": {
 		{
 			name: "positive",
@@ -682,6 +717,22 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"getstateroot": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetStateRoot(1)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"index":1,"stateroot":"0x0f2ec3ae6dbbd9ea4cc24a1cfd2d2d23f3c3b2e1a9ce4a1b6d4f3c2e1a9b8c7d"}}`,
+			result: func(c *Client) interface{} {
+				root, err := util.Uint256DecodeStringLE("0f2ec3ae6dbbd9ea4cc24a1cfd2d2d23f3c3b2e1a9ce4a1b6d4f3c2e1a9b8c7d")
+				if err != nil {
+					panic(err)
+				}
+				return &result.StateRoot{Index: 1, StateRoot: root}
+			},
+		},
+	},
 	"getstorage": {
 		{
 			name: "positive",
@@ -841,6 +892,37 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"invoke": {
+		{
+			name: "with effects",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.InvokeWithEffects("af7c7328eee5a275a3bcaee2bf0cf662b5e739be", nil)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"script":"00c166be39e7b562f60cbfe2aebca375a2e5ee28737caf","state":"HALT","gas_consumed":"0.1","stack":[],"effects":{"calls":[{"caller":"91b83e96f2a7c4fdf0c1688441ec61986c7cae26","callee":"af7c7328eee5a275a3bcaee2bf0cf662b5e739be","dynamic":false}],"storage":[],"notifications":[],"transfers":[]}}}`,
+			result: func(c *Client) interface{} {
+				caller, err := util.Uint160DecodeStringLE("91b83e96f2a7c4fdf0c1688441ec61986c7cae26")
+				if err != nil {
+					panic(err)
+				}
+				callee, err := util.Uint160DecodeStringLE("af7c7328eee5a275a3bcaee2bf0cf662b5e739be")
+				if err != nil {
+					panic(err)
+				}
+				return &result.Invoke{
+					State:       "HALT",
+					GasConsumed: "0.1",
+					Script:      "00c166be39e7b562f60cbfe2aebca375a2e5ee28737caf",
+					Stack:       []smartcontract.Parameter{},
+					Effects: &result.InvokeEffects{
+						Calls:         []result.ContractCall{{Caller: caller, Callee: callee}},
+						Storage:       []result.StorageAccess{},
+						Notifications: []result.NotificationEvent{},
+						Transfers:     []result.Transfer{},
+					},
+				}
+			},
+		},
+	},
 	"invokescript": {
 		{
 			name: "positive",
@@ -866,6 +948,26 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 				}
 			},
 		},
+		{
+			name: "with hashes",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.InvokeScriptWithHashes("51", []util.Uint160{{1, 2, 3}})
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"script":"51","state":"HALT","gas_consumed":"0","stack":[{"type":"Integer","value":"1"}]}}`,
+			result: func(c *Client) interface{} {
+				return &result.Invoke{
+					State:       "HALT",
+					GasConsumed: "0",
+					Script:      "51",
+					Stack: []smartcontract.Parameter{
+						{
+							Type:  smartcontract.IntegerType,
+							Value: int64(1),
+						},
+					},
+				}
+			},
+		},
 	},
 	"sendrawtransaction": {
 		{
@@ -0,0 +1,31 @@
+package result
+
+import "github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+
+type (
+	// ConsensusState is a result of the `getconsensusstate` RPC call.
+	ConsensusState struct {
+		Height              uint32                    `json:"height"`
+		View                byte                      `json:"view"`
+		Primary             uint16                    `json:"primary"`
+		WatchOnly           bool                      `json:"watchonly"`
+		Stage               string                    `json:"stage"`
+		MissingTransactions int                       `json:"missingtransactions"`
+		Validators          []ConsensusValidatorState `json:"validators"`
+	}
+
+	// ConsensusValidatorState describes messages received from a single
+	// validator in the current view.
+	ConsensusValidatorState struct {
+		PublicKey  *keys.PublicKey      `json:"publickey"`
+		Prepared   bool                 `json:"prepared"`
+		Committed  bool                 `json:"committed"`
+		ChangeView *ConsensusChangeView `json:"changeview,omitempty"`
+	}
+
+	// ConsensusChangeView describes the view change request.
+	ConsensusChangeView struct {
+		NewView   byte   `json:"newview"`
+		Timestamp uint32 `json:"timestamp"`
+	}
+)
@@ -1,7 +1,13 @@
 package result
 
 import (
+	"encoding/hex"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
 )
 
 // Invoke represents code invocation result and is used by several RPC calls
@@ -11,4 +17,114 @@ type Invoke struct {
 	GasConsumed string                    `json:"gas_consumed"`
 	Script      string                    `json:"script"`
 	Stack       []smartcontract.Parameter `json:"stack"`
+	Effects     *InvokeEffects            `json:"effects,omitempty"`
+}
+
+// InvokeEffects contains side effects of the invocation: contracts called,
+// storage keys accessed, notifications and NEP5 transfers produced.
+type InvokeEffects struct {
+	Calls         []ContractCall      `json:"calls"`
+	Storage       []StorageAccess     `json:"storage"`
+	Notifications []NotificationEvent `json:"notifications"`
+	Transfers     []Transfer          `json:"transfers"`
+}
+
+// ContractCall is a call of one contract from another.
+type ContractCall struct {
+	Caller  util.Uint160 `json:"caller"`
+	Callee  util.Uint160 `json:"callee"`
+	Dynamic bool         `json:"dynamic"`
+}
+
+// StorageAccess lists hex-encoded storage keys accessed by the contract,
+// Find contains key prefixes used for Storage.Find.
+type StorageAccess struct {
+	Contract util.Uint160 `json:"contract"`
+	Read     []string     `json:"read,omitempty"`
+	Write    []string     `json:"write,omitempty"`
+	Delete   []string     `json:"delete,omitempty"`
+	Find     []string     `json:"find,omitempty"`
+}
+
+// Transfer is a NEP5 transfer event, From and To are empty for minted and
+// burnt tokens.
+type Transfer struct {
+	Asset  util.Uint160 `json:"asset_hash"`
+	From   string       `json:"from"`
+	To     string       `json:"to"`
+	Amount string       `json:"amount"`
+}
+
+// NewInvokeEffects creates a new InvokeEffects wrapper, storage accesses are
+// grouped by contract with duplicate keys removed.
+func NewInvokeEffects(e *state.Effects) *InvokeEffects {
+	res := &InvokeEffects{
+		Calls:         make([]ContractCall, 0, len(e.Calls)),
+		Storage:       make([]StorageAccess, 0),
+		Notifications: make([]NotificationEvent, 0, len(e.Notifications)),
+		Transfers:     make([]Transfer, 0),
+	}
+	for _, c := range e.Calls {
+		res.Calls = append(res.Calls, ContractCall{
+			Caller:  c.Caller,
+			Callee:  c.Callee,
+			Dynamic: c.Dynamic,
+		})
+	}
+
+	index := make(map[util.Uint160]int)
+	seen := make(map[string]bool)
+	for _, s := range e.Storage {
+		i, ok := index[s.ScriptHash]
+		if !ok {
+			i = len(res.Storage)
+			index[s.ScriptHash] = i
+			res.Storage = append(res.Storage, StorageAccess{Contract: s.ScriptHash})
+		}
+		key := hex.EncodeToString(s.Key)
+		id := s.ScriptHash.StringLE() + s.Op.String() + key
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		acc := &res.Storage[i]
+		switch s.Op {
+		case state.StorageRead:
+			acc.Read = append(acc.Read, key)
+		case state.StorageWrite:
+			acc.Write = append(acc.Write, key)
+		case state.StorageDelete:
+			acc.Delete = append(acc.Delete, key)
+		case state.StorageFind:
+			acc.Find = append(acc.Find, key)
+		}
+	}
+
+	for i := range e.Notifications {
+		ne := &e.Notifications[i]
+		res.Notifications = append(res.Notifications, NotificationEvent{
+			Contract: ne.ScriptHash,
+			Item:     ne.Item.ToContractParameter(make(map[vm.StackItem]bool)),
+		})
+		from, to, amount, ok := ne.NEP5Transfer()
+		if !ok {
+			continue
+		}
+		res.Transfers = append(res.Transfers, Transfer{
+			Asset:  ne.ScriptHash,
+			From:   transferAddress(from),
+			To:     transferAddress(to),
+			Amount: amount.String(),
+		})
+	}
+	return res
+}
+
+// transferAddress converts NEP5 transfer sender or receiver to an address.
+func transferAddress(b []byte) string {
+	u, err := util.Uint160DecodeBytesBE(b)
+	if err != nil {
+		return ""
+	}
+	return address.Uint160ToString(u)
 }
@@ -0,0 +1,17 @@
+package result
+
+import "github.com/nspcc-dev/neo-go/pkg/util"
+
+// NetworkFee represents result of the `calculatenetworkfee` call.
+type NetworkFee struct {
+	// Size is the size of the transaction with all its witnesses.
+	Size int `json:"size"`
+	// NetworkFee is the network fee paid by the transaction.
+	NetworkFee util.Fixed8 `json:"networkfee"`
+	// MinimumFee is the network fee needed for the transaction to be
+	// accepted by the node.
+	MinimumFee util.Fixed8 `json:"minimumfee"`
+	// PriorityFee is the network fee needed for the transaction to be
+	// accepted and not to be low priority.
+	PriorityFee util.Fixed8 `json:"priorityfee"`
+}
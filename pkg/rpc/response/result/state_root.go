@@ -0,0 +1,12 @@
+package result
+
+import "github.com/nspcc-dev/neo-go/pkg/util"
+
+// StateRoot represents result of the `getstateroot` call.
+type StateRoot struct {
+	// Index is the height of the block.
+	Index uint32 `json:"index"`
+	// StateRoot is the digest of the ledger state after the block, it
+	// covers all state changes made since the genesis.
+	StateRoot util.Uint256 `json:"stateroot"`
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/nspcc-dev/neo-go/pkg/rpc"
 
+	"github.com/nspcc-dev/neo-go/pkg/consensus"
 	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
@@ -26,6 +27,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
 	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -44,6 +46,7 @@ type (
 )
 
 var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, error){
+	"calculatenetworkfee":  (*Server).calculateNetworkFee,
 	"getaccountstate":      (*Server).getAccountState,
 	"getapplicationlog":    (*Server).getApplicationLog,
 	"getassetstate":        (*Server).getAssetState,
@@ -55,12 +58,14 @@ var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, error){
 	"getblocksysfee":       (*Server).getBlockSysFee,
 	"getclaimable":         (*Server).getClaimable,
 	"getconnectioncount":   (*Server).getConnectionCount,
+	"getconsensusstate":    (*Server).getConsensusState,
 	"getcontractstate":     (*Server).getContractState,
 	"getnep5balances":      (*Server).getNEP5Balances,
 	"getnep5transfers":     (*Server).getNEP5Transfers,
 	"getpeers":             (*Server).getPeers,
 	"getrawmempool":        (*Server).getRawMempool,
 	"getrawtransaction":    (*Server).getrawtransaction,
+	"getstateroot":         (*Server).getStateRoot,
 	"getstorage":           (*Server).getStorage,
 	"gettransactionheight": (*Server).getTransactionHeight,
 	"gettxout":             (*Server).getTxOut,
@@ -216,6 +221,53 @@ func (s *Server) getConnectionCount(_ request.Params) (interface{}, error) {
 	return s.coreServer.PeerCount(), nil
 }
 
+// getConsensusState returns the state of the local dBFT instance for the
+// current or given recent height.
+func (s *Server) getConsensusState(reqParams request.Params) (interface{}, error) {
+	var st *consensus.State
+
+	if param, ok := reqParams.Value(0); ok {
+		h, err := param.GetInt()
+		if err != nil || h < 0 {
+			return nil, response.ErrInvalidParams
+		}
+		st = s.coreServer.ConsensusStateAt(uint32(h))
+	} else {
+		st = s.coreServer.ConsensusState()
+	}
+	if st == nil {
+		return nil, response.NewInternalServerError("consensus state is not available", nil)
+	}
+	return newConsensusState(st), nil
+}
+
+// newConsensusState converts consensus state snapshot into the RPC result.
+func newConsensusState(st *consensus.State) result.ConsensusState {
+	res := result.ConsensusState{
+		Height:              st.Height,
+		View:                st.View,
+		Primary:             st.Primary,
+		WatchOnly:           st.WatchOnly,
+		Stage:               st.Stage,
+		MissingTransactions: st.MissingTransactions,
+		Validators:          make([]result.ConsensusValidatorState, len(st.Validators)),
+	}
+	for i, v := range st.Validators {
+		res.Validators[i] = result.ConsensusValidatorState{
+			PublicKey: v.PublicKey,
+			Prepared:  v.Prepared,
+			Committed: v.Committed,
+		}
+		if v.ChangeView != nil {
+			res.Validators[i].ChangeView = &result.ConsensusChangeView{
+				NewView:   v.ChangeView.NewView,
+				Timestamp: v.ChangeView.Timestamp,
+			}
+		}
+	}
+	return res
+}
+
 func (s *Server) getBlock(reqParams request.Params) (interface{}, error) {
 	var hash util.Uint256
 
@@ -517,7 +569,7 @@ func (s *Server) getDecimals(h util.Uint160, cache map[util.Uint160]int64) (int6
 	if err != nil {
 		return 0, err
 	}
-	res := s.runScriptInVM(script)
+	res := s.runScriptInVM(script, false)
 	if res == nil || res.State != "HALT" || len(res.Stack) == 0 {
 		return 0, errors.New("execution error")
 	}
@@ -538,6 +590,26 @@ func (s *Server) getDecimals(h util.Uint160, cache map[util.Uint160]int64) (int6
 	return d, nil
 }
 
+// getStateRoot returns the state digest of the block at the specified height.
+func (s *Server) getStateRoot(reqParams request.Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, request.NumberT)
+	if !ok {
+		return nil, response.ErrInvalidParams
+	}
+	num, err := s.blockHeightFromParam(param)
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	root, err := s.chain.GetStateRoot(uint32(num))
+	if err != nil {
+		return nil, response.NewRPCError("Unknown state root", "", err)
+	}
+	return result.StateRoot{
+		Index:     uint32(num),
+		StateRoot: root,
+	}, nil
+}
+
 func (s *Server) getStorage(ps request.Params) (interface{}, error) {
 	param, ok := ps.Value(0)
 	if !ok {
@@ -793,7 +865,7 @@ func (s *Server) getUnclaimed(ps request.Params) (interface{}, error) {
 
 	acc := s.chain.GetAccountState(u)
 	if acc == nil {
-		return nil, response.NewInternalServerError("unknown account", nil)
+		acc = state.NewAccount(u)
 	}
 
 	return result.NewUnclaimed(acc, s.chain)
@@ -840,11 +912,18 @@ func (s *Server) invoke(reqParams request.Params) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	withEffects, err := getEffectsParam(reqParams, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(reqParams) > 3 {
+		return nil, response.ErrInvalidParams
+	}
 	script, err := request.CreateInvocationScript(scriptHash, slice)
 	if err != nil {
 		return nil, err
 	}
-	return s.runScriptInVM(script), nil
+	return s.runScriptInVM(script, nil, withEffects), nil
 }
 
 // invokescript implements the `invokescript` RPC call.
@@ -857,11 +936,24 @@ func (s *Server) invokeFunction(reqParams request.Params) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	script, err := request.CreateFunctionInvocationScript(scriptHash, reqParams[1:])
+	// Operation and parameters array are passed to the contract, effects
+	// flag can only follow the array, any other parameters are an error.
+	args := reqParams[1:]
+	if len(args) > 2 {
+		if len(args) > 3 || args[1].Type != request.ArrayT {
+			return nil, response.ErrInvalidParams
+		}
+		args = args[:2]
+	}
+	withEffects, err := getEffectsParam(reqParams, 3)
+	if err != nil {
+		return nil, err
+	}
+	script, err := request.CreateFunctionInvocationScript(scriptHash, args)
 	if err != nil {
 		return nil, err
 	}
-	return s.runScriptInVM(script), nil
+	return s.runScriptInVM(script, nil, withEffects), nil
 }
 
 // invokescript implements the `invokescript` RPC call.
@@ -874,22 +966,87 @@ func (s *Server) invokescript(reqParams request.Params) (interface{}, error) {
 	if err != nil {
 		return nil, response.ErrInvalidParams
 	}
+	// Optional array of script hashes to check witnesses for goes first,
+	// effects flag follows it.
+	var hashes []util.Uint160
+	effectsIndex := 1
+	if param, ok := reqParams.ValueWithType(1, request.ArrayT); ok {
+		arr, err := param.GetArray()
+		if err != nil {
+			return nil, response.ErrInvalidParams
+		}
+		hashes = make([]util.Uint160, len(arr))
+		for i := range arr {
+			if hashes[i], err = arr[i].GetUint160FromHex(); err != nil {
+				return nil, response.ErrInvalidParams
+			}
+		}
+		effectsIndex++
+	}
+	withEffects, err := getEffectsParam(reqParams, effectsIndex)
+	if err != nil {
+		return nil, err
+	}
+	if len(reqParams) > effectsIndex+1 {
+		return nil, response.ErrInvalidParams
+	}
 
-	return s.runScriptInVM(script), nil
+	return s.runScriptInVM(script, hashes, withEffects), nil
+}
+
+// getEffectsParam returns true if invocation side effects are requested with
+// the optional number parameter at the given index.
+func getEffectsParam(reqParams request.Params, index int) (bool, error) {
+	param, ok := reqParams.Value(index)
+	if !ok {
+		return false, nil
+	}
+	if param.Type != request.NumberT {
+		return false, response.ErrInvalidParams
+	}
+	v, err := param.GetInt()
+	if err != nil {
+		return false, response.ErrInvalidParams
+	}
+	return v != 0, nil
 }
 
 // runScriptInVM runs given script in a new test VM and returns the invocation
-// result.
-func (s *Server) runScriptInVM(script []byte) *result.Invoke {
-	vm := s.chain.GetTestVM()
+// result, witnesses are only valid for the given hashes and side effects are
+// only collected if withEffects is true.
+func (s *Server) runScriptInVM(script []byte, hashes []util.Uint160, withEffects bool) *result.Invoke {
+	var (
+		effects *state.Effects
+		tx      *transaction.Transaction
+	)
+	if withEffects {
+		effects = new(state.Effects)
+	}
+	if len(hashes) != 0 {
+		// Witnesses are checked against script attributes of the
+		// transaction.
+		tx = transaction.NewInvocationTX(script, 0)
+		for _, h := range hashes {
+			tx.Attributes = append(tx.Attributes, transaction.Attribute{
+				Usage: transaction.Script,
+				Data:  h.BytesBE(),
+			})
+		}
+	}
+	vm := s.chain.GetTestVM(tx, effects)
 	vm.SetGasLimit(s.config.MaxGasInvoke)
 	vm.LoadScript(script)
 	_ = vm.Run()
+	var resEffects *result.InvokeEffects
+	if withEffects {
+		resEffects = result.NewInvokeEffects(effects)
+	}
 	result := &result.Invoke{
 		State:       vm.State(),
 		GasConsumed: vm.GasConsumed().String(),
 		Script:      hex.EncodeToString(script),
 		Stack:       vm.Estack().ToContractParameters(),
+		Effects:     resEffects,
 	}
 	return result
 }
@@ -959,6 +1116,48 @@ func (s *Server) sendrawtransaction(reqParams request.Params) (interface{}, erro
 	return results, resultsErr
 }
 
+// calculateNetworkFee returns network fees for the given transaction. Its
+// witnesses can have empty invocation scripts, signatures for standard
+// verification scripts are accounted for then.
+func (s *Server) calculateNetworkFee(reqParams request.Params) (interface{}, error) {
+	if len(reqParams) < 1 {
+		return nil, response.ErrInvalidParams
+	}
+	byteTx, err := reqParams[0].GetBytesHex()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	r := io.NewBinReaderFromBuf(byteTx)
+	tx := &transaction.Transaction{}
+	tx.DecodeBinary(r)
+	if r.Err != nil {
+		return nil, response.ErrInvalidParams
+	}
+	for i := range tx.Scripts {
+		w := &tx.Scripts[i]
+		if len(w.InvocationScript) != 0 {
+			continue
+		}
+		nsigs, ok := vm.GetMultiSigThreshold(w.VerificationScript)
+		if !ok && vm.IsSignatureContract(w.VerificationScript) {
+			nsigs = 1
+		}
+		// Every signature is 64 bytes pushed with PUSHBYTES64.
+		w.InvocationScript = make([]byte, nsigs*(1+64))
+	}
+
+	res := &result.NetworkFee{Size: io.GetVarSize(tx)}
+	if tx.Type != transaction.ClaimType {
+		res.NetworkFee = s.chain.NetworkFee(tx)
+		res.MinimumFee = s.chain.MinNetworkFee(res.Size)
+		res.PriorityFee = res.MinimumFee
+		if s.chain.IsLowPriority(res.PriorityFee) {
+			res.PriorityFee = util.Fixed8FromFloat(s.chain.GetConfig().LowPriorityThreshold)
+		}
+	}
+	return res, nil
+}
+
 func (s *Server) blockHeightFromParam(param *request.Param) (int, error) {
 	num, err := param.GetInt()
 	if err != nil {
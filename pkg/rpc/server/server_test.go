@@ -47,6 +47,40 @@ type rpcTestCase struct {
 const testContractHash = "c2789e5ab9bab828743833965b1df0d5fbcc206f"
 
 var rpcTestCases = map[string][]rpcTestCase{
+	"calculatenetworkfee": {
+		{
+			name:   "signed",
+			params: `["d1001b00046e616d6567d3d8602814a429a91afdbaa3914884a1c90c733101201cc9c05cefffe6cdd7b182816a9152ec218d2ec000000141403387ef7940a5764259621e655b3c621a6aafd869a611ad64adcc364d8dd1edf84e00a7f8b11b630a377eaef02791d1c289d711c08b7ad04ff0d6c9caca22cfe6232103cbb45da6072c14761c9da545749d9cfd863f860c351066d16df480602a2024c6ac"]`,
+			result: func(e *executor) interface{} { return &result.NetworkFee{} },
+			check: func(t *testing.T, e *executor, fee interface{}) {
+				res, ok := fee.(*result.NetworkFee)
+				require.True(t, ok)
+				require.Equal(t, 157, res.Size)
+				require.Equal(t, util.Fixed8(0), res.MinimumFee)
+				require.Equal(t, util.Fixed8(0), res.PriorityFee)
+			},
+		},
+		{
+			name:   "without signature",
+			params: `["d1001b00046e616d6567d3d8602814a429a91afdbaa3914884a1c90c733101201cc9c05cefffe6cdd7b182816a9152ec218d2ec000000100232103cbb45da6072c14761c9da545749d9cfd863f860c351066d16df480602a2024c6ac"]`,
+			result: func(e *executor) interface{} { return &result.NetworkFee{} },
+			check: func(t *testing.T, e *executor, fee interface{}) {
+				res, ok := fee.(*result.NetworkFee)
+				require.True(t, ok)
+				require.Equal(t, 157, res.Size)
+			},
+		},
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid tx",
+			params: `["0274d792072617720636f6e747261637"]`,
+			fail:   true,
+		},
+	},
 	"getapplicationlog": {
 		{
 			name:   "positive",
@@ -211,6 +245,36 @@ var rpcTestCases = map[string][]rpcTestCase{
 			},
 		},
 	},
+	"getstateroot": {
+		{
+			name:   "positive",
+			params: "[1]",
+			result: func(e *executor) interface{} {
+				root, _ := e.chain.GetStateRoot(1)
+				return &result.StateRoot{Index: 1, StateRoot: root}
+			},
+		},
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "string height",
+			params: `["first"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid number height",
+			params: `[-2]`,
+			fail:   true,
+		},
+		{
+			name:   "height above the chain",
+			params: `[100500]`,
+			fail:   true,
+		},
+	},
 	"getstorage": {
 		{
 			name:   "positive",
@@ -548,6 +612,18 @@ var rpcTestCases = map[string][]rpcTestCase{
 			},
 		},
 	},
+	"getconsensusstate": {
+		{
+			name:   "consensus is not running",
+			params: "[]",
+			fail:   true,
+		},
+		{
+			name:   "invalid height",
+			params: `["notanumber"]`,
+			fail:   true,
+		},
+	},
 	"getpeers": {
 		{
 			params: "[]",
@@ -627,6 +703,13 @@ var rpcTestCases = map[string][]rpcTestCase{
 				assert.Equal(t, res.Available+res.Unavailable, res.Unclaimed)
 			},
 		},
+		{
+			name:   "unknown account",
+			params: `["AKkkumHbBipZ46UMZJoFynJMXzSRnBvKcs"]`,
+			result: func(*executor) interface{} {
+				return &result.Unclaimed{}
+			},
+		},
 	},
 	"getunspents": {
 		{
@@ -720,6 +803,11 @@ var rpcTestCases = map[string][]rpcTestCase{
 			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", 42]`,
 			fail:   true,
 		},
+		{
+			name:   "too many params",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", [], 1, 1]`,
+			fail:   true,
+		},
 		{
 			name:   "bad params",
 			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", [{"type": "Integer", "value": "qwerty"}]]`,
@@ -737,6 +825,34 @@ var rpcTestCases = map[string][]rpcTestCase{
 				assert.NotEqual(t, "", res.Script)
 				assert.NotEqual(t, "", res.State)
 				assert.NotEqual(t, 0, res.GasConsumed)
+				assert.Nil(t, res.Effects)
+			},
+		},
+		{
+			name:   "effects",
+			params: fmt.Sprintf(`["%s", "init", [], 1]`, testContractHash),
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				require.Equal(t, "HALT", res.State)
+				require.NotNil(t, res.Effects)
+
+				h, err := util.Uint160DecodeStringLE(testContractHash)
+				require.NoError(t, err)
+				require.Equal(t, 1, len(res.Effects.Calls))
+				assert.Equal(t, h, res.Effects.Calls[0].Callee)
+				assert.False(t, res.Effects.Calls[0].Dynamic)
+				require.Equal(t, []result.StorageAccess{{
+					Contract: h,
+					Write:    []string{hex.EncodeToString(h.BytesBE())},
+				}}, res.Effects.Storage)
+				assert.Equal(t, 2, len(res.Effects.Notifications))
+				require.Equal(t, []result.Transfer{{
+					Asset:  h,
+					To:     address.Uint160ToString(h),
+					Amount: "1000000",
+				}}, res.Effects.Transfers)
 			},
 		},
 		{
@@ -754,6 +870,21 @@ var rpcTestCases = map[string][]rpcTestCase{
 			params: `["qwerty", "test", []]`,
 			fail:   true,
 		},
+		{
+			name:   "too many params",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "test", [], 0, 1]`,
+			fail:   true,
+		},
+		{
+			name:   "effects without params array",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "test", "arg", 1]`,
+			fail:   true,
+		},
+		{
+			name:   "bad effects flag",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "test", [], "yes"]`,
+			fail:   true,
+		},
 		{
 			name:   "bad params",
 			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "test", [{"type": "Integer", "value": "qwerty"}]]`,
@@ -771,8 +902,43 @@ var rpcTestCases = map[string][]rpcTestCase{
 				assert.NotEqual(t, "", res.Script)
 				assert.NotEqual(t, "", res.State)
 				assert.NotEqual(t, 0, res.GasConsumed)
+				assert.Nil(t, res.Effects)
+			},
+		},
+		{
+			name:   "effects",
+			params: `["51c56b0d48656c6c6f2c20776f726c6421680f4e656f2e52756e74696d652e4c6f67616c7566", 1]`,
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				require.NotNil(t, res.Effects)
+				assert.Equal(t, 0, len(res.Effects.Calls))
+			},
+		},
+		{
+			name:   "witness hashes",
+			params: `["140102030405060708090a0b0c0d0e0f101112131468184e656f2e52756e74696d652e436865636b5769746e657373", ["14131211100f0e0d0c0b0a090807060504030201"], 1]`,
+			result: func(e *executor) interface{} { return &result.Invoke{} },
+			check: func(t *testing.T, e *executor, inv interface{}) {
+				res, ok := inv.(*result.Invoke)
+				require.True(t, ok)
+				require.Equal(t, "HALT", res.State)
+				require.Equal(t, 1, len(res.Stack))
+				require.Equal(t, true, res.Stack[0].Value)
+				require.NotNil(t, res.Effects)
 			},
 		},
+		{
+			name:   "bad witness hash",
+			params: `["51", ["notahash"]]`,
+			fail:   true,
+		},
+		{
+			name:   "too many params",
+			params: `["51", 1, 1]`,
+			fail:   true,
+		},
 		{
 			name:   "no params",
 			params: `[]`,
@@ -783,6 +949,11 @@ var rpcTestCases = map[string][]rpcTestCase{
 			params: `[42]`,
 			fail:   true,
 		},
+		{
+			name:   "bad effects flag",
+			params: `["51c56b0d48656c6c6f2c20776f726c6421680f4e656f2e52756e74696d652e4c6f67616c7566", []]`,
+			fail:   true,
+		},
 		{
 			name:   "bas string",
 			params: `["qwerty"]`,
@@ -124,6 +124,13 @@ func (c *ParameterContext) AddSignature(ctr *wallet.Contract, pub *keys.PublicKe
 	return nil
 }
 
+// AddContract adds an empty item for the specified contract, so that the
+// context can be signed by the holder of contract keys without knowing
+// which contracts need to be satisfied.
+func (c *ParameterContext) AddContract(ctr *wallet.Contract) {
+	c.getItemForContract(ctr)
+}
+
 func (c *ParameterContext) getItemForContract(ctr *wallet.Contract) *Item {
 	h := ctr.ScriptHash()
 	if item, ok := c.Items[h]; ok {
@@ -178,10 +185,12 @@ func (c *ParameterContext) UnmarshalJSON(data []byte) error {
 
 	var verif io.Serializable
 	switch pc.Type {
-	case "Neo.Core.ContractTransaction":
+	case "Neo.Core.ContractTransaction", "Neo.Core.InvocationTransaction":
 		verif = new(transaction.Transaction)
+	case wallet.MessageType:
+		verif = new(wallet.Message)
 	default:
-		return fmt.Errorf("unsupported type: %s", c.Type)
+		return fmt.Errorf("unsupported type: %s", pc.Type)
 	}
 	br := io.NewBinReaderFromBuf(data)
 	verif.DecodeBinary(br)
@@ -138,6 +138,40 @@ func TestParameterContext_MarshalJSON(t *testing.T) {
 	}
 
 	testserdes.MarshalUnmarshalJSON(t, expected, new(ParameterContext))
+
+	t.Run("message", func(t *testing.T) {
+		msg := &wallet.Message{Text: "hello", Salt: "0102"}
+		expected.Type = wallet.MessageType
+		expected.Verifiable = msg
+		testserdes.MarshalUnmarshalJSON(t, expected, new(ParameterContext))
+	})
+}
+
+func TestParameterContext_AddContract(t *testing.T) {
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	pub := priv.PublicKey()
+	ctr := &wallet.Contract{
+		Script:     pub.GetVerificationScript(),
+		Parameters: []wallet.ContractParam{newParam(smartcontract.SignatureType, "parameter0")},
+	}
+
+	tx := getContractTx()
+	c := NewParameterContext("Neo.Core.ContractTransaction", tx)
+	c.AddContract(ctr)
+	item := c.Items[ctr.ScriptHash()]
+	require.NotNil(t, item)
+	require.Nil(t, item.Parameters[0].Value)
+	_, err = c.GetWitness(ctr)
+	require.Error(t, err)
+
+	actual := new(ParameterContext)
+	testserdes.MarshalUnmarshalJSON(t, c, actual)
+
+	sig := priv.Sign(tx.GetSignedPart())
+	require.NoError(t, actual.AddSignature(ctr, pub, sig))
+	_, err = actual.GetWitness(ctr)
+	require.NoError(t, err)
 }
 
 func getPrivateKeys(t *testing.T, n int) ([]*keys.PrivateKey, []*keys.PublicKey) {
@@ -116,6 +116,10 @@ func (p *Parameter) UnmarshalJSON(data []byte) (err error) {
 		}
 		p.Value = boolean
 	case ByteArrayType, PublicKeyType, SignatureType:
+		if string(r.Value) == "null" {
+			p.Value = nil
+			return
+		}
 		if err = json.Unmarshal(r.Value, &s); err != nil {
 			return
 		}
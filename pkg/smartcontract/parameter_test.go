@@ -176,6 +176,10 @@ var unmarshalJSONTestCases = []struct {
 		input:  `{"type":"ByteArray","value":"010203"}`,
 		result: Parameter{Type: ByteArrayType, Value: []byte{0x01, 0x02, 0x03}},
 	},
+	{
+		input:  `{"type":"Signature","value":null}`,
+		result: Parameter{Type: SignatureType},
+	},
 	{
 		input:  `{"type":"String","value":"Some string"}`,
 		result: Parameter{Type: StringType, Value: "Some string"},
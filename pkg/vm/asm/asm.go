@@ -0,0 +1,381 @@
+/*
+Package asm implements NeoVM assembly language.
+
+Assembly program consists of lines containing labels, instructions and
+comments:
+
+	; Comments start with a semicolon and last till the end of the line.
+	start:                          ; labels end with a colon
+		PUSHBYTES3 "abc"            ; data can be a quoted Go string...
+		PUSHBYTES 0x010203          ; ...or a hex literal, length is implied
+		SYSCALL "Neo.Storage.Get"
+		JMPIFNOT start              ; jump targets are labels...
+		JMP 3                       ; ...or relative offsets
+
+Instructions are written with opcode names (as returned by opcode.Opcode
+String method) followed by their operands:
+  - PUSHBYTES1..PUSHBYTES75, PUSHDATA1, PUSHDATA2, PUSHDATA4, SYSCALL, APPCALL
+    and TAILCALL take data, PUSHBYTES without length takes data of any length
+    up to 75 bytes;
+  - JMP, JMPIF, JMPIFNOT and CALL take jump target;
+  - CALLI takes return values count, parameters count and jump target;
+  - CALLE and CALLET take return values count, parameters count and script
+    hash data;
+  - CALLED and CALLEDT take return values count and parameters count.
+
+Arbitrary bytes can be inserted with the .bytes directive taking data.
+*/
+package asm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	nio "github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// bytesDirective is used to insert raw data into the program.
+const bytesDirective = ".bytes"
+
+// instruction is a single parsed assembly instruction.
+type instruction struct {
+	line   int
+	op     opcode.Opcode
+	raw    bool
+	param  []byte
+	label  string
+	target int
+	offset int
+}
+
+// Assemble compiles assembly program read from r into NeoVM bytecode.
+func Assemble(r io.Reader) ([]byte, error) {
+	var (
+		instrs []*instruction
+		labels = make(map[string]int)
+		offset int
+		scan   = bufio.NewScanner(r)
+	)
+	for n := 1; scan.Scan(); n++ {
+		fields, err := splitLine(scan.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		for len(fields) != 0 && strings.HasSuffix(fields[0], ":") {
+			name := strings.TrimSuffix(fields[0], ":")
+			if !isLabel(name) {
+				return nil, fmt.Errorf("line %d: bad label name %q", n, name)
+			}
+			if _, ok := labels[name]; ok {
+				return nil, fmt.Errorf("line %d: duplicate label %s", n, name)
+			}
+			labels[name] = offset
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		instr, err := parseInstruction(fields[0], fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		instr.line = n
+		instr.offset = offset
+		instrs = append(instrs, instr)
+		offset += instr.size()
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+
+	buf := nio.NewBufBinWriter()
+	for _, instr := range instrs {
+		if instr.raw {
+			buf.WriteBytes(instr.param)
+			continue
+		}
+		if !isJump(instr.op) {
+			emit.Instruction(buf.BinWriter, instr.op, instr.param)
+			continue
+		}
+		target := instr.target
+		if instr.label != "" {
+			pos, ok := labels[instr.label]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown label %s", instr.line, instr.label)
+			}
+			target = pos - instr.offset
+			if instr.op == opcode.CALLI {
+				target -= 2
+			}
+			if target < math.MinInt16 || target > math.MaxInt16 {
+				return nil, fmt.Errorf("line %d: label %s is too far", instr.line, instr.label)
+			}
+		}
+		param := append(instr.param, 0, 0)
+		binary.LittleEndian.PutUint16(param[len(param)-2:], uint16(int16(target)))
+		emit.Instruction(buf.BinWriter, instr.op, param)
+	}
+	if buf.Err != nil {
+		return nil, buf.Err
+	}
+	return buf.Bytes(), nil
+}
+
+// size returns the length of encoded instruction in bytes.
+func (i *instruction) size() int {
+	if i.raw {
+		return len(i.param)
+	}
+	n := 1 + len(i.param)
+	if isJump(i.op) {
+		n += 2
+	}
+	return n
+}
+
+// parseInstruction parses instruction with the given name and operands.
+func parseInstruction(name string, args []string) (*instruction, error) {
+	instr := new(instruction)
+	if name == bytesDirective {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s expects 1 operand", name)
+		}
+		data, err := parseData(args[0])
+		if err != nil {
+			return nil, err
+		}
+		instr.raw = true
+		instr.param = data
+		return instr, nil
+	}
+
+	var op opcode.Opcode
+	if name == "PUSHBYTES" {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s expects 1 operand", name)
+		}
+		data, err := parseData(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 || len(data) > int(opcode.PUSHBYTES75) {
+			return nil, fmt.Errorf("bad %s data length %d", name, len(data))
+		}
+		op = opcode.Opcode(len(data))
+	} else {
+		var err error
+		op, err = opcode.FromString(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	instr.op = op
+
+	var err error
+	switch {
+	case op >= opcode.PUSHBYTES1 && op <= opcode.PUSHBYTES75:
+		if err = checkOperands(op, args, 1); err == nil {
+			instr.param, err = parseDataLen(args[0], int(op))
+		}
+	case op == opcode.PUSHDATA1 || op == opcode.PUSHDATA2 || op == opcode.PUSHDATA4 || op == opcode.SYSCALL:
+		var data []byte
+		if err = checkOperands(op, args, 1); err == nil {
+			data, err = parseData(args[0])
+		}
+		if err == nil {
+			instr.param, err = withLength(op, data)
+		}
+	case op == opcode.APPCALL || op == opcode.TAILCALL:
+		if err = checkOperands(op, args, 1); err == nil {
+			instr.param, err = parseDataLen(args[0], 20)
+		}
+	case op == opcode.JMP || op == opcode.JMPIF || op == opcode.JMPIFNOT || op == opcode.CALL:
+		if err = checkOperands(op, args, 1); err == nil {
+			err = instr.parseTarget(args[0])
+		}
+	case op == opcode.CALLI:
+		if err = checkOperands(op, args, 3); err == nil {
+			instr.param, err = parseCounts(args[:2])
+		}
+		if err == nil {
+			err = instr.parseTarget(args[2])
+		}
+	case op == opcode.CALLE || op == opcode.CALLET:
+		var hash []byte
+		if err = checkOperands(op, args, 3); err == nil {
+			instr.param, err = parseCounts(args[:2])
+		}
+		if err == nil {
+			hash, err = parseDataLen(args[2], 20)
+			instr.param = append(instr.param, hash...)
+		}
+	case op == opcode.CALLED || op == opcode.CALLEDT:
+		if err = checkOperands(op, args, 2); err == nil {
+			instr.param, err = parseCounts(args)
+		}
+	default:
+		err = checkOperands(op, args, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return instr, nil
+}
+
+// parseTarget parses jump target which is either a label or a relative
+// offset.
+func (i *instruction) parseTarget(s string) error {
+	if isLabel(s) {
+		i.label = s
+		return nil
+	}
+	n, err := strconv.ParseInt(s, 10, 16)
+	if err != nil {
+		return fmt.Errorf("bad jump target %s", s)
+	}
+	i.target = int(n)
+	return nil
+}
+
+// isJump returns true for instructions ending with a 2-byte jump offset.
+func isJump(op opcode.Opcode) bool {
+	switch op {
+	case opcode.JMP, opcode.JMPIF, opcode.JMPIFNOT, opcode.CALL, opcode.CALLI:
+		return true
+	}
+	return false
+}
+
+func checkOperands(op opcode.Opcode, args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("%s expects %d operand(s), got %d", op, n, len(args))
+	}
+	return nil
+}
+
+// withLength prepends data with its length encoded the way op expects.
+func withLength(op opcode.Opcode, data []byte) ([]byte, error) {
+	var prefix []byte
+	switch op {
+	case opcode.PUSHDATA1, opcode.SYSCALL:
+		if len(data) > math.MaxUint8 {
+			return nil, fmt.Errorf("%s data is too long", op)
+		}
+		prefix = []byte{byte(len(data))}
+	case opcode.PUSHDATA2:
+		if len(data) > math.MaxUint16 {
+			return nil, fmt.Errorf("%s data is too long", op)
+		}
+		prefix = make([]byte, 2)
+		binary.LittleEndian.PutUint16(prefix, uint16(len(data)))
+	case opcode.PUSHDATA4:
+		prefix = make([]byte, 4)
+		binary.LittleEndian.PutUint32(prefix, uint32(len(data)))
+	}
+	return append(prefix, data...), nil
+}
+
+// parseCounts parses return values and parameters counts of CALL* opcodes.
+func parseCounts(args []string) ([]byte, error) {
+	res := make([]byte, len(args))
+	for i := range args {
+		n, err := strconv.ParseUint(args[i], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("bad count %s", args[i])
+		}
+		res[i] = byte(n)
+	}
+	return res, nil
+}
+
+// parseData parses either quoted string or 0x-prefixed hex data.
+func parseData(s string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		str, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("bad string %s", s)
+		}
+		return []byte(str), nil
+	case strings.HasPrefix(s, "0x"):
+		data, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return nil, fmt.Errorf("bad hex data %s", s)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("bad data %s, quoted string or 0x-prefixed hex expected", s)
+	}
+}
+
+// parseDataLen parses data of exactly n bytes.
+func parseDataLen(s string, n int) ([]byte, error) {
+	data, err := parseData(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != n {
+		return nil, fmt.Errorf("expected %d bytes of data, got %d", n, len(data))
+	}
+	return data, nil
+}
+
+// isLabel checks whether s is a valid label name.
+func isLabel(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case i != 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// splitLine splits the line into whitespace-separated fields dropping the
+// comment, quoted strings are kept intact.
+func splitLine(line string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == ';':
+			return fields, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(line) && line[j] != '"'; j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(line) {
+				return nil, errors.New("unterminated string")
+			}
+			fields = append(fields, line[i:j+1])
+			i = j + 1
+		default:
+			j := strings.IndexAny(line[i:], " \t\r;\"")
+			if j < 0 {
+				j = len(line) - i
+			}
+			fields = append(fields, line[i:i+j])
+			i += j
+		}
+	}
+	return fields, nil
+}
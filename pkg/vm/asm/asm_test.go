@@ -0,0 +1,131 @@
+package asm
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssemble(t *testing.T) {
+	src := `; test program
+	start:
+		PUSHBYTES3 "abc"   ; explicit length
+		PUSHBYTES 0x0102
+		PUSHDATA1 "; not a comment"
+		SYSCALL "Neo.Storage.Get"
+		JMPIFNOT end
+		CALLI 1 2 start
+		JMP -3
+	end: RET`
+	prog, err := Assemble(strings.NewReader(src))
+	require.NoError(t, err)
+
+	w := io.NewBufBinWriter()
+	emit.Bytes(w.BinWriter, []byte("abc"))
+	emit.Bytes(w.BinWriter, []byte{1, 2})
+	emit.Instruction(w.BinWriter, opcode.PUSHDATA1, append([]byte{15}, "; not a comment"...))
+	emit.Syscall(w.BinWriter, "Neo.Storage.Get")
+	emit.Jmp(w.BinWriter, opcode.JMPIFNOT, 11)
+	emit.Instruction(w.BinWriter, opcode.CALLI, []byte{1, 2, 0xd2, 0xff}) // -46
+	emit.Jmp(w.BinWriter, opcode.JMP, 0xfffd)
+	emit.Opcode(w.BinWriter, opcode.RET)
+	require.NoError(t, w.Err)
+	require.Equal(t, w.Bytes(), prog)
+}
+
+func TestAssembleErrors(t *testing.T) {
+	testCases := map[string]string{
+		"unknown opcode":       "PUSHX",
+		"missing operand":      "SYSCALL",
+		"extra operand":        "ADD 1",
+		"wrong length":         `PUSHBYTES2 "abc"`,
+		"bad hex":              "PUSHBYTES1 0xzz",
+		"bad data":             "PUSHBYTES1 1",
+		"unterminated string":  `SYSCALL "Neo.Runtime.Log`,
+		"unknown label":        "JMP nowhere",
+		"duplicate label":      "a: NOP\na: NOP",
+		"bad label":            "1a: NOP",
+		"bad count":            "CALLED 256 1",
+		"bad script hash":      "APPCALL 0x0102",
+		"empty PUSHBYTES data": `PUSHBYTES ""`,
+	}
+	for name, src := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Assemble(strings.NewReader(src))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	w := io.NewBufBinWriter()
+	emit.Jmp(w.BinWriter, opcode.JMP, 3)
+	emit.Bytes(w.BinWriter, []byte{1, 2, 3})
+	emit.Syscall(w.BinWriter, "Neo.Runtime.Log")
+	emit.AppCall(w.BinWriter, util.Uint160{1, 2, 3}, false)
+	emit.Jmp(w.BinWriter, opcode.JMPIF, 2)
+	require.NoError(t, w.Err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, Disassemble(buf, w.Bytes()))
+	expected := []string{
+		"JMP L3",
+		"L3:",
+		"PUSHBYTES3 0x010203",
+		`SYSCALL "Neo.Runtime.Log"`,
+		"APPCALL 0x",
+		"JMPIF 2",
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Equal(t, len(expected), len(lines))
+	for i := range expected {
+		require.True(t, strings.HasPrefix(strings.TrimSpace(lines[i]), expected[i]), lines[i])
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	check := func(t *testing.T, prog []byte) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, Disassemble(buf, prog))
+		actual, err := Assemble(buf)
+		require.NoError(t, err)
+		require.Equal(t, prog, actual)
+	}
+
+	t.Run("contract", func(t *testing.T) {
+		src := `package foo
+		import "github.com/nspcc-dev/neo-go/pkg/interop/storage"
+		func Main(op string, args []interface{}) interface{} {
+			ctx := storage.GetContext()
+			if op == "put" {
+				storage.Put(ctx, args[0].([]byte), args[1].([]byte))
+				return true
+			}
+			for i := 0; i < len(args); i++ {
+				storage.Delete(ctx, args[i].([]byte))
+			}
+			return storage.Get(ctx, op)
+		}`
+		prog, err := compiler.Compile(strings.NewReader(src))
+		require.NoError(t, err)
+		check(t, prog)
+	})
+	t.Run("random", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			prog := make([]byte, 1+rand.Intn(200))
+			rand.Read(prog)
+			check(t, prog)
+		}
+	})
+	t.Run("truncated", func(t *testing.T) {
+		check(t, []byte{byte(opcode.ADD), byte(opcode.PUSHBYTES10), 1, 2})
+	})
+}
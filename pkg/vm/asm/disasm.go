@@ -0,0 +1,150 @@
+package asm
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// decoded is a single instruction decoded from the program.
+type decoded struct {
+	offset int
+	op     opcode.Opcode
+	param  []byte
+	// raw is set for bytes that are not a valid instruction.
+	raw []byte
+}
+
+// Disassemble writes assembly listing of prog to w. The listing can be
+// assembled back to exactly the same bytecode, jump targets are replaced
+// with labels and every instruction is commented with its offset.
+func Disassemble(w io.Writer, prog []byte) error {
+	instrs := decode(prog)
+	starts := make(map[int]bool, len(instrs)+1)
+	for _, instr := range instrs {
+		starts[instr.offset] = true
+	}
+	starts[len(prog)] = true
+
+	labels := make(map[int]bool)
+	for _, instr := range instrs {
+		if target, ok := instr.target(); ok && starts[target] {
+			labels[target] = true
+		}
+	}
+
+	for _, instr := range instrs {
+		if labels[instr.offset] {
+			if _, err := fmt.Fprintf(w, "%s:\n", labelName(instr.offset)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "\t%-40s ; %d\n", instr.format(labels), instr.offset); err != nil {
+			return err
+		}
+	}
+	if labels[len(prog)] {
+		if _, err := fmt.Fprintf(w, "%s:\n", labelName(len(prog))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decode splits the program into instructions.
+func decode(prog []byte) []decoded {
+	var (
+		res []decoded
+		ctx = vm.NewContext(prog)
+	)
+	for ctx.NextIP() < len(prog) {
+		start := ctx.NextIP()
+		op, param, err := ctx.Next()
+		if err != nil {
+			res = append(res, decoded{offset: start, raw: prog[start:]})
+			break
+		}
+		if strings.HasPrefix(op.String(), "Opcode(") {
+			res = append(res, decoded{offset: start, raw: prog[start:ctx.NextIP()]})
+			continue
+		}
+		res = append(res, decoded{offset: start, op: op, param: param})
+	}
+	return res
+}
+
+// target returns absolute jump target of the instruction if it has one.
+func (d *decoded) target() (int, bool) {
+	if d.raw != nil || !isJump(d.op) {
+		return 0, false
+	}
+	off := int16(binary.LittleEndian.Uint16(d.param[len(d.param)-2:]))
+	target := d.offset + int(off)
+	if d.op == opcode.CALLI {
+		target += 2
+	}
+	return target, true
+}
+
+// format returns textual representation of the instruction.
+func (d *decoded) format(labels map[int]bool) string {
+	if d.raw != nil {
+		return bytesDirective + " " + formatData(d.raw)
+	}
+	op := d.op.String()
+	switch {
+	case d.op >= opcode.PUSHBYTES1 && d.op <= opcode.PUSHBYTES75,
+		d.op == opcode.PUSHDATA1, d.op == opcode.PUSHDATA2, d.op == opcode.PUSHDATA4,
+		d.op == opcode.SYSCALL:
+		return op + " " + formatData(d.param)
+	case d.op == opcode.APPCALL || d.op == opcode.TAILCALL:
+		return op + " 0x" + hex.EncodeToString(d.param)
+	case d.op == opcode.CALLE || d.op == opcode.CALLET:
+		return fmt.Sprintf("%s %d %d 0x%s", op, d.param[0], d.param[1], hex.EncodeToString(d.param[2:]))
+	case d.op == opcode.CALLED || d.op == opcode.CALLEDT:
+		return fmt.Sprintf("%s %d %d", op, d.param[0], d.param[1])
+	case isJump(d.op):
+		target, _ := d.target()
+		arg := labelName(target)
+		if !labels[target] {
+			arg = strconv.Itoa(int(int16(binary.LittleEndian.Uint16(d.param[len(d.param)-2:]))))
+		}
+		if d.op == opcode.CALLI {
+			return fmt.Sprintf("%s %d %d %s", op, d.param[0], d.param[1], arg)
+		}
+		return op + " " + arg
+	default:
+		return op
+	}
+}
+
+// labelName returns the name of the label pointing to the given offset.
+func labelName(offset int) string {
+	return "L" + strconv.Itoa(offset)
+}
+
+// formatData returns data as a quoted string if it's printable and as hex
+// otherwise.
+func formatData(data []byte) string {
+	if len(data) != 0 && utf8.Valid(data) {
+		printable := true
+		for _, r := range string(data) {
+			if !unicode.IsPrint(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return strconv.Quote(string(data))
+		}
+	}
+	return "0x" + hex.EncodeToString(data)
+}
@@ -87,6 +87,22 @@ var commands = []*ishell.Cmd{
 > load /path/to/file.go`,
 		Func: handleLoadGo,
 	},
+	{
+		Name: "save",
+		Help: "Save the VM execution state to a file",
+		LongHelp: `Usage: save <file>
+<file> is mandatory parameter, example:
+> save /path/to/state.bin`,
+		Func: handleSave,
+	},
+	{
+		Name: "load-state",
+		Help: "Load the VM execution state saved by 'save' command",
+		LongHelp: `Usage: load-state <file>
+<file> is mandatory parameter, example:
+> load-state /path/to/state.bin`,
+		Func: handleLoadState,
+	},
 	{
 		Name: "run",
 		Help: "Execute the current loaded script",
@@ -270,6 +286,46 @@ func handleLoadGo(c *ishell.Context) {
 	changePrompt(c, v)
 }
 
+func handleSave(c *ishell.Context) {
+	if !checkVMIsReady(c) {
+		return
+	}
+	if len(c.Args) != 1 {
+		c.Err(errors.New("missing parameter <file>"))
+		return
+	}
+	v := getVMFromContext(c)
+	data, err := v.Snapshot()
+	if err != nil {
+		c.Err(err)
+		return
+	}
+	if err := ioutil.WriteFile(c.Args[0], data, 0644); err != nil {
+		c.Err(err)
+		return
+	}
+	c.Printf("VM state saved to %s\n", c.Args[0])
+}
+
+func handleLoadState(c *ishell.Context) {
+	if len(c.Args) != 1 {
+		c.Err(errors.New("missing parameter <file>"))
+		return
+	}
+	v := getVMFromContext(c)
+	data, err := ioutil.ReadFile(c.Args[0])
+	if err != nil {
+		c.Err(err)
+		return
+	}
+	if err := v.Restore(data); err != nil {
+		c.Err(err)
+		return
+	}
+	c.Printf("READY: state loaded, invocation stack depth %d\n", v.Istack().Len())
+	changePrompt(c, v)
+}
+
 func handleRun(c *ishell.Context) {
 	v := getVMFromContext(c)
 	if len(c.Args) != 0 {
@@ -21,6 +21,12 @@ type Context struct {
 	// The raw program script.
 	prog []byte
 
+	// Pre-decoded program, nil if the context is executed from raw script.
+	code *Program
+
+	// Current pre-decoded instruction, nil if it was decoded from raw script.
+	instr *instruction
+
 	// Breakpoints.
 	breakPoints []int
 
@@ -62,6 +68,7 @@ func (c *Context) NextIP() int {
 func (c *Context) Next() (opcode.Opcode, []byte, error) {
 	var err error
 
+	c.instr = nil
 	c.ip = c.nextip
 	if c.ip >= len(c.prog) {
 		return opcode.RET, nil, nil
@@ -126,6 +133,27 @@ func (c *Context) Next() (opcode.Opcode, []byte, error) {
 	return instr, parameter, nil
 }
 
+// next is the same as Next, but it takes instructions from the pre-decoded
+// program if possible. Parameters of such instructions are shared with the
+// program, so they're copied for data pushes as pushed items can be changed.
+func (c *Context) next() (opcode.Opcode, []byte, error) {
+	if c.code != nil && c.nextip < len(c.code.index) {
+		if i := c.code.index[c.nextip]; i >= 0 {
+			in := &c.code.instrs[i]
+			c.instr = in
+			c.ip = c.nextip
+			c.nextip = in.next
+			param := in.param
+			if param != nil && isDataPush(in.op) {
+				param = make([]byte, len(in.param))
+				copy(param, in.param)
+			}
+			return in.op, param, in.err
+		}
+	}
+	return c.Next()
+}
+
 // IP returns the absolute instruction without taking 0 into account.
 // If that program starts the ip = 0 but IP() will return 1, cause its
 // the first instruction.
@@ -38,22 +38,34 @@ func IsMultiSigContract(script []byte) bool {
 // ParseMultiSigContract returns list of public keys from the verification
 // script of the contract.
 func ParseMultiSigContract(script []byte) ([][]byte, bool) {
+	_, pubs, ok := parseMultiSigContract(script)
+	return pubs, ok
+}
+
+// GetMultiSigThreshold returns the number of signatures required by the
+// multi-signature contract.
+func GetMultiSigThreshold(script []byte) (int, bool) {
+	nsigs, _, ok := parseMultiSigContract(script)
+	return nsigs, ok
+}
+
+func parseMultiSigContract(script []byte) (int, [][]byte, bool) {
 	var nsigs, nkeys int
 
 	ctx := NewContext(script)
 	instr, param, err := ctx.Next()
 	if err != nil {
-		return nil, false
+		return 0, nil, false
 	}
 	nsigs, ok := getNumOfThingsFromInstr(instr, param)
 	if !ok {
-		return nil, false
+		return 0, nil, false
 	}
 	var pubs [][]byte
 	for {
 		instr, param, err = ctx.Next()
 		if err != nil {
-			return nil, false
+			return 0, nil, false
 		}
 		if instr != opcode.PUSHBYTES33 {
 			break
@@ -61,28 +73,28 @@ func ParseMultiSigContract(script []byte) ([][]byte, bool) {
 		pubs = append(pubs, param)
 		nkeys++
 		if nkeys > MaxArraySize {
-			return nil, false
+			return 0, nil, false
 		}
 	}
 	if nkeys < nsigs {
-		return nil, false
+		return 0, nil, false
 	}
 	nkeys2, ok := getNumOfThingsFromInstr(instr, param)
 	if !ok {
-		return nil, false
+		return 0, nil, false
 	}
 	if nkeys2 != nkeys {
-		return nil, false
+		return 0, nil, false
 	}
 	instr, _, err = ctx.Next()
 	if err != nil || instr != opcode.CHECKMULTISIG {
-		return nil, false
+		return 0, nil, false
 	}
 	instr, _, err = ctx.Next()
 	if err != nil || instr != opcode.RET || ctx.ip != len(script) {
-		return nil, false
+		return 0, nil, false
 	}
-	return pubs, true
+	return nsigs, pubs, true
 }
 
 // IsSignatureContract checks whether the passed script is a signature check
@@ -226,3 +226,19 @@ func TestIsMultiSigContractBadRead3(t *testing.T) {
 	prog[70] = byte(opcode.PUSHBYTES1)
 	assert.Equal(t, false, IsMultiSigContract(prog))
 }
+
+func TestGetMultiSigThreshold(t *testing.T) {
+	prog := make([]byte, 105)
+	prog[0] = byte(opcode.PUSH2)
+	prog[1] = byte(opcode.PUSHBYTES33)
+	prog[35] = byte(opcode.PUSHBYTES33)
+	prog[69] = byte(opcode.PUSHBYTES33)
+	prog[103] = byte(opcode.PUSH3)
+	prog[104] = byte(opcode.CHECKMULTISIG)
+	n, ok := GetMultiSigThreshold(prog)
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+
+	_, ok = GetMultiSigThreshold(prog[:104])
+	assert.False(t, ok)
+}
@@ -0,0 +1,23 @@
+package opcode
+
+import "fmt"
+
+// stringToOpcode maps opcode names (including aliases) to opcodes.
+var stringToOpcode = map[string]Opcode{
+	"PUSHF": PUSHF,
+	"PUSHT": PUSHT,
+}
+
+func init() {
+	for op, s := range _Opcode_map {
+		stringToOpcode[s] = op
+	}
+}
+
+// FromString converts opcode name (as returned by String) to Opcode.
+func FromString(s string) (Opcode, error) {
+	if op, ok := stringToOpcode[s]; ok {
+		return op, nil
+	}
+	return 0, fmt.Errorf("unknown opcode %s", s)
+}
@@ -18,3 +18,18 @@ func TestStringer(t *testing.T) {
 		assert.Equal(t, s, o.String())
 	}
 }
+
+func TestFromString(t *testing.T) {
+	for _, op := range []Opcode{ADD, PUSH0, PUSHBYTES75, SYSCALL, THROWIFNOT} {
+		actual, err := FromString(op.String())
+		assert.NoError(t, err)
+		assert.Equal(t, op, actual)
+	}
+	op, err := FromString("PUSHT")
+	assert.NoError(t, err)
+	assert.Equal(t, PUSH1, op)
+	_, err = FromString("Opcode(255)")
+	assert.Error(t, err)
+	_, err = FromString("add")
+	assert.Error(t, err)
+}
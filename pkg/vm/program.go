@@ -0,0 +1,149 @@
+package vm
+
+import (
+	"container/list"
+	"encoding/binary"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+)
+
+// Program is a script decoded into instructions once so that it doesn't need
+// to be decoded again on every execution. It's immutable and can be shared
+// between VMs.
+type Program struct {
+	script []byte
+	instrs []instruction
+	// index maps script offsets to instruction indexes, offsets which are
+	// not instruction boundaries are marked with -1 and decoded on the fly
+	// if jumped to.
+	index []int32
+}
+
+// instruction is a decoded program instruction.
+type instruction struct {
+	op    opcode.Opcode
+	param []byte
+	err   error
+	// next is the offset of the next instruction.
+	next int
+	// target is the resolved offset for JMP, JMPIF, JMPIFNOT, CALL and
+	// CALLI instructions.
+	target int
+}
+
+// NewProgram decodes the script given.
+func NewProgram(script []byte) *Program {
+	p := &Program{
+		script: script,
+		index:  make([]int32, len(script)),
+	}
+	for i := range p.index {
+		p.index[i] = -1
+	}
+	ctx := NewContext(script)
+	for ctx.nextip < len(script) {
+		start := ctx.nextip
+		op, param, err := ctx.Next()
+		in := instruction{op: op, param: param, err: err, next: ctx.nextip}
+		if err == nil {
+			switch op {
+			case opcode.JMP, opcode.JMPIF, opcode.JMPIFNOT, opcode.CALL:
+				in.target = start + int(int16(binary.LittleEndian.Uint16(param)))
+			case opcode.CALLI:
+				in.target = start + int(int16(binary.LittleEndian.Uint16(param[2:]))) + 2
+			}
+		}
+		p.index[start] = int32(len(p.instrs))
+		p.instrs = append(p.instrs, in)
+		if err != nil {
+			break
+		}
+	}
+	return p
+}
+
+// Script returns the script of the program.
+func (p *Program) Script() []byte {
+	return p.script
+}
+
+// isDataPush returns true for instructions pushing their parameter to the
+// stack.
+func isDataPush(op opcode.Opcode) bool {
+	return op >= opcode.PUSHBYTES1 && op <= opcode.PUSHBYTES75 ||
+		op == opcode.PUSHDATA1 || op == opcode.PUSHDATA2 || op == opcode.PUSHDATA4
+}
+
+// ProgramCache is a bounded LRU cache of programs indexed by script hashes,
+// it's safe for concurrent use.
+type ProgramCache struct {
+	lock   sync.Mutex
+	maxCap int
+	elems  map[util.Uint160]*list.Element
+	queue  *list.List
+}
+
+// programEntry is an element of ProgramCache queue.
+type programEntry struct {
+	hash util.Uint160
+	prog *Program
+}
+
+// NewProgramCache returns a new cache holding up to capacity programs.
+func NewProgramCache(capacity int) *ProgramCache {
+	return &ProgramCache{
+		maxCap: capacity,
+		elems:  make(map[util.Uint160]*list.Element),
+		queue:  list.New(),
+	}
+}
+
+// Get returns the program for the given script hash decoding and caching
+// the script if it's not yet cached. Script hash must match the script.
+func (c *ProgramCache) Get(hash util.Uint160, script []byte) *Program {
+	c.lock.Lock()
+	if e, ok := c.elems[hash]; ok {
+		c.queue.MoveToFront(e)
+		c.lock.Unlock()
+		return e.Value.(*programEntry).prog
+	}
+	c.lock.Unlock()
+
+	// Decoding is done without the lock, concurrent Gets of the same
+	// script can decode it twice, but the result is the same anyway.
+	p := NewProgram(script)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.elems[hash]; ok || c.maxCap <= 0 {
+		return p
+	}
+	if c.queue.Len() >= c.maxCap {
+		last := c.queue.Back()
+		c.queue.Remove(last)
+		delete(c.elems, last.Value.(*programEntry).hash)
+	}
+	c.elems[hash] = c.queue.PushFront(&programEntry{hash: hash, prog: p})
+	return p
+}
+
+// Delete drops the program from the cache.
+func (c *ProgramCache) Delete(hash util.Uint160) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.elems[hash]; ok {
+		c.queue.Remove(e)
+		delete(c.elems, hash)
+	}
+}
+
+// Len returns the number of cached programs.
+func (c *ProgramCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.queue.Len()
+}
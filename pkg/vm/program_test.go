@@ -0,0 +1,209 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopProgram counts from 0 to 1000 using jumps.
+var loopProgram = []byte{
+	byte(opcode.PUSH0),
+	byte(opcode.DUP), // 1
+	byte(opcode.PUSHBYTES2), 0xe8, 0x03,
+	byte(opcode.LT),
+	byte(opcode.JMPIFNOT), 7, 0, // 6 -> 13
+	byte(opcode.INC),
+	byte(opcode.JMP), 0xf7, 0xff, // 10 -> 1
+	byte(opcode.RET), // 13
+}
+
+// loadRaw loads the program without pre-decoding it.
+func loadRaw(prog []byte) *VM {
+	v := load(prog)
+	v.Context().code = nil
+	return v
+}
+
+func TestNewProgram(t *testing.T) {
+	p := NewProgram(loopProgram)
+	require.Equal(t, 8, len(p.instrs))
+	require.Equal(t, int32(-1), p.index[3])
+	in := p.instrs[p.index[6]]
+	require.Equal(t, opcode.JMPIFNOT, in.op)
+	require.Equal(t, 13, in.target)
+	require.Equal(t, 9, in.next)
+	require.Equal(t, 1, p.instrs[p.index[10]].target)
+
+	t.Run("CALLI", func(t *testing.T) {
+		p := NewProgram([]byte{byte(opcode.CALLI), 1, 2, 0xfe, 0xff})
+		require.Equal(t, 0, p.instrs[0].target)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		p := NewProgram([]byte{byte(opcode.PUSH1), byte(opcode.JMP), 1})
+		require.Equal(t, 2, len(p.instrs))
+		require.Error(t, p.instrs[1].err)
+	})
+}
+
+func TestProgramExecution(t *testing.T) {
+	testCases := map[string]struct {
+		prog   []byte
+		result int64
+	}{
+		"loop": {loopProgram, 1000},
+		// Jump into PUSHBYTES2 parameter to execute PUSH1 RET from it.
+		"jump inside instruction": {[]byte{
+			byte(opcode.JMP), 4, 0,
+			byte(opcode.PUSHBYTES2), byte(opcode.PUSH1), byte(opcode.RET),
+		}, 1},
+		"call": {[]byte{
+			byte(opcode.CALL), 5, 0,
+			byte(opcode.INC),
+			byte(opcode.RET),
+			byte(opcode.PUSH2), // 5
+			byte(opcode.RET),
+		}, 3},
+		"CALLI": {[]byte{
+			byte(opcode.PUSH3),
+			byte(opcode.CALLI), 1, 1, 5, 0, // 1 -> 8
+			byte(opcode.INC),
+			byte(opcode.RET),
+			byte(opcode.DEC), // 8
+			byte(opcode.RET),
+		}, 3},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, v := range []*VM{load(tc.prog), loadRaw(tc.prog)} {
+				runVM(t, v)
+				assert.Equal(t, 1, v.estack.Len())
+				assert.Equal(t, tc.result, v.estack.Pop().BigInt().Int64())
+			}
+		})
+	}
+
+	t.Run("bad jump", func(t *testing.T) {
+		prog := []byte{byte(opcode.JMP), 0xf0, 0xff}
+		checkVMFailed(t, load(prog))
+		checkVMFailed(t, loadRaw(prog))
+	})
+}
+
+func TestProgramParamsNotChanged(t *testing.T) {
+	// LEFT result shares memory with its argument, so CAT can overwrite it.
+	prog := []byte{
+		byte(opcode.PUSHBYTES3), 'a', 'b', 'c',
+		byte(opcode.PUSH1),
+		byte(opcode.LEFT),
+		byte(opcode.PUSHBYTES1), 'x',
+		byte(opcode.CAT),
+		byte(opcode.RET),
+	}
+	p := NewProgram(prog)
+	for i := 0; i < 2; i++ {
+		v := New()
+		v.loadProgram(p)
+		runVM(t, v)
+		require.Equal(t, []byte("ax"), v.estack.Pop().Bytes())
+	}
+	require.Equal(t, []byte("abc"), p.instrs[0].param)
+}
+
+func TestProgramCache(t *testing.T) {
+	c := NewProgramCache(2)
+	s1, s2, s3 := []byte{1}, []byte{2}, []byte{3}
+	h1, h2, h3 := hash.Hash160(s1), hash.Hash160(s2), hash.Hash160(s3)
+
+	p1 := c.Get(h1, s1)
+	require.Equal(t, s1, p1.Script())
+	require.True(t, p1 == c.Get(h1, s1))
+	c.Get(h2, s2)
+	c.Get(h1, s1)
+	// h2 is the least recently used one.
+	c.Get(h3, s3)
+	require.Equal(t, 2, c.Len())
+	require.True(t, p1 == c.Get(h1, s1))
+
+	c.Delete(h1)
+	require.Equal(t, 1, c.Len())
+	require.False(t, p1 == c.Get(h1, s1))
+
+	t.Run("disabled", func(t *testing.T) {
+		c := NewProgramCache(0)
+		require.NotNil(t, c.Get(h1, s1))
+		require.Equal(t, 0, c.Len())
+	})
+}
+
+func TestAppCallProgramCache(t *testing.T) {
+	callee := []byte{byte(opcode.PUSH5), byte(opcode.RET)}
+	h := hash.Hash160(callee)
+	prog := []byte{byte(opcode.APPCALL)}
+	prog = append(prog, h.BytesBE()...)
+	prog = append(prog, byte(opcode.APPCALL))
+	prog = append(prog, h.BytesBE()...)
+	prog = append(prog, byte(opcode.ADD), byte(opcode.RET))
+
+	c := NewProgramCache(10)
+	v := load(prog)
+	v.SetProgramCache(c)
+	v.SetScriptGetter(func(in util.Uint160) ([]byte, bool) {
+		if in.Equals(h) {
+			return callee, false
+		}
+		return nil, false
+	})
+	runVM(t, v)
+	require.Equal(t, int64(10), v.estack.Pop().BigInt().Int64())
+	require.Equal(t, 1, c.Len())
+}
+
+func benchmarkLoop(b *testing.B, raw bool) {
+	for n := 0; n < b.N; n++ {
+		v := load(loopProgram)
+		if raw {
+			v.Context().code = nil
+		}
+		if err := v.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoop(b *testing.B) {
+	b.Run("raw", func(b *testing.B) { benchmarkLoop(b, true) })
+	b.Run("predecoded", func(b *testing.B) { benchmarkLoop(b, false) })
+}
+
+func BenchmarkAppCall(b *testing.B) {
+	callee := loopProgram[:len(loopProgram)-1]
+	callee = append(append([]byte{}, callee...), byte(opcode.DROP), byte(opcode.RET))
+	h := hash.Hash160(callee)
+	var prog []byte
+	for i := 0; i < 10; i++ {
+		prog = append(prog, byte(opcode.APPCALL))
+		prog = append(prog, h.BytesBE()...)
+	}
+	prog = append(prog, byte(opcode.RET))
+	getScript := func(in util.Uint160) ([]byte, bool) {
+		return callee, false
+	}
+
+	for name, c := range map[string]*ProgramCache{"nocache": nil, "cache": NewProgramCache(10)} {
+		b.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				v := load(prog)
+				v.SetScriptGetter(getScript)
+				v.SetProgramCache(c)
+				if err := v.Run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/emit"
+)
+
+// snapshotVersion is the version of VM snapshot format.
+const snapshotVersion = 0
+
+// interopT marks interop items in snapshots, they can't be serialized with
+// SerializeItem, so this type is not used anywhere else.
+const interopT stackItemType = 0xf0
+
+// Interop values kinds stored in snapshots.
+const (
+	interopOpaque byte = iota
+	interopArrayWrapper
+	interopMapWrapper
+	interopConcatEnum
+	interopConcatIter
+	interopKeys
+	interopValues
+)
+
+// OpaqueInterop replaces interop item values which can't be serialized
+// (like storage contexts or blockchain objects) in restored VM state. Type is
+// the Go type name of the original value.
+type OpaqueInterop struct {
+	Type string
+}
+
+// snapshotWriter assigns identifiers to stack items and stacks so that
+// shared references are stored only once.
+type snapshotWriter struct {
+	items  map[StackItem]int
+	ilist  []StackItem
+	stacks map[*Stack]int
+	slist  []*Stack
+}
+
+// Snapshot serializes the whole VM execution state (invocation, evaluation
+// and alt stacks with all of their items, breakpoints, GAS counters and
+// state). Items shared between stacks, arrays and iterators stay shared after
+// Restore, recursive structures are supported. Interop values other than VM
+// iterators are stored as OpaqueInterop. Callbacks (interop getters, script
+// getter, price getter) are not a part of the snapshot.
+func (v *VM) Snapshot() ([]byte, error) {
+	s := &snapshotWriter{
+		items:  make(map[StackItem]int),
+		stacks: make(map[*Stack]int),
+	}
+	var ctxs []*Context
+	v.istack.IterBack(func(e *Element) {
+		ctx := e.value.(*Context)
+		ctxs = append(ctxs, ctx)
+		s.addStack(ctx.estack)
+		s.addStack(ctx.astack)
+	})
+	s.addStack(v.estack)
+	s.addStack(v.astack)
+
+	w := io.NewBufBinWriter()
+	w.WriteB(snapshotVersion)
+	w.WriteB(byte(v.state))
+	w.WriteU64LE(uint64(v.gasConsumed))
+	w.WriteU64LE(uint64(v.gasLimit))
+	w.WriteVarBytes(v.checkhash)
+
+	w.WriteVarUint(uint64(len(s.ilist)))
+	for _, item := range s.ilist {
+		s.writeItem(w.BinWriter, item)
+	}
+	w.WriteVarUint(uint64(len(s.slist)))
+	for _, st := range s.slist {
+		w.WriteString(st.name)
+		w.WriteBool(st.size == &v.size)
+		w.WriteVarUint(uint64(st.Len()))
+		st.IterBack(func(e *Element) {
+			w.WriteVarUint(uint64(s.items[e.value]))
+		})
+	}
+	w.WriteVarUint(uint64(s.stacks[v.estack]))
+	w.WriteVarUint(uint64(s.stacks[v.astack]))
+
+	w.WriteVarUint(uint64(len(ctxs)))
+	for _, ctx := range ctxs {
+		w.WriteVarBytes(ctx.prog)
+		w.WriteU32LE(uint32(ctx.ip))
+		w.WriteU32LE(uint32(ctx.nextip))
+		w.WriteVarUint(uint64(len(ctx.breakPoints)))
+		for _, bp := range ctx.breakPoints {
+			w.WriteU32LE(uint32(bp))
+		}
+		w.WriteU32LE(uint32(int32(ctx.rvcount)))
+		w.WriteVarUint(uint64(s.stacks[ctx.estack]))
+		w.WriteVarUint(uint64(s.stacks[ctx.astack]))
+		w.WriteBytes(ctx.scriptHash.BytesBE())
+		w.WriteBool(ctx.hasDynamicInvoke)
+	}
+	if w.Err != nil {
+		return nil, w.Err
+	}
+	return w.Bytes(), nil
+}
+
+func (s *snapshotWriter) addStack(st *Stack) {
+	if _, ok := s.stacks[st]; ok {
+		return
+	}
+	s.stacks[st] = len(s.slist)
+	s.slist = append(s.slist, st)
+	st.IterBack(func(e *Element) {
+		s.addItem(e.value)
+	})
+}
+
+func (s *snapshotWriter) addItem(item StackItem) {
+	if _, ok := s.items[item]; ok {
+		return
+	}
+	s.items[item] = len(s.ilist)
+	s.ilist = append(s.ilist, item)
+	switch t := item.(type) {
+	case *ArrayItem:
+		for _, e := range t.value {
+			s.addItem(e)
+		}
+	case *StructItem:
+		for _, e := range t.value {
+			s.addItem(e)
+		}
+	case *MapItem:
+		for _, e := range t.value {
+			s.addItem(e.Key)
+			s.addItem(e.Value)
+		}
+	case *InteropItem:
+		s.addInterop(t.value)
+	}
+}
+
+func (s *snapshotWriter) addInterop(value interface{}) {
+	switch t := value.(type) {
+	case *arrayWrapper:
+		for _, e := range t.value {
+			s.addItem(e)
+		}
+	case *mapWrapper:
+		for _, e := range t.m {
+			s.addItem(e.Key)
+			s.addItem(e.Value)
+		}
+	case *concatEnum:
+		s.addInterop(t.current)
+		s.addInterop(t.second)
+	case *concatIter:
+		s.addInterop(t.current)
+		s.addInterop(t.second)
+	case *keysWrapper:
+		s.addInterop(t.iter)
+	case *valuesWrapper:
+		s.addInterop(t.iter)
+	}
+}
+
+func (s *snapshotWriter) writeIDs(w *io.BinWriter, items []StackItem) {
+	w.WriteVarUint(uint64(len(items)))
+	for _, e := range items {
+		w.WriteVarUint(uint64(s.items[e]))
+	}
+}
+
+func (s *snapshotWriter) writeMap(w *io.BinWriter, m []MapElement) {
+	w.WriteVarUint(uint64(len(m)))
+	for _, e := range m {
+		w.WriteVarUint(uint64(s.items[e.Key]))
+		w.WriteVarUint(uint64(s.items[e.Value]))
+	}
+}
+
+func (s *snapshotWriter) writeItem(w *io.BinWriter, item StackItem) {
+	switch t := item.(type) {
+	case *ByteArrayItem:
+		w.WriteB(byte(byteArrayT))
+		w.WriteVarBytes(t.value)
+	case *BoolItem:
+		w.WriteB(byte(booleanT))
+		w.WriteBool(t.value)
+	case *BigIntegerItem:
+		w.WriteB(byte(integerT))
+		w.WriteVarBytes(emit.IntToBytes(t.value))
+	case *ArrayItem:
+		w.WriteB(byte(arrayT))
+		s.writeIDs(w, t.value)
+	case *StructItem:
+		w.WriteB(byte(structT))
+		s.writeIDs(w, t.value)
+	case *MapItem:
+		w.WriteB(byte(mapT))
+		s.writeMap(w, t.value)
+	case *InteropItem:
+		w.WriteB(byte(interopT))
+		s.writeInterop(w, t.value)
+	default:
+		w.Err = fmt.Errorf("unsupported stack item type %T", item)
+	}
+}
+
+func (s *snapshotWriter) writeInterop(w *io.BinWriter, value interface{}) {
+	switch t := value.(type) {
+	case *arrayWrapper:
+		w.WriteB(interopArrayWrapper)
+		w.WriteU32LE(uint32(int32(t.index)))
+		s.writeIDs(w, t.value)
+	case *mapWrapper:
+		w.WriteB(interopMapWrapper)
+		w.WriteU32LE(uint32(int32(t.index)))
+		s.writeMap(w, t.m)
+	case *concatEnum:
+		w.WriteB(interopConcatEnum)
+		s.writeInterop(w, t.current)
+		s.writeInterop(w, t.second)
+	case *concatIter:
+		w.WriteB(interopConcatIter)
+		s.writeInterop(w, t.current)
+		s.writeInterop(w, t.second)
+	case *keysWrapper:
+		w.WriteB(interopKeys)
+		s.writeInterop(w, t.iter)
+	case *valuesWrapper:
+		w.WriteB(interopValues)
+		s.writeInterop(w, t.iter)
+	case OpaqueInterop:
+		w.WriteB(interopOpaque)
+		w.WriteString(t.Type)
+	default:
+		w.WriteB(interopOpaque)
+		w.WriteString(fmt.Sprintf("%T", value))
+	}
+}
+
+// snapshotReader restores stack items, references to items are resolved
+// after all of them are read.
+type snapshotReader struct {
+	r     *io.BinReader
+	max   uint64
+	items []StackItem
+	refs  []snapshotRef
+}
+
+// snapshotRef is a place to put item with the given id to.
+type snapshotRef struct {
+	dst *StackItem
+	id  uint64
+}
+
+// Restore replaces the VM execution state with the one serialized by
+// Snapshot. VM callbacks are left intact, so interop and script getters
+// should be set up the same way they were for the original VM. VM is not
+// changed if the snapshot can't be decoded.
+func (v *VM) Restore(data []byte) error {
+	r := io.NewBinReaderFromBuf(data)
+	s := &snapshotReader{r: r, max: uint64(len(data))}
+
+	if ver := r.ReadB(); r.Err == nil && ver != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", ver)
+	}
+	state := State(r.ReadB())
+	gasConsumed := util.Fixed8(r.ReadU64LE())
+	gasLimit := util.Fixed8(r.ReadU64LE())
+	checkhash := r.ReadVarBytes()
+
+	n := s.readCount()
+	s.items = make([]StackItem, n)
+	for i := range s.items {
+		s.items[i] = s.readItem()
+	}
+	if r.Err != nil {
+		return r.Err
+	}
+	for _, ref := range s.refs {
+		if ref.id >= uint64(len(s.items)) {
+			return errors.New("invalid item reference")
+		}
+		*ref.dst = s.items[ref.id]
+	}
+
+	type stackData struct {
+		name   string
+		shared bool
+		items  []StackItem
+	}
+	stacks := make([]stackData, s.readCount())
+	for i := range stacks {
+		stacks[i].name = r.ReadString()
+		stacks[i].shared = r.ReadBool()
+		stacks[i].items = make([]StackItem, s.readCount())
+		for j := range stacks[i].items {
+			stacks[i].items[j] = s.readRef()
+		}
+	}
+	estack, astack := s.readStackID(len(stacks)), s.readStackID(len(stacks))
+
+	type contextData struct {
+		ctx            *Context
+		estack, astack int
+	}
+	ctxs := make([]contextData, s.readCount())
+	programs := make(map[string]*Program)
+	for i := range ctxs {
+		prog := r.ReadVarBytes()
+		p, ok := programs[string(prog)]
+		if !ok {
+			p = NewProgram(prog)
+			programs[string(prog)] = p
+		}
+		ctx := NewContext(prog)
+		ctx.code = p
+		ctx.ip = int(r.ReadU32LE())
+		ctx.nextip = int(r.ReadU32LE())
+		if r.Err == nil && (ctx.ip > len(prog) || ctx.nextip > len(prog)) {
+			r.Err = errors.New("invalid instruction pointer")
+		}
+		ctx.breakPoints = make([]int, s.readCount())
+		for j := range ctx.breakPoints {
+			ctx.breakPoints[j] = int(r.ReadU32LE())
+		}
+		ctx.rvcount = int(int32(r.ReadU32LE()))
+		ctxs[i].estack = s.readStackID(len(stacks))
+		ctxs[i].astack = s.readStackID(len(stacks))
+		var h [util.Uint160Size]byte
+		r.ReadBytes(h[:])
+		ctx.scriptHash, _ = util.Uint160DecodeBytesBE(h[:])
+		ctx.hasDynamicInvoke = r.ReadBool()
+		ctxs[i].ctx = ctx
+	}
+	if r.Err != nil {
+		return r.Err
+	}
+
+	v.state = state
+	v.gasConsumed = gasConsumed
+	v.gasLimit = gasLimit
+	v.checkhash = checkhash
+	v.itemCount = make(map[StackItem]int)
+	v.size = 0
+	restored := make([]*Stack, len(stacks))
+	for i := range stacks {
+		var st *Stack
+		if stacks[i].shared {
+			st = v.newItemStack(stacks[i].name)
+		} else {
+			st = NewStack(stacks[i].name)
+		}
+		for _, item := range stacks[i].items {
+			st.Push(&Element{value: item})
+		}
+		restored[i] = st
+	}
+	v.istack = NewStack("invocation")
+	for _, c := range ctxs {
+		c.ctx.estack = restored[c.estack]
+		c.ctx.astack = restored[c.astack]
+		v.istack.PushVal(c.ctx)
+	}
+	v.estack = restored[estack]
+	v.astack = restored[astack]
+	return nil
+}
+
+// readCount reads the number of elements to follow, every element takes at
+// least one byte, so it can't exceed the snapshot length.
+func (s *snapshotReader) readCount() int {
+	n := s.r.ReadVarUint()
+	if s.r.Err == nil && n > s.max {
+		s.r.Err = errors.New("invalid element count")
+	}
+	if s.r.Err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (s *snapshotReader) readStackID(count int) int {
+	id := s.r.ReadVarUint()
+	if s.r.Err == nil && id >= uint64(count) {
+		s.r.Err = errors.New("invalid stack reference")
+	}
+	if s.r.Err != nil {
+		return 0
+	}
+	return int(id)
+}
+
+// readRef reads item id referring to already restored item.
+func (s *snapshotReader) readRef() StackItem {
+	id := s.r.ReadVarUint()
+	if s.r.Err == nil && id >= uint64(len(s.items)) {
+		s.r.Err = errors.New("invalid item reference")
+	}
+	if s.r.Err != nil {
+		return nil
+	}
+	return s.items[id]
+}
+
+// readIDs reads a list of item ids, items are set when all of them are read.
+func (s *snapshotReader) readIDs() []StackItem {
+	items := make([]StackItem, s.readCount())
+	for i := range items {
+		s.refs = append(s.refs, snapshotRef{dst: &items[i], id: s.r.ReadVarUint()})
+	}
+	return items
+}
+
+func (s *snapshotReader) readMap() []MapElement {
+	m := make([]MapElement, s.readCount())
+	for i := range m {
+		s.refs = append(s.refs, snapshotRef{dst: &m[i].Key, id: s.r.ReadVarUint()})
+		s.refs = append(s.refs, snapshotRef{dst: &m[i].Value, id: s.r.ReadVarUint()})
+	}
+	return m
+}
+
+func (s *snapshotReader) readItem() StackItem {
+	switch t := stackItemType(s.r.ReadB()); t {
+	case byteArrayT:
+		return NewByteArrayItem(s.r.ReadVarBytes())
+	case booleanT:
+		return NewBoolItem(s.r.ReadBool())
+	case integerT:
+		return &BigIntegerItem{value: emit.BytesToInt(s.r.ReadVarBytes())}
+	case arrayT:
+		return &ArrayItem{value: s.readIDs()}
+	case structT:
+		return &StructItem{value: s.readIDs()}
+	case mapT:
+		return &MapItem{value: s.readMap()}
+	case interopT:
+		return NewInteropItem(s.readInterop())
+	default:
+		if s.r.Err == nil {
+			s.r.Err = fmt.Errorf("unknown stack item type %d", t)
+		}
+		return nil
+	}
+}
+
+func (s *snapshotReader) readInterop() interface{} {
+	switch kind := s.r.ReadB(); kind {
+	case interopOpaque:
+		return OpaqueInterop{Type: s.r.ReadString()}
+	case interopArrayWrapper:
+		index := int(int32(s.r.ReadU32LE()))
+		return &arrayWrapper{index: index, value: s.readIDs()}
+	case interopMapWrapper:
+		index := int(int32(s.r.ReadU32LE()))
+		return &mapWrapper{index: index, m: s.readMap()}
+	case interopConcatEnum:
+		current, ok1 := s.readInterop().(enumerator)
+		second, ok2 := s.readInterop().(enumerator)
+		if !ok1 || !ok2 {
+			return OpaqueInterop{Type: "*vm.concatEnum"}
+		}
+		return &concatEnum{current: current, second: second}
+	case interopConcatIter:
+		current, ok1 := s.readInterop().(iterator)
+		second, ok2 := s.readInterop().(iterator)
+		if !ok1 || !ok2 {
+			return OpaqueInterop{Type: "*vm.concatIter"}
+		}
+		return &concatIter{current: current, second: second}
+	case interopKeys:
+		if iter, ok := s.readInterop().(iterator); ok {
+			return &keysWrapper{iter}
+		}
+		return OpaqueInterop{Type: "*vm.keysWrapper"}
+	case interopValues:
+		if iter, ok := s.readInterop().(iterator); ok {
+			return &valuesWrapper{iter}
+		}
+		return OpaqueInterop{Type: "*vm.valuesWrapper"}
+	default:
+		if s.r.Err == nil {
+			s.r.Err = fmt.Errorf("unknown interop kind %d", kind)
+		}
+		return nil
+	}
+}
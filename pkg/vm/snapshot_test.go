@@ -0,0 +1,126 @@
+package vm
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func restoreSnapshot(t *testing.T, v *VM) *VM {
+	data, err := v.Snapshot()
+	require.NoError(t, err)
+	restored := New()
+	require.NoError(t, restored.Restore(data))
+	return restored
+}
+
+func TestSnapshotExecution(t *testing.T) {
+	prog := []byte{
+		byte(opcode.CALL), 5, 0,
+		byte(opcode.INC),
+		byte(opcode.RET),
+		byte(opcode.PUSH2), // 5
+		byte(opcode.TOALTSTACK),
+		byte(opcode.FROMALTSTACK),
+		byte(opcode.RET),
+	}
+	v := load(prog)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, v.Step())
+	}
+	v.AddBreakPoint(8)
+	require.Equal(t, 2, v.Istack().Len())
+	require.Equal(t, 1, v.Astack().Len())
+
+	r := restoreSnapshot(t, v)
+	require.Equal(t, v.State(), r.State())
+	require.Equal(t, 2, r.Istack().Len())
+	require.Equal(t, 1, r.Astack().Len())
+	require.Equal(t, []int{8}, r.Context().breakPoints)
+	require.Equal(t, v.Context().ip, r.Context().ip)
+	require.Equal(t, v.Context().ScriptHash(), r.Context().ScriptHash())
+	// CALL shares stacks between contexts.
+	var stacks []*Stack
+	r.Istack().Iter(func(e *Element) {
+		stacks = append(stacks, e.value.(*Context).estack)
+	})
+	require.True(t, stacks[0] == stacks[1])
+	require.True(t, r.Estack() == stacks[0])
+
+	r.Context().breakPoints = nil
+	v.Context().breakPoints = nil
+	for _, vm := range []*VM{v, r} {
+		runVM(t, vm)
+		assert.Equal(t, int64(3), vm.Estack().Pop().BigInt().Int64())
+	}
+	assert.Equal(t, v.GasConsumed(), r.GasConsumed())
+}
+
+func TestSnapshotLoop(t *testing.T) {
+	v := load(loopProgram)
+	v.SetGasLimit(1000)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, v.Step())
+	}
+	r := restoreSnapshot(t, v)
+	require.Equal(t, v.GasConsumed(), r.GasConsumed())
+	runVM(t, r)
+	require.Equal(t, int64(1000), r.Estack().Pop().BigInt().Int64())
+}
+
+func TestSnapshotSharedItems(t *testing.T) {
+	v := New()
+	arr := NewArrayItem([]StackItem{makeStackItem(1)})
+	s := NewStructItem(nil)
+	s.value = append(s.value, s)
+	m := NewMapItem()
+	m.Add(makeStackItem("key"), arr)
+	v.Estack().PushVal(arr)
+	v.Estack().PushVal(s)
+	v.Estack().PushVal(m)
+	v.Astack().PushVal(arr)
+	v.Estack().PushVal(NewInteropItem(&arrayWrapper{index: 0, value: arr.value}))
+	v.Estack().PushVal(NewInteropItem(&keysWrapper{NewMapIterator(m).value.(iterator)}))
+	v.Estack().PushVal(NewInteropItem(t))
+	v.Estack().PushVal(true)
+	v.Estack().PushVal([]byte{1, 2, 3})
+
+	r := restoreSnapshot(t, v)
+	require.Equal(t, v.size, r.size)
+	require.Equal(t, []byte{1, 2, 3}, r.Estack().Pop().Bytes())
+	require.True(t, r.Estack().Pop().Bool())
+	require.Equal(t, OpaqueInterop{Type: "*testing.T"}, r.Estack().Pop().Interop().value)
+
+	keys := r.Estack().Pop().Interop().value.(*keysWrapper)
+	require.True(t, keys.Next())
+	require.Equal(t, []byte("key"), keys.Value().Value())
+
+	enum := r.Estack().Pop().Interop().value.(*arrayWrapper)
+	rm := r.Estack().Pop().value.(*MapItem)
+	rs := r.Estack().Pop().value.(*StructItem)
+	ra := r.Estack().Pop().value.(*ArrayItem)
+	require.True(t, rs.value[0] == rs)
+	require.True(t, rm.value[0].Value == ra)
+	require.True(t, r.Astack().Pop().value == ra)
+	require.True(t, enum.value[0] == ra.value[0])
+	require.Equal(t, 0, enum.index)
+}
+
+func TestSnapshotRestoreInvalid(t *testing.T) {
+	v := load(loopProgram)
+	require.NoError(t, v.Step())
+	data, err := v.Snapshot()
+	require.NoError(t, err)
+
+	r := load(loopProgram)
+	require.Error(t, r.Restore(data[:len(data)-1]))
+	require.Error(t, r.Restore(append([]byte{0xff}, data[1:]...)))
+	for i := 0; i < 100; i++ {
+		buf := make([]byte, rand.Intn(len(data)))
+		rand.Read(buf)
+		require.NotPanics(t, func() { _ = r.Restore(buf) })
+	}
+}
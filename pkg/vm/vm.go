@@ -69,6 +69,9 @@ type VM struct {
 	// callback to get scripts.
 	getScript func(util.Uint160) ([]byte, bool)
 
+	// programs caches pre-decoded scripts got via getScript.
+	programs *ProgramCache
+
 	istack *Stack // invocation stack.
 	estack *Stack // execution stack.
 	astack *Stack // alt stack.
@@ -256,22 +259,38 @@ func (v *VM) Load(prog []byte) {
 // will immediately push a new context created from this script to
 // the invocation stack and starts executing it.
 func (v *VM) LoadScript(b []byte) {
-	ctx := NewContext(b)
+	v.loadProgram(NewProgram(b))
+}
+
+// loadProgram pushes a new context executing the given program to the
+// invocation stack.
+func (v *VM) loadProgram(p *Program) *Context {
+	ctx := NewContext(p.script)
+	ctx.code = p
 	ctx.estack = v.estack
 	ctx.astack = v.astack
 	v.istack.PushVal(ctx)
+	return ctx
 }
 
 // loadScriptWithHash if similar to the LoadScript method, but it also loads
 // given script hash directly into the Context to avoid its recalculations. It's
 // up to user of this function to make sure the script and hash match each other.
 func (v *VM) loadScriptWithHash(b []byte, hash util.Uint160, hasDynamicInvoke bool) {
-	v.LoadScript(b)
-	ctx := v.Context()
+	ctx := v.loadProgram(v.program(hash, b))
 	ctx.scriptHash = hash
 	ctx.hasDynamicInvoke = hasDynamicInvoke
 }
 
+// program returns pre-decoded script with the given hash using program cache
+// if it's set.
+func (v *VM) program(hash util.Uint160, script []byte) *Program {
+	if v.programs != nil {
+		return v.programs.Get(hash, script)
+	}
+	return NewProgram(script)
+}
+
 // Context returns the current executed context. Nil if there is no context,
 // which implies no program is loaded.
 func (v *VM) Context() *Context {
@@ -347,7 +366,7 @@ func (v *VM) Run() error {
 			// Normal exit from this loop.
 			return nil
 		case v.state == noneState:
-			if err := v.Step(); err != nil {
+			if err := v.step(ctx); err != nil {
 				return err
 			}
 		default:
@@ -359,8 +378,12 @@ func (v *VM) Run() error {
 
 // Step 1 instruction in the program.
 func (v *VM) Step() error {
-	ctx := v.Context()
-	op, param, err := ctx.Next()
+	return v.step(v.Context())
+}
+
+// step executes the next instruction of the given context.
+func (v *VM) step(ctx *Context) error {
+	op, param, err := ctx.next()
 	if err != nil {
 		v.state = faultState
 		return newError(ctx.ip, op, err)
@@ -382,7 +405,7 @@ func (v *VM) StepInto() error {
 	}
 
 	if ctx != nil && ctx.prog != nil {
-		op, param, err := ctx.Next()
+		op, param, err := ctx.next()
 		if err != nil {
 			v.state = faultState
 			return newError(ctx.ip, op, err)
@@ -477,6 +500,13 @@ func (v *VM) SetScriptGetter(gs func(util.Uint160) ([]byte, bool)) {
 	v.getScript = gs
 }
 
+// SetProgramCache sets the cache of pre-decoded scripts got via script
+// getter, it can be shared between VMs. Script getter must return scripts
+// matching requested hashes for it to work correctly.
+func (v *VM) SetProgramCache(c *ProgramCache) {
+	v.programs = c
+}
+
 // GetInteropID converts instruction parameter to an interop ID.
 func GetInteropID(parameter []byte) uint32 {
 	if len(parameter) == 4 {
@@ -1381,6 +1411,7 @@ func (v *VM) execute(ctx *Context, op opcode.Opcode, parameter []byte) (err erro
 				panic(fmt.Sprintf("could not find script %s", hash))
 			}
 			newCtx = NewContext(script)
+			newCtx.code = v.program(hash, script)
 			newCtx.scriptHash = hash
 			newCtx.hasDynamicInvoke = hasDynamicInvoke
 		}
@@ -1430,8 +1461,13 @@ func (v *VM) jumpIf(ctx *Context, offset int, cond bool) {
 // to a which JMP should be performed.
 // parameter is interpreted as little-endian int16.
 func (v *VM) getJumpOffset(ctx *Context, parameter []byte, mod int) int {
-	rOffset := int16(binary.LittleEndian.Uint16(parameter))
-	offset := ctx.ip + int(rOffset) + mod
+	var offset int
+	if ctx.instr != nil {
+		offset = ctx.instr.target
+	} else {
+		rOffset := int16(binary.LittleEndian.Uint16(parameter))
+		offset = ctx.ip + int(rOffset) + mod
+	}
 	if offset < 0 || offset > len(ctx.prog) {
 		panic(fmt.Sprintf("JMP: invalid offset %d ip at %d", offset, ctx.ip))
 	}
@@ -25,6 +25,9 @@ type Account struct {
 	// NEO public key.
 	publicKey []byte
 
+	// Signer for keys not stored in the wallet.
+	signer Signer
+
 	// Account import file.
 	wif string
 
@@ -47,6 +50,9 @@ type Account struct {
 
 	// Indicates whether the account is the default change account.
 	Default bool `json:"isDefault"`
+
+	// Extra is an additional account data.
+	Extra *AccountExtra `json:"extra,omitempty"`
 }
 
 // Contract represents a subset of the smartcontract to embed in the
@@ -126,13 +132,29 @@ func NewAccount() (*Account, error) {
 	return newAccountFromPrivateKey(priv), nil
 }
 
+// NewWatchOnlyAccount creates an account for the given script hash without
+// any key or contract, it can only be used to track address balance.
+func NewWatchOnlyAccount(h util.Uint160) *Account {
+	return &Account{Address: address.Uint160ToString(h)}
+}
+
+// IsWatchOnly returns true if there is no key for the account in the wallet,
+// so it can't be used for signing.
+func (a *Account) IsWatchOnly() bool {
+	return a.EncryptedWIF == "" && !a.IsHSM() && a.Signer() == nil
+}
+
 // SignTx signs transaction t and updates it's Witnesses.
 func (a *Account) SignTx(t *transaction.Transaction) error {
-	if a.privateKey == nil {
+	s := a.Signer()
+	if s == nil {
 		return errors.New("account is not unlocked")
 	}
 	data := t.GetSignedPart()
-	sign := a.privateKey.Sign(data)
+	sign, err := s.Sign(data)
+	if err != nil {
+		return err
+	}
 
 	t.Scripts = append(t.Scripts, transaction.Witness{
 		InvocationScript:   append([]byte{byte(opcode.PUSHBYTES64)}, sign...),
@@ -146,18 +168,61 @@ func (a *Account) getVerificationScript() []byte {
 	if a.Contract != nil {
 		return a.Contract.Script
 	}
-	return a.PrivateKey().PublicKey().GetVerificationScript()
+	return a.Signer().PublicKey().GetVerificationScript()
+}
+
+// Signer returns Signer for the account key, it's nil if the account is not
+// unlocked.
+func (a *Account) Signer() Signer {
+	if a.signer != nil {
+		return a.signer
+	}
+	if a.privateKey != nil {
+		return privateKeySigner{priv: a.privateKey}
+	}
+	return nil
+}
+
+// IsHSM returns true if the account key is stored in HSM.
+func (a *Account) IsHSM() bool {
+	return a.Extra != nil && a.Extra.HSM != nil
+}
+
+// Unlock makes the account with the key stored in HSM ready for signing
+// using the given provider.
+func (a *Account) Unlock(p KeyProvider) error {
+	if !a.IsHSM() {
+		return errors.New("no HSM key in the account")
+	}
+	s, err := p.Signer(a.Extra.HSM)
+	if err != nil {
+		return err
+	}
+	pub := s.PublicKey().Bytes()
+	if a.Contract != nil && !bytes.Contains(a.Contract.Script, pub) {
+		return errors.New("HSM key doesn't match account contract")
+	}
+	a.signer = s
+	a.publicKey = pub
+	return nil
 }
 
 // Decrypt decrypts the EncryptedWIF with the given passphrase returning error
 // if anything goes wrong.
 func (a *Account) Decrypt(passphrase string) error {
+	return a.DecryptWithParams(passphrase, keys.NEP2ScryptParams())
+}
+
+// DecryptWithParams decrypts the EncryptedWIF with the given passphrase and
+// scrypt parameters, accounts from the wallet must be decrypted with the
+// wallet Scrypt.
+func (a *Account) DecryptWithParams(passphrase string, params keys.ScryptParams) error {
 	var err error
 
 	if a.EncryptedWIF == "" {
 		return errors.New("no encrypted wif in the account")
 	}
-	a.privateKey, err = keys.NEP2Decrypt(a.EncryptedWIF, passphrase)
+	a.privateKey, err = keys.NEP2DecryptWithParams(a.EncryptedWIF, passphrase, params)
 	if err != nil {
 		return err
 	}
@@ -171,7 +236,13 @@ func (a *Account) Decrypt(passphrase string) error {
 // Encrypt encrypts the wallet's PrivateKey with the given passphrase
 // under the NEP-2 standard.
 func (a *Account) Encrypt(passphrase string) error {
-	wif, err := keys.NEP2Encrypt(a.privateKey, passphrase)
+	return a.EncryptWithParams(passphrase, keys.NEP2ScryptParams())
+}
+
+// EncryptWithParams encrypts the wallet's PrivateKey with the given
+// passphrase under the NEP-2 standard with the given scrypt parameters.
+func (a *Account) EncryptWithParams(passphrase string, params keys.ScryptParams) error {
+	wif, err := keys.NEP2EncryptWithParams(a.privateKey, passphrase, params)
 	if err != nil {
 		return err
 	}
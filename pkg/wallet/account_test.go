@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/internal/keytestcases"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -123,6 +126,29 @@ func TestAccount_ConvertMultisig(t *testing.T) {
 	})
 }
 
+func TestNewWatchOnlyAccount(t *testing.T) {
+	h := util.Uint160{1, 2, 3}
+	a := NewWatchOnlyAccount(h)
+	require.True(t, a.IsWatchOnly())
+	require.Nil(t, a.Contract)
+	require.Error(t, a.SignTx(&transaction.Transaction{}))
+
+	actual, err := address.StringToUint160(a.Address)
+	require.NoError(t, err)
+	require.Equal(t, h, actual)
+
+	data, err := json.Marshal(a)
+	require.NoError(t, err)
+	b := new(Account)
+	require.NoError(t, json.Unmarshal(data, b))
+	require.True(t, b.IsWatchOnly())
+	require.Equal(t, a.Address, b.Address)
+
+	acc, err := NewAccount()
+	require.NoError(t, err)
+	require.False(t, acc.IsWatchOnly())
+}
+
 func convertPubs(t *testing.T, hexKeys []string) []*keys.PublicKey {
 	pubs := make([]*keys.PublicKey, len(hexKeys))
 	for i := range pubs {
@@ -0,0 +1,157 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+)
+
+// base58Alphabet is the set of characters addresses consist of.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// GenerateOptions are parameters of accounts generation.
+type GenerateOptions struct {
+	// Count is the number of accounts to generate.
+	Count int
+	// Prefixes are address prefixes to search for, the address should have
+	// any of them. All addresses are accepted if it's empty.
+	Prefixes []string
+	// Workers is the number of goroutines generating and encrypting keys,
+	// it's the number of CPUs by default.
+	Workers int
+	// Passphrase is used to encrypt keys.
+	Passphrase string
+	// Label is set for all generated accounts.
+	Label string
+	// Progress is called (from one goroutine) after every generated account
+	// with the number of accounts generated so far.
+	Progress func(done int)
+}
+
+// CheckAddressPrefix checks that some addresses can start with the prefix.
+func CheckAddressPrefix(prefix string) error {
+	if !strings.HasPrefix(prefix, "A") {
+		return fmt.Errorf("invalid prefix %s: addresses start with 'A'", prefix)
+	}
+	for _, c := range prefix {
+		if !strings.ContainsRune(base58Alphabet, c) {
+			return fmt.Errorf("invalid prefix %s: '%c' is not a base58 character", prefix, c)
+		}
+	}
+	return nil
+}
+
+// GenerateAccounts generates new accounts with keys encrypted with the
+// wallet scrypt parameters in parallel and adds them to the wallet, the wallet
+// is not saved. Generation can be canceled via ctx, accounts generated by
+// that moment are added anyway.
+func (w *Wallet) GenerateAccounts(ctx context.Context, opts GenerateOptions) ([]*Account, error) {
+	if opts.Count <= 0 {
+		return nil, errors.New("positive number of accounts is required")
+	}
+	for _, p := range opts.Prefixes {
+		if err := CheckAddressPrefix(p); err != nil {
+			return nil, err
+		}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		err     error
+		res     = make([]*Account, 0, opts.Count)
+		claimed int
+		params  = w.Scrypt
+	)
+	// claim reserves a place for the found account, it returns false if
+	// enough accounts are found already. Other workers are stopped when the
+	// last place is taken.
+	claim := func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		if claimed == opts.Count {
+			return false
+		}
+		claimed++
+		if claimed == opts.Count {
+			cancel()
+		}
+		return true
+	}
+	fail := func(e error) {
+		lock.Lock()
+		if err == nil {
+			err = e
+		}
+		lock.Unlock()
+		cancel()
+	}
+	results := make(chan *Account)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				priv, e := keys.NewPrivateKey()
+				if e != nil {
+					fail(e)
+					return
+				}
+				if !matchPrefix(priv.Address(), opts.Prefixes) {
+					continue
+				}
+				if !claim() {
+					return
+				}
+				acc := newAccountFromPrivateKey(priv)
+				acc.Label = opts.Label
+				if e := acc.EncryptWithParams(opts.Passphrase, params); e != nil {
+					fail(e)
+					return
+				}
+				results <- acc
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	for acc := range results {
+		res = append(res, acc)
+		if opts.Progress != nil {
+			opts.Progress(len(res))
+		}
+	}
+	for _, acc := range res {
+		w.AddAccount(acc)
+	}
+	if err == nil && len(res) < opts.Count {
+		err = fmt.Errorf("generation stopped after %d accounts: %v", len(res), ctx.Err())
+	}
+	return res, err
+}
+
+func matchPrefix(addr string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(addr, p) {
+			return true
+		}
+	}
+	return false
+}
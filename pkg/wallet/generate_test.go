@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAddressPrefix(t *testing.T) {
+	require.NoError(t, CheckAddressPrefix("A"))
+	require.NoError(t, CheckAddressPrefix("AKk"))
+	require.Error(t, CheckAddressPrefix(""))
+	require.Error(t, CheckAddressPrefix("B"))
+	require.Error(t, CheckAddressPrefix("AO"))
+}
+
+func TestWallet_GenerateAccounts(t *testing.T) {
+	newTestWallet := func() *Wallet {
+		w := newWallet(new(bytes.Buffer))
+		w.Scrypt = keys.ScryptParams{N: 2, R: 1, P: 1}
+		return w
+	}
+
+	t.Run("simple", func(t *testing.T) {
+		w := newTestWallet()
+		var progress []int
+		accs, err := w.GenerateAccounts(context.Background(), GenerateOptions{
+			Count:      5,
+			Workers:    3,
+			Passphrase: "pass",
+			Label:      "deposit",
+			Progress:   func(done int) { progress = append(progress, done) },
+		})
+		require.NoError(t, err)
+		require.Equal(t, 5, len(accs))
+		require.Equal(t, accs, w.Accounts)
+		require.Equal(t, []int{1, 2, 3, 4, 5}, progress)
+		for _, acc := range accs {
+			require.Equal(t, "deposit", acc.Label)
+			require.NoError(t, acc.DecryptWithParams("pass", w.Scrypt))
+			require.Equal(t, acc.Address, acc.PrivateKey().Address())
+		}
+		// Keys are not encrypted with the default parameters.
+		require.Error(t, accs[0].Decrypt("pass"))
+
+		// Other accounts use wallet parameters too.
+		require.NoError(t, w.CreateAccount("other", "pass"))
+		require.NoError(t, w.Accounts[5].DecryptWithParams("pass", w.Scrypt))
+	})
+	t.Run("prefix", func(t *testing.T) {
+		w := newTestWallet()
+		accs, err := w.GenerateAccounts(context.Background(), GenerateOptions{
+			Count:    2,
+			Prefixes: []string{"AK", "AL"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(accs))
+		for _, acc := range accs {
+			require.True(t, strings.HasPrefix(acc.Address, "AK") || strings.HasPrefix(acc.Address, "AL"))
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		w := newTestWallet()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := w.GenerateAccounts(ctx, GenerateOptions{
+			Count:    1,
+			Prefixes: []string{"AKkkkkkkkkkk"},
+		})
+		require.Error(t, err)
+	})
+	t.Run("bad options", func(t *testing.T) {
+		w := newTestWallet()
+		_, err := w.GenerateAccounts(context.Background(), GenerateOptions{})
+		require.Error(t, err)
+		_, err = w.GenerateAccounts(context.Background(), GenerateOptions{Count: 1, Prefixes: []string{"B"}})
+		require.Error(t, err)
+	})
+}
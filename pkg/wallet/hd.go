@@ -0,0 +1,203 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// NEOCoinType is NEO coin type registered in SLIP-0044.
+	NEOCoinType = 888
+
+	// mnemonicEntropySize is the size of entropy for new mnemonics in
+	// bits, it gives 24 words.
+	mnemonicEntropySize = 256
+)
+
+// HDKey is an account-level (m/44'/888'/account') key of hierarchical
+// deterministic wallet, wallet addresses are derived from it.
+type HDKey struct {
+	// Path is a derivation path of the key.
+	Path string `json:"path"`
+	// Key is NEP-2 encrypted private key.
+	Key string `json:"key"`
+	// ChainCode is hex-encoded chain code of the key.
+	ChainCode string `json:"chaincode"`
+}
+
+// AccountExtra is an additional account data.
+type AccountExtra struct {
+	// Path is a derivation path for accounts of HD wallet.
+	Path string `json:"path,omitempty"`
+	// HSM is a reference to the account key stored in HSM.
+	HSM *HSMKey `json:"hsm,omitempty"`
+}
+
+// NewMnemonic generates new random BIP-0039 mnemonic.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropySize)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// IsHD returns true if wallet accounts are derived from mnemonic.
+func (w *Wallet) IsHD() bool {
+	return w.Extra.HD != nil
+}
+
+// InitHD makes w hierarchical deterministic wallet using the given mnemonic
+// as a source of keys. Derived key is encrypted with the passphrase, the
+// same passphrase must be used to create accounts.
+func (w *Wallet) InitHD(mnemonic, passphrase string) error {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return err
+	}
+	master, err := keys.NewMasterKey(seed)
+	if err != nil {
+		return err
+	}
+	path := []uint32{
+		keys.HardenedKeyStart + 44,
+		keys.HardenedKeyStart + NEOCoinType,
+		keys.HardenedKeyStart,
+	}
+	k, err := master.Derive(path)
+	if err != nil {
+		return err
+	}
+	encrypted, err := keys.NEP2EncryptWithParams(k.PrivateKey, passphrase, w.Scrypt)
+	if err != nil {
+		return err
+	}
+	w.Extra.HD = &HDKey{
+		Path:      keys.FormatDerivationPath(path),
+		Key:       encrypted,
+		ChainCode: hex.EncodeToString(k.ChainCode),
+	}
+	return nil
+}
+
+// hdAccountPath returns derivation path for the account with the given
+// index (external chain is always used).
+func (w *Wallet) hdAccountPath(index uint32) string {
+	return fmt.Sprintf("%s/0/%d", w.Extra.HD.Path, index)
+}
+
+// NextHDIndex returns the index of the first account which is not yet
+// present in the wallet.
+func (w *Wallet) NextHDIndex() uint32 {
+	var next uint32
+	if !w.IsHD() {
+		return next
+	}
+	prefix := w.Extra.HD.Path + "/0/"
+	for _, acc := range w.Accounts {
+		if acc.Extra == nil || !strings.HasPrefix(acc.Extra.Path, prefix) {
+			continue
+		}
+		path, err := keys.ParseDerivationPath(acc.Extra.Path)
+		if err != nil {
+			continue
+		}
+		if i := path[len(path)-1]; i < keys.HardenedKeyStart && i >= next {
+			next = i + 1
+		}
+	}
+	return next
+}
+
+// Decrypt decrypts the key with the given passphrase and scrypt parameters.
+func (k *HDKey) Decrypt(passphrase string, params keys.ScryptParams) (*keys.ExtendedKey, error) {
+	priv, err := keys.NEP2DecryptWithParams(k.Key, passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	chainCode, err := hex.DecodeString(k.ChainCode)
+	if err != nil {
+		return nil, err
+	}
+	return keys.NewExtendedKey(priv, chainCode)
+}
+
+// DeriveAccount derives account with the given index from HD wallet key,
+// the account is encrypted with the passphrase, but is not added to the
+// wallet.
+func (w *Wallet) DeriveAccount(index uint32, passphrase string) (*Account, error) {
+	if !w.IsHD() {
+		return nil, errors.New("not an HD wallet")
+	}
+	k, err := w.Extra.HD.Decrypt(passphrase, w.Scrypt)
+	if err != nil {
+		return nil, err
+	}
+	acc, err := w.deriveAccount(k, index)
+	if err != nil {
+		return nil, err
+	}
+	if err := acc.EncryptWithParams(passphrase, w.Scrypt); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+func (w *Wallet) deriveAccount(k *keys.ExtendedKey, index uint32) (*Account, error) {
+	if index >= keys.HardenedKeyStart {
+		return nil, errors.New("invalid account index")
+	}
+	k, err := k.Derive([]uint32{0, index})
+	if err != nil {
+		return nil, err
+	}
+	acc := newAccountFromPrivateKey(k.PrivateKey)
+	acc.Extra = &AccountExtra{Path: w.hdAccountPath(index)}
+	return acc, nil
+}
+
+// RecoverHD derives accounts starting from the given index until gap
+// consecutive unused ones are found. Used accounts missing from the wallet
+// are encrypted with the passphrase and added to it, the list of added
+// accounts is returned.
+func (w *Wallet) RecoverHD(passphrase string, start uint32, gap int, isUsed func(address string) (bool, error)) ([]*Account, error) {
+	if !w.IsHD() {
+		return nil, errors.New("not an HD wallet")
+	}
+	k, err := w.Extra.HD.Decrypt(passphrase, w.Scrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []*Account
+	for i, unused := start, 0; unused < gap; i++ {
+		acc, err := w.deriveAccount(k, i)
+		if err != nil {
+			return added, err
+		}
+		used, err := isUsed(acc.Address)
+		if err != nil {
+			return added, err
+		}
+		if !used {
+			unused++
+			continue
+		}
+		unused = 0
+		if w.GetAccount(acc.Contract.ScriptHash()) != nil {
+			continue
+		}
+		if err := acc.EncryptWithParams(passphrase, w.Scrypt); err != nil {
+			return added, err
+		}
+		w.AddAccount(acc)
+		added = append(added, acc)
+	}
+	return added, nil
+}
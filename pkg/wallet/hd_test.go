@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestNewMnemonic(t *testing.T) {
+	m, err := NewMnemonic()
+	require.NoError(t, err)
+	require.Len(t, strings.Fields(m), 24)
+
+	w := newWallet(new(bytes.Buffer))
+	require.NoError(t, w.InitHD(m, "pass"))
+}
+
+func TestWallet_HD(t *testing.T) {
+	w := newWallet(new(bytes.Buffer))
+	require.False(t, w.IsHD())
+	require.Error(t, w.InitHD("abandon abandon", "pass"))
+	_, err := w.DeriveAccount(0, "pass")
+	require.Error(t, err)
+
+	require.NoError(t, w.InitHD(testMnemonic, "pass"))
+	require.True(t, w.IsHD())
+	require.Equal(t, "m/44'/888'/0'", w.Extra.HD.Path)
+	require.Equal(t, uint32(0), w.NextHDIndex())
+
+	require.NoError(t, w.CreateAccount("first", "pass"))
+	require.NoError(t, w.CreateAccount("second", "pass"))
+	require.Len(t, w.Accounts, 2)
+	require.Equal(t, "m/44'/888'/0'/0/0", w.Accounts[0].Extra.Path)
+	require.Equal(t, "m/44'/888'/0'/0/1", w.Accounts[1].Extra.Path)
+	require.Equal(t, uint32(2), w.NextHDIndex())
+	require.Error(t, w.CreateAccount("third", "wrong"))
+
+	// The same mnemonic gives the same accounts.
+	w2 := newWallet(new(bytes.Buffer))
+	require.NoError(t, w2.InitHD(testMnemonic, "other"))
+	acc, err := w2.DeriveAccount(1, "other")
+	require.NoError(t, err)
+	require.Equal(t, w.Accounts[1].Address, acc.Address)
+	require.NoError(t, acc.Decrypt("other"))
+	require.NoError(t, w.Accounts[1].Decrypt("pass"))
+	require.Equal(t, w.Accounts[1].PrivateKey().Bytes(), acc.PrivateKey().Bytes())
+}
+
+func TestWallet_RecoverHD(t *testing.T) {
+	src := newWallet(new(bytes.Buffer))
+	require.NoError(t, src.InitHD(testMnemonic, "pass"))
+	used := make(map[string]bool)
+	for _, i := range []uint32{0, 2, 5} {
+		acc, err := src.DeriveAccount(i, "pass")
+		require.NoError(t, err)
+		used[acc.Address] = true
+	}
+	isUsed := func(addr string) (bool, error) { return used[addr], nil }
+
+	w := newWallet(new(bytes.Buffer))
+	require.NoError(t, w.InitHD(testMnemonic, "pass"))
+	require.NoError(t, w.CreateAccount("first", "pass"))
+
+	_, err := w.RecoverHD("wrong", 0, 3, isUsed)
+	require.Error(t, err)
+
+	// Gap of 2 unused accounts stops the scan before index 5.
+	added, err := w.RecoverHD("pass", 0, 2, isUsed)
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	require.Equal(t, "m/44'/888'/0'/0/2", added[0].Extra.Path)
+
+	added, err = w.RecoverHD("pass", 0, 3, isUsed)
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	require.Equal(t, "m/44'/888'/0'/0/5", added[0].Extra.Path)
+	require.Len(t, w.Accounts, 3)
+	require.Equal(t, uint32(6), w.NextHDIndex())
+}
@@ -0,0 +1,117 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+)
+
+// HSMKey is a reference to the private key stored in hardware security module,
+// accounts using such keys have no `key` field.
+type HSMKey struct {
+	// Module is a path to PKCS#11 library of the HSM.
+	Module string `json:"module"`
+	// Token is a label of the token holding the key.
+	Token string `json:"token"`
+	// ID is hex-encoded CKA_ID of the key.
+	ID string `json:"id"`
+}
+
+// Signer signs data with some private key which can be not accessible
+// directly.
+type Signer interface {
+	// PublicKey returns public key corresponding to the private key used.
+	PublicKey() *keys.PublicKey
+	// Sign returns signature of sha256 hash of data in the same format
+	// PrivateKey.Sign returns it.
+	Sign(data []byte) ([]byte, error)
+}
+
+// KeyProvider gives access to keys stored outside of the wallet.
+type KeyProvider interface {
+	// Signer returns Signer for the key referenced by k.
+	Signer(k *HSMKey) (Signer, error)
+}
+
+// privateKeySigner is a Signer for keys kept in memory.
+type privateKeySigner struct {
+	priv *keys.PrivateKey
+}
+
+// PublicKey implements Signer interface.
+func (s privateKeySigner) PublicKey() *keys.PublicKey {
+	return s.priv.PublicKey()
+}
+
+// Sign implements Signer interface.
+func (s privateKeySigner) Sign(data []byte) ([]byte, error) {
+	return s.priv.Sign(data), nil
+}
+
+// MemoryKeyProvider is a KeyProvider keeping keys in memory. It's a software
+// stand-in for HSM which doesn't protect keys in any way, so it should only
+// be used for testing.
+type MemoryKeyProvider struct {
+	lock sync.RWMutex
+	keys map[HSMKey]*keys.PrivateKey
+}
+
+// NewMemoryKeyProvider returns new empty MemoryKeyProvider.
+func NewMemoryKeyProvider() *MemoryKeyProvider {
+	return &MemoryKeyProvider{
+		keys: make(map[HSMKey]*keys.PrivateKey),
+	}
+}
+
+// Generate creates new key in the specified token and returns a reference
+// to it.
+func (p *MemoryKeyProvider) Generate(token string) (*HSMKey, error) {
+	priv, err := keys.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	k := HSMKey{Token: token, ID: hex.EncodeToString(id)}
+
+	p.lock.Lock()
+	p.keys[k] = priv
+	p.lock.Unlock()
+	return &k, nil
+}
+
+// Signer implements KeyProvider interface, key module is ignored.
+func (p *MemoryKeyProvider) Signer(k *HSMKey) (Signer, error) {
+	p.lock.RLock()
+	priv, ok := p.keys[HSMKey{Token: k.Token, ID: k.ID}]
+	p.lock.RUnlock()
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return privateKeySigner{priv: priv}, nil
+}
+
+// NewAccountFromHSM creates a new Account using key k from provider p. The
+// account is unlocked and ready for signing.
+func NewAccountFromHSM(p KeyProvider, k *HSMKey) (*Account, error) {
+	s, err := p.Signer(k)
+	if err != nil {
+		return nil, err
+	}
+	pub := s.PublicKey()
+	return &Account{
+		publicKey: pub.Bytes(),
+		signer:    s,
+		Address:   pub.Address(),
+		Contract: &Contract{
+			Script:     pub.GetVerificationScript(),
+			Parameters: getContractParams(1),
+		},
+		Extra: &AccountExtra{HSM: k},
+	}, nil
+}
@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccount_HSM(t *testing.T) {
+	p := NewMemoryKeyProvider()
+	k, err := p.Generate("token")
+	require.NoError(t, err)
+
+	acc, err := NewAccountFromHSM(p, k)
+	require.NoError(t, err)
+	require.True(t, acc.IsHSM())
+	require.Equal(t, "", acc.EncryptedWIF)
+	require.Nil(t, acc.PrivateKey())
+
+	tx := transaction.NewContractTX()
+	tx.AddOutput(&transaction.Output{
+		AssetID:    util.Uint256{1, 2, 3},
+		Amount:     1,
+		ScriptHash: util.Uint160{4, 5, 6},
+	})
+	require.NoError(t, acc.SignTx(tx))
+	require.Len(t, tx.Scripts, 1)
+
+	v := vm.New()
+	v.SetCheckedHash(tx.VerificationHash().BytesBE())
+	v.LoadScript(tx.Scripts[0].VerificationScript)
+	v.LoadScript(tx.Scripts[0].InvocationScript)
+	require.NoError(t, v.Run())
+	require.Equal(t, 1, v.Estack().Len())
+	require.Equal(t, true, v.Estack().Pop().Bool())
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(acc)
+		require.NoError(t, err)
+		actual := new(Account)
+		require.NoError(t, json.Unmarshal(data, actual))
+		require.Equal(t, k, actual.Extra.HSM)
+		require.Error(t, actual.Decrypt("pass"))
+		require.Error(t, actual.SignTx(tx))
+
+		_, err = p.Signer(&HSMKey{Token: "token", ID: "00"})
+		require.Error(t, err)
+		other, err := p.Generate("token")
+		require.NoError(t, err)
+		actual.Extra.HSM = other
+		require.Error(t, actual.Unlock(p))
+
+		actual.Extra.HSM = k
+		require.NoError(t, actual.Unlock(p))
+		require.NoError(t, actual.SignTx(tx))
+	})
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package wallet
+
+import "os"
+
+// lockFile doesn't lock anything on this platform, because files opened on
+// Windows can't be replaced, so JSON wallets only rely on atomic writes
+// there. Bolt wallets are locked by bbolt itself.
+func lockFile(path string) (*os.File, error) {
+	return nil, nil
+}
@@ -0,0 +1,46 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package wallet
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// lockFile takes an exclusive advisory lock on the file at path waiting for
+// lockTimeout if it's locked by someone else. As files are replaced on save,
+// the lock is retaken if the file was replaced while waiting for it. The
+// lock is held until the returned file is closed with unlockFile.
+func lockFile(path string) (*os.File, error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			locked, lerr := file.Stat()
+			current, cerr := os.Stat(path)
+			if lerr == nil && cerr == nil && os.SameFile(locked, current) {
+				return file, nil
+			}
+			file.Close()
+			if cerr != nil {
+				return nil, cerr
+			}
+			continue
+		}
+		file.Close()
+		if err != syscall.EWOULDBLOCK {
+			return nil, fmt.Errorf("can't lock wallet file: %v", err)
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrWalletLocked
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
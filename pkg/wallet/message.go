@@ -0,0 +1,176 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+)
+
+// MessageType is the ParameterContext type of messages.
+const MessageType = "Neo.Wallet.Message"
+
+// messageSaltSize is the size of random salt in bytes, salt is hex-encoded
+// in messages.
+const messageSaltSize = 16
+
+// messagePrefix and messageSuffix wrap signed message data so that it can't
+// be a valid transaction, it's the format NEO dAPI wallets use in
+// signMessage.
+var (
+	messagePrefix = []byte{0x01, 0x00, 0x01, 0xf0}
+	messageSuffix = []byte{0x00, 0x00}
+)
+
+// Message is a text message signed to prove address ownership.
+type Message struct {
+	// Text is the message itself.
+	Text string
+	// Salt is a hex-encoded random string prepended to the text before
+	// signing, it can be empty.
+	Salt string
+}
+
+// SignedMessage is a message signed by a single key, its JSON representation
+// is the same NEO dAPI wallets return from signMessage.
+type SignedMessage struct {
+	Message   string          `json:"message"`
+	Salt      string          `json:"salt"`
+	PublicKey *keys.PublicKey `json:"publicKey"`
+	Signature string          `json:"data"`
+}
+
+// NewMessage returns a message with the given text and random salt.
+func NewMessage(text string) (*Message, error) {
+	salt := make([]byte, messageSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &Message{
+		Text: text,
+		Salt: hex.EncodeToString(salt),
+	}, nil
+}
+
+// GetSignedPart returns the data that is signed for the message: salted
+// message text prefixed with its length and wrapped with fixed prefix and
+// suffix.
+func (m *Message) GetSignedPart() []byte {
+	w := io.NewBufBinWriter()
+	w.WriteBytes(messagePrefix)
+	w.WriteString(m.Salt + m.Text)
+	w.WriteBytes(messageSuffix)
+	return w.Bytes()
+}
+
+// Verify checks that sig is a valid signature of the message made with the
+// key corresponding to pub.
+func (m *Message) Verify(pub *keys.PublicKey, sig []byte) bool {
+	if len(sig) != 64 {
+		return false
+	}
+	digest := sha256.Sum256(m.GetSignedPart())
+	return pub.Verify(sig, digest[:])
+}
+
+// VerifyMultiSig checks that sigs contain enough valid signatures of the
+// message made with different keys of the given multisig verification
+// script.
+func (m *Message) VerifyMultiSig(script []byte, sigs [][]byte) error {
+	threshold, ok := vm.GetMultiSigThreshold(script)
+	if !ok {
+		return errors.New("not a multisig contract")
+	}
+	pubBytes, _ := vm.ParseMultiSigContract(script)
+	pubs := make([]*keys.PublicKey, len(pubBytes))
+	for i := range pubBytes {
+		pubs[i] = new(keys.PublicKey)
+		if err := pubs[i].DecodeBytes(pubBytes[i]); err != nil {
+			return fmt.Errorf("bad public key in the script: %v", err)
+		}
+	}
+	used := make([]bool, len(pubs))
+	var valid int
+	for _, sig := range sigs {
+		for i := range pubs {
+			if used[i] {
+				continue
+			}
+			if m.Verify(pubs[i], sig) {
+				used[i] = true
+				valid++
+				break
+			}
+		}
+	}
+	if valid < threshold {
+		return fmt.Errorf("only %d valid signatures out of %d needed", valid, threshold)
+	}
+	return nil
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (m *Message) EncodeBinary(w *io.BinWriter) {
+	w.WriteString(m.Salt)
+	w.WriteString(m.Text)
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (m *Message) DecodeBinary(r *io.BinReader) {
+	m.Salt = r.ReadString()
+	m.Text = r.ReadString()
+}
+
+// SignMessage signs the message with the account key, the account should be
+// unlocked. For multisig accounts it's a partial signature made with the
+// key of the account owner.
+func (a *Account) SignMessage(m *Message) (*SignedMessage, error) {
+	s := a.Signer()
+	if s == nil {
+		return nil, errors.New("account is not unlocked")
+	}
+	sig, err := s.Sign(m.GetSignedPart())
+	if err != nil {
+		return nil, err
+	}
+	return &SignedMessage{
+		Message:   m.Text,
+		Salt:      m.Salt,
+		PublicKey: s.PublicKey(),
+		Signature: hex.EncodeToString(sig),
+	}, nil
+}
+
+// Verify checks that the message signature is valid.
+func (sm *SignedMessage) Verify() error {
+	if sm.PublicKey == nil {
+		return errors.New("no public key")
+	}
+	sig, err := hex.DecodeString(sm.Signature)
+	if err != nil {
+		return fmt.Errorf("bad signature: %v", err)
+	}
+	m := &Message{Text: sm.Message, Salt: sm.Salt}
+	if !m.Verify(sm.PublicKey, sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// VerifyAddress checks that the message signature is valid and it's made by
+// the standard account with the given script hash.
+func (sm *SignedMessage) VerifyAddress(h util.Uint160) error {
+	if sm.PublicKey == nil {
+		return errors.New("no public key")
+	}
+	if !h.Equals(sm.PublicKey.GetScriptHash()) {
+		return errors.New("public key doesn't match the address")
+	}
+	return sm.Verify()
+}
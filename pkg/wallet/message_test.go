@@ -0,0 +1,103 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_GetSignedPart(t *testing.T) {
+	m := &Message{Text: "hello", Salt: "0102"}
+	expected, err := hex.DecodeString("010001f009" + hex.EncodeToString([]byte("0102hello")) + "0000")
+	require.NoError(t, err)
+	require.Equal(t, expected, m.GetSignedPart())
+}
+
+func TestMessage_EncodeDecode(t *testing.T) {
+	m, err := NewMessage("hello")
+	require.NoError(t, err)
+	require.Equal(t, 2*messageSaltSize, len(m.Salt))
+
+	w := io.NewBufBinWriter()
+	m.EncodeBinary(w.BinWriter)
+	require.NoError(t, w.Err)
+	actual := new(Message)
+	r := io.NewBinReaderFromBuf(w.Bytes())
+	actual.DecodeBinary(r)
+	require.NoError(t, r.Err)
+	require.Equal(t, m, actual)
+}
+
+func TestAccount_SignMessage(t *testing.T) {
+	acc, err := NewAccount()
+	require.NoError(t, err)
+	m, err := NewMessage("I own this address")
+	require.NoError(t, err)
+	sm, err := acc.SignMessage(m)
+	require.NoError(t, err)
+	require.NoError(t, sm.Verify())
+	require.NoError(t, sm.VerifyAddress(acc.Contract.ScriptHash()))
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(sm)
+		require.NoError(t, err)
+		actual := new(SignedMessage)
+		require.NoError(t, json.Unmarshal(data, actual))
+		require.Equal(t, sm, actual)
+	})
+	t.Run("wrong address", func(t *testing.T) {
+		other, err := NewAccount()
+		require.NoError(t, err)
+		require.Error(t, sm.VerifyAddress(other.Contract.ScriptHash()))
+	})
+	t.Run("changed message", func(t *testing.T) {
+		changed := *sm
+		changed.Message = "I own this address too"
+		require.Error(t, changed.Verify())
+		changed = *sm
+		changed.Salt = ""
+		require.Error(t, changed.Verify())
+	})
+	t.Run("bad signature", func(t *testing.T) {
+		changed := *sm
+		changed.Signature = "0102"
+		require.Error(t, changed.Verify())
+		changed.Signature = "xyz"
+		require.Error(t, changed.Verify())
+	})
+	t.Run("no key", func(t *testing.T) {
+		_, err := NewWatchOnlyAccount(acc.Contract.ScriptHash()).SignMessage(m)
+		require.Error(t, err)
+	})
+}
+
+func TestMessage_VerifyMultiSig(t *testing.T) {
+	privs := make([]*keys.PrivateKey, 3)
+	pubs := make(keys.PublicKeys, 3)
+	for i := range privs {
+		var err error
+		privs[i], err = keys.NewPrivateKey()
+		require.NoError(t, err)
+		pubs[i] = privs[i].PublicKey()
+	}
+	acc := newAccountFromPrivateKey(privs[0])
+	require.NoError(t, acc.ConvertMultisig(2, pubs))
+	script := acc.Contract.Script
+
+	m, err := NewMessage("hello")
+	require.NoError(t, err)
+	sig0 := privs[0].Sign(m.GetSignedPart())
+	sig2 := privs[2].Sign(m.GetSignedPart())
+
+	require.NoError(t, m.VerifyMultiSig(script, [][]byte{sig2, sig0}))
+	require.Error(t, m.VerifyMultiSig(script, [][]byte{sig0}))
+	require.Error(t, m.VerifyMultiSig(script, [][]byte{sig0, sig0}))
+	require.Error(t, m.VerifyMultiSig(pubs[0].GetVerificationScript(), [][]byte{sig0, sig2}))
+
+	other := &Message{Text: "bye", Salt: m.Salt}
+	require.Error(t, other.VerifyMultiSig(script, [][]byte{sig0, sig2}))
+}
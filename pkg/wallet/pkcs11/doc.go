@@ -0,0 +1,16 @@
+/*
+Package pkcs11 implements wallet.KeyProvider for hardware security modules
+accessible via PKCS#11 interface.
+
+Only secp256r1 ECDSA keys are supported. Each key is expected to have a pair of
+private and public key objects with the same CKA_ID, the private key is never
+extracted from the token.
+
+PKCS#11 support requires cgo, so it's only available when built with the
+pkcs11 tag:
+
+	go build -tags pkcs11 ./cli/main.go
+
+Without it New always returns ErrNotSupported.
+*/
+package pkcs11
@@ -0,0 +1,7 @@
+package pkcs11
+
+import "errors"
+
+// ErrNotSupported is returned when the binary is built without PKCS#11
+// support.
+var ErrNotSupported = errors.New("PKCS#11 support is not compiled in, rebuild with 'pkcs11' tag")
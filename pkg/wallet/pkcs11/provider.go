@@ -0,0 +1,213 @@
+//go:build pkcs11
+// +build pkcs11
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+)
+
+// secp256r1 is DER-encoded OID of secp256r1 curve (1.2.840.10045.3.1.7).
+var secp256r1 = []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}
+
+// Provider is a wallet.KeyProvider for a PKCS#11 module. It logs into tokens
+// with the same PIN and keeps one session per token.
+type Provider struct {
+	// lock protects sessions, PKCS#11 sessions can't be used concurrently.
+	lock     sync.Mutex
+	ctx      *p11.Ctx
+	pin      string
+	sessions map[string]p11.SessionHandle
+}
+
+// signer is a wallet.Signer for a key stored in a token.
+type signer struct {
+	p       *Provider
+	session p11.SessionHandle
+	key     p11.ObjectHandle
+	pub     *keys.PublicKey
+}
+
+// New loads PKCS#11 module and initializes it, pin is used to log into tokens.
+func New(module, pin string) (*Provider, error) {
+	ctx := p11.New(module)
+	if ctx == nil {
+		return nil, fmt.Errorf("can't load PKCS#11 module %s", module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("can't initialize PKCS#11 module: %v", err)
+	}
+	return &Provider{
+		ctx:      ctx,
+		pin:      pin,
+		sessions: make(map[string]p11.SessionHandle),
+	}, nil
+}
+
+// Signer implements wallet.KeyProvider interface.
+func (p *Provider) Signer(k *wallet.HSMKey) (wallet.Signer, error) {
+	id, err := hex.DecodeString(k.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key ID: %v", err)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	sh, err := p.getSession(k.Token)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := p.findObject(sh, p11.CKO_PRIVATE_KEY, id)
+	if err != nil {
+		return nil, fmt.Errorf("private key: %v", err)
+	}
+	pubObj, err := p.findObject(sh, p11.CKO_PUBLIC_KEY, id)
+	if err != nil {
+		return nil, fmt.Errorf("public key: %v", err)
+	}
+	attrs, err := p.ctx.GetAttributeValue(sh, pubObj, []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_EC_PARAMS, nil),
+		p11.NewAttribute(p11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(attrs[0].Value, secp256r1) {
+		return nil, errors.New("key is not on secp256r1 curve")
+	}
+	pub, err := decodeECPoint(attrs[1].Value)
+	if err != nil {
+		return nil, err
+	}
+	return &signer{
+		p:       p,
+		session: sh,
+		key:     priv,
+		pub:     pub,
+	}, nil
+}
+
+// Close closes all sessions and unloads the module.
+func (p *Provider) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for token, sh := range p.sessions {
+		_ = p.ctx.Logout(sh)
+		_ = p.ctx.CloseSession(sh)
+		delete(p.sessions, token)
+	}
+	err := p.ctx.Finalize()
+	p.ctx.Destroy()
+	return err
+}
+
+// getSession returns a logged in session for the token with the given label.
+func (p *Provider) getSession(token string) (p11.SessionHandle, error) {
+	if sh, ok := p.sessions[token]; ok {
+		return sh, nil
+	}
+	slots, err := p.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, slot := range slots {
+		info, err := p.ctx.GetTokenInfo(slot)
+		if err != nil || info.Label != token {
+			continue
+		}
+		sh, err := p.ctx.OpenSession(slot, p11.CKF_SERIAL_SESSION)
+		if err != nil {
+			return 0, err
+		}
+		err = p.ctx.Login(sh, p11.CKU_USER, p.pin)
+		if err != nil && err != p11.Error(p11.CKR_USER_ALREADY_LOGGED_IN) {
+			_ = p.ctx.CloseSession(sh)
+			return 0, fmt.Errorf("can't log into token: %v", err)
+		}
+		p.sessions[token] = sh
+		return sh, nil
+	}
+	return 0, fmt.Errorf("token %q not found", token)
+}
+
+// findObject finds EC key object of the given class with the given ID.
+func (p *Provider) findObject(sh p11.SessionHandle, class uint, id []byte) (p11.ObjectHandle, error) {
+	template := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, class),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, p11.CKK_EC),
+		p11.NewAttribute(p11.CKA_ID, id),
+	}
+	if err := p.ctx.FindObjectsInit(sh, template); err != nil {
+		return 0, err
+	}
+	objs, _, err := p.ctx.FindObjects(sh, 2)
+	if ferr := p.ctx.FindObjectsFinal(sh); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		return 0, err
+	}
+	switch len(objs) {
+	case 0:
+		return 0, errors.New("not found")
+	case 1:
+		return objs[0], nil
+	default:
+		return 0, errors.New("multiple objects found")
+	}
+}
+
+// decodeECPoint decodes CKA_EC_POINT value which is a DER-encoded octet
+// string with uncompressed point inside, some modules return the point
+// itself.
+func decodeECPoint(data []byte) (*keys.PublicKey, error) {
+	var point []byte
+	if rest, err := asn1.Unmarshal(data, &point); err != nil || len(rest) != 0 {
+		point = data
+	}
+	pub := new(keys.PublicKey)
+	if err := pub.DecodeBytes(point); err != nil {
+		return nil, fmt.Errorf("invalid EC point: %v", err)
+	}
+	return pub, nil
+}
+
+// PublicKey implements wallet.Signer interface.
+func (s *signer) PublicKey() *keys.PublicKey {
+	return s.pub
+}
+
+// Sign implements wallet.Signer interface. CKM_ECDSA mechanism returns r and s
+// concatenated, which is the format used by NEO.
+func (s *signer) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+
+	s.p.lock.Lock()
+	defer s.p.lock.Unlock()
+
+	err := s.p.ctx.SignInit(s.session, []*p11.Mechanism{p11.NewMechanism(p11.CKM_ECDSA, nil)}, s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.p.ctx.Sign(s.session, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 64 {
+		return nil, fmt.Errorf("unexpected signature length %d", len(sig))
+	}
+	return sig, nil
+}
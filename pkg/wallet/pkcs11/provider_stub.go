@@ -0,0 +1,26 @@
+//go:build !pkcs11
+// +build !pkcs11
+
+package pkcs11
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+)
+
+// Provider is a PKCS#11 key provider, it's not available in this build.
+type Provider struct{}
+
+// New always returns ErrNotSupported in this build.
+func New(module, pin string) (*Provider, error) {
+	return nil, ErrNotSupported
+}
+
+// Signer implements wallet.KeyProvider interface.
+func (p *Provider) Signer(k *wallet.HSMKey) (wallet.Signer, error) {
+	return nil, ErrNotSupported
+}
+
+// Close does nothing in this build.
+func (p *Provider) Close() error {
+	return nil
+}
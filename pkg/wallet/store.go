@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+)
+
+// Store is a persistent storage of wallet data.
+type Store interface {
+	// Load reads wallet data into w.
+	Load(w *Wallet) error
+	// Save writes w data into the storage.
+	Save(w *Wallet) error
+	// Close releases resources (like file locks) held by the storage.
+	Close() error
+}
+
+// Format is a wallet file format.
+type Format byte
+
+const (
+	// JSONFormat is the NEP-6 JSON file format, the whole file is rewritten
+	// on every save.
+	JSONFormat Format = iota
+	// BoltFormat is the bbolt database format, only accounts changed since
+	// the previous save are written, so it suits wallets with a lot of
+	// accounts.
+	BoltFormat
+)
+
+// BoltExtension is the wallet file extension that makes it use BoltFormat.
+const BoltExtension = ".db"
+
+var (
+	// lockTimeout is the time to wait for the wallet file lock held by
+	// another process.
+	lockTimeout = 5 * time.Second
+	// lockRetryInterval is the interval between attempts to lock the
+	// wallet file.
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// ErrWalletLocked is returned when wallet file is locked by another process
+// for longer than lockTimeout.
+var ErrWalletLocked = errors.New("wallet file is locked by another process")
+
+// GetFormat returns the format of the wallet at the given path based on its
+// extension.
+func GetFormat(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), BoltExtension) {
+		return BoltFormat
+	}
+	return JSONFormat
+}
+
+// openStore opens the wallet storage at the given path, existing wallet is
+// reset if create is true.
+func openStore(path string, create bool) (Store, error) {
+	if GetFormat(path) == BoltFormat {
+		return openBoltStore(path, create)
+	}
+	return openJSONStore(path, create)
+}
+
+// validateScrypt checks that scrypt parameters can be used for key
+// derivation.
+func validateScrypt(p keys.ScryptParams) error {
+	if p.N <= 1 || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("invalid scrypt N parameter %d: must be a power of 2 greater than 1", p.N)
+	}
+	if p.R <= 0 || p.P <= 0 {
+		return fmt.Errorf("invalid scrypt r/p parameters %d/%d: must be positive", p.R, p.P)
+	}
+	if uint64(p.R)*uint64(p.P) >= 1<<30 {
+		return fmt.Errorf("invalid scrypt r/p parameters %d/%d: r*p must be less than 2^30", p.R, p.P)
+	}
+	return nil
+}
+
+// unlockFile releases the lock taken by lockFile.
+func unlockFile(f *os.File) error {
+	if f == nil {
+		return nil
+	}
+	return f.Close()
+}
+
+// streamStore keeps wallet as JSON in an arbitrary io.ReadWriter, it doesn't
+// lock anything and writes are not atomic.
+type streamStore struct {
+	rw io.ReadWriter
+}
+
+// Load implements the Store interface.
+func (s *streamStore) Load(w *Wallet) error {
+	return json.NewDecoder(s.rw).Decode(w)
+}
+
+// Save implements the Store interface.
+func (s *streamStore) Save(w *Wallet) error {
+	if sk, ok := s.rw.(io.Seeker); ok {
+		if _, err := sk.Seek(0, 0); err != nil {
+			return err
+		}
+	}
+	return json.NewEncoder(s.rw).Encode(w)
+}
+
+// Close implements the Store interface.
+func (s *streamStore) Close() error {
+	if c, ok := s.rw.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.etcd.io/bbolt"
+)
+
+var (
+	boltMetaBucket     = []byte("meta")
+	boltAccountsBucket = []byte("accounts")
+
+	boltVersionKey = []byte("version")
+	boltScryptKey  = []byte("scrypt")
+	boltExtraKey   = []byte("extra")
+)
+
+// boltStore keeps wallet in a bbolt database. Every account is stored under
+// its own sequential key in the order accounts were added, so that saving
+// the wallet only writes accounts changed since the last save. The database
+// file is locked by bbolt while the store is open.
+type boltStore struct {
+	db *bbolt.DB
+	// accounts are the last saved (or loaded) accounts with their keys and
+	// serialized data.
+	accounts map[*Account]boltAccount
+}
+
+// boltAccount is an account stored in the database.
+type boltAccount struct {
+	key  []byte
+	data []byte
+}
+
+func openBoltStore(path string, create bool) (*boltStore, error) {
+	if !create {
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+	}
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		if err == bbolt.ErrTimeout {
+			return nil, ErrWalletLocked
+		}
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{boltMetaBucket, boltAccountsBucket} {
+			if create {
+				if err := tx.DeleteBucket(name); err != nil && err != bbolt.ErrBucketNotFound {
+					return err
+				}
+			}
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("could not create %s bucket: %v", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{
+		db:       db,
+		accounts: make(map[*Account]boltAccount),
+	}, nil
+}
+
+// Load implements the Store interface.
+func (s *boltStore) Load(w *Wallet) error {
+	accounts := make(map[*Account]boltAccount)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		meta := tx.Bucket(boltMetaBucket)
+		version := meta.Get(boltVersionKey)
+		if version == nil {
+			return errors.New("wallet database is empty")
+		}
+		w.Version = string(version)
+		if err := json.Unmarshal(meta.Get(boltScryptKey), &w.Scrypt); err != nil {
+			return fmt.Errorf("invalid scrypt parameters: %v", err)
+		}
+		if err := json.Unmarshal(meta.Get(boltExtraKey), &w.Extra); err != nil {
+			return fmt.Errorf("invalid extra data: %v", err)
+		}
+		w.Accounts = []*Account{}
+		return tx.Bucket(boltAccountsBucket).ForEach(func(k, v []byte) error {
+			acc := new(Account)
+			if err := json.Unmarshal(v, acc); err != nil {
+				return fmt.Errorf("invalid account %x: %v", k, err)
+			}
+			w.Accounts = append(w.Accounts, acc)
+			// Slices returned by bbolt are only valid inside transaction.
+			accounts[acc] = boltAccount{
+				key:  append([]byte{}, k...),
+				data: append([]byte{}, v...),
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.accounts = accounts
+	return nil
+}
+
+// Save implements the Store interface. Accounts are rewritten from scratch
+// only if their order has changed in some other way than appending new ones.
+func (s *boltStore) Save(w *Wallet) error {
+	scrypt, err := json.Marshal(w.Scrypt)
+	if err != nil {
+		return err
+	}
+	extra, err := json.Marshal(w.Extra)
+	if err != nil {
+		return err
+	}
+	saved := make(map[*Account]boltAccount, len(w.Accounts))
+	err = s.db.Update(func(tx *bbolt.Tx) error {
+		meta := tx.Bucket(boltMetaBucket)
+		if err := meta.Put(boltVersionKey, []byte(w.Version)); err != nil {
+			return err
+		}
+		if err := meta.Put(boltScryptKey, scrypt); err != nil {
+			return err
+		}
+		if err := meta.Put(boltExtraKey, extra); err != nil {
+			return err
+		}
+
+		b := tx.Bucket(boltAccountsBucket)
+		known := s.accounts
+		if !s.inOrder(w.Accounts) {
+			if err := tx.DeleteBucket(boltAccountsBucket); err != nil {
+				return err
+			}
+			if b, err = tx.CreateBucket(boltAccountsBucket); err != nil {
+				return err
+			}
+			known = nil
+		}
+		for _, acc := range w.Accounts {
+			data, err := json.Marshal(acc)
+			if err != nil {
+				return err
+			}
+			ba, ok := known[acc]
+			if !ok {
+				seq, err := b.NextSequence()
+				if err != nil {
+					return err
+				}
+				ba.key = make([]byte, 8)
+				binary.BigEndian.PutUint64(ba.key, seq)
+			}
+			if !ok || !bytes.Equal(ba.data, data) {
+				if err := b.Put(ba.key, data); err != nil {
+					return err
+				}
+				ba.data = data
+			}
+			saved[acc] = ba
+		}
+		for acc, ba := range known {
+			if _, ok := saved[acc]; !ok {
+				if err := b.Delete(ba.key); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.accounts = saved
+	return nil
+}
+
+// inOrder checks that already stored accounts are in the same order in
+// accounts and new ones are only appended to them, so that new sequential
+// keys keep the order.
+func (s *boltStore) inOrder(accounts []*Account) bool {
+	var (
+		last    []byte
+		seenNew bool
+	)
+	for _, acc := range accounts {
+		ba, ok := s.accounts[acc]
+		if !ok {
+			seenNew = true
+			continue
+		}
+		if seenNew || bytes.Compare(ba.key, last) <= 0 {
+			return false
+		}
+		last = ba.key
+	}
+	return true
+}
+
+// Close implements the Store interface.
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
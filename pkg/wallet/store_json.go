@@ -0,0 +1,115 @@
+package wallet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// jsonStore keeps wallet in a NEP-6 JSON file. Every save writes the whole
+// wallet into a temporary file that then replaces the original one, so the
+// wallet file is never left half-written. The file is locked while the store
+// is open, the lock is moved to the new file on save.
+type jsonStore struct {
+	path string
+	// lock is the wallet file locked by lockFile.
+	lock *os.File
+}
+
+func openJSONStore(path string, create bool) (*jsonStore, error) {
+	if create {
+		// Existing file is only truncated after it's locked.
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0666)
+		if err != nil {
+			return nil, err
+		}
+		if err := file.Close(); err != nil {
+			return nil, err
+		}
+	}
+	lock, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if create {
+		if err := os.Truncate(path, 0); err != nil {
+			unlockFile(lock)
+			return nil, err
+		}
+	}
+	return &jsonStore{path: path, lock: lock}, nil
+}
+
+// Load implements the Store interface.
+func (s *jsonStore) Load(w *Wallet) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewDecoder(file).Decode(w)
+}
+
+// Save implements the Store interface.
+func (s *jsonStore) Save(w *Wallet) error {
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	tmp, err := writeTempFile(s.path, append(data, '\n'))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp) // Fails after successful rename.
+
+	// The new file is locked before it replaces the old one, so that the
+	// wallet is never unlocked while the store is open.
+	lock, err := lockFile(tmp)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		unlockFile(lock)
+		return err
+	}
+	unlockFile(s.lock)
+	s.lock = lock
+	return nil
+}
+
+// Close implements the Store interface.
+func (s *jsonStore) Close() error {
+	err := unlockFile(s.lock)
+	s.lock = nil
+	return err
+}
+
+// writeTempFile writes data into a temporary file in the same directory as
+// path and returns its name, so that it can be renamed to path atomically.
+// Permissions of the existing file at path are preserved.
+func writeTempFile(path string, data []byte) (string, error) {
+	perm := os.FileMode(0600)
+	if fi, err := os.Stat(path); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
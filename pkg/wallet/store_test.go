@@ -0,0 +1,234 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func newTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "wallet")
+	require.NoError(t, err)
+	return dir
+}
+
+func TestGetFormat(t *testing.T) {
+	require.Equal(t, JSONFormat, GetFormat("wallet.json"))
+	require.Equal(t, JSONFormat, GetFormat("wallet"))
+	require.Equal(t, BoltFormat, GetFormat("wallet.db"))
+	require.Equal(t, BoltFormat, GetFormat("/tmp/wallet.DB"))
+}
+
+func TestValidateScrypt(t *testing.T) {
+	require.NoError(t, validateScrypt(keys.NEP2ScryptParams()))
+	require.NoError(t, validateScrypt(keys.ScryptParams{N: 2, R: 1, P: 1}))
+	require.Error(t, validateScrypt(keys.ScryptParams{N: 0, R: 8, P: 8}))
+	require.Error(t, validateScrypt(keys.ScryptParams{N: 1, R: 8, P: 8}))
+	require.Error(t, validateScrypt(keys.ScryptParams{N: 1000, R: 8, P: 8}))
+	require.Error(t, validateScrypt(keys.ScryptParams{N: 16384, R: 0, P: 8}))
+	require.Error(t, validateScrypt(keys.ScryptParams{N: 16384, R: 8, P: -1}))
+	require.Error(t, validateScrypt(keys.ScryptParams{N: 16384, R: 1 << 15, P: 1 << 15}))
+}
+
+func TestNewWalletFromFile_InvalidScrypt(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "wallet.json")
+	w, err := NewWallet(path)
+	require.NoError(t, err)
+	w.Scrypt.N = 1000
+	require.NoError(t, w.Save())
+	w.Close()
+
+	_, err = NewWalletFromFile(path)
+	require.Error(t, err)
+}
+
+func TestJSONStore_AtomicSave(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "wallet.json")
+	w, err := NewWallet(path)
+	require.NoError(t, err)
+	acc, err := NewAccount()
+	require.NoError(t, err)
+	w.AddAccount(acc)
+	require.NoError(t, w.Save())
+	require.NoError(t, os.Chmod(path, 0640))
+
+	// Shorter wallet must not leave old data in the file.
+	require.NoError(t, w.RemoveAccount(acc.Address))
+	require.NoError(t, w.Save())
+	w.Close()
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(files))
+
+	w2, err := NewWalletFromFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(w2.Accounts))
+	w2.Close()
+}
+
+func newTestAccount(t *testing.T, label string) *Account {
+	acc, err := NewAccount()
+	require.NoError(t, err)
+	acc.Label = label
+	return acc
+}
+
+func checkBoltWallet(t *testing.T, path string, expected *Wallet) {
+	w, err := NewWalletFromFile(path)
+	require.NoError(t, err)
+	defer w.Close()
+	require.Equal(t, expected.Version, w.Version)
+	require.Equal(t, expected.Scrypt, w.Scrypt)
+	require.Equal(t, expected.Extra, w.Extra)
+	require.Equal(t, len(expected.Accounts), len(w.Accounts))
+	for i := range expected.Accounts {
+		require.Equal(t, expected.Accounts[i].Address, w.Accounts[i].Address)
+		require.Equal(t, expected.Accounts[i].Label, w.Accounts[i].Label)
+		require.Equal(t, expected.Accounts[i].Contract, w.Accounts[i].Contract)
+	}
+}
+
+func countBoltAccounts(t *testing.T, path string) int {
+	db, err := bbolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+	defer db.Close()
+	var n int
+	require.NoError(t, db.View(func(tx *bbolt.Tx) error {
+		n = tx.Bucket(boltAccountsBucket).Stats().KeyN
+		return nil
+	}))
+	return n
+}
+
+func TestJSONStore_Lock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("JSON wallets are not locked on Windows")
+	}
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 100 * time.Millisecond
+
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "wallet.json")
+	w, err := NewWallet(path)
+	require.NoError(t, err)
+	require.NoError(t, w.Save())
+
+	_, err = NewWalletFromFile(path)
+	require.Equal(t, ErrWalletLocked, err)
+
+	// Lock is kept after the file is replaced on save.
+	require.NoError(t, w.Save())
+	_, err = NewWalletFromFile(path)
+	require.Equal(t, ErrWalletLocked, err)
+	_, err = NewWallet(path)
+	require.Equal(t, ErrWalletLocked, err)
+
+	w.Close()
+	w, err = NewWalletFromFile(path)
+	require.NoError(t, err)
+	w.Close()
+}
+
+func TestBoltStore(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "wallet.db")
+
+	_, err := NewWalletFromFile(path)
+	require.Error(t, err)
+
+	w, err := NewWallet(path)
+	require.NoError(t, err)
+	w.AddToken(NewToken(util.Uint160{1, 2, 3}, "Rubl", "RUB", 2))
+	for _, l := range []string{"a", "b", "c"} {
+		w.AddAccount(newTestAccount(t, l))
+	}
+	require.NoError(t, w.Save())
+	w.Close()
+	checkBoltWallet(t, path, w)
+
+	w, err = NewWalletFromFile(path)
+	require.NoError(t, err)
+
+	t.Run("modify, remove and append", func(t *testing.T) {
+		w.Accounts[0].Label = "changed"
+		require.NoError(t, w.RemoveAccount(w.Accounts[1].Address))
+		w.AddAccount(newTestAccount(t, "d"))
+		require.NoError(t, w.Save())
+		require.Equal(t, 3, len(w.store.(*boltStore).accounts))
+		w.Close()
+		checkBoltWallet(t, path, w)
+		require.Equal(t, 3, countBoltAccounts(t, path))
+
+		w, err = NewWalletFromFile(path)
+		require.NoError(t, err)
+	})
+	t.Run("reorder", func(t *testing.T) {
+		w.Accounts[0], w.Accounts[2] = w.Accounts[2], w.Accounts[0]
+		require.NoError(t, w.Save())
+		w.Close()
+		checkBoltWallet(t, path, w)
+		require.Equal(t, 3, countBoltAccounts(t, path))
+	})
+	t.Run("recreate", func(t *testing.T) {
+		w, err := NewWallet(path)
+		require.NoError(t, err)
+		require.NoError(t, w.Save())
+		w.Close()
+		checkBoltWallet(t, path, w)
+	})
+}
+
+func TestWallet_Convert(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	w, err := NewWalletFromFile("testdata/wallet1.json")
+	require.NoError(t, err)
+	defer w.Close()
+	w.AddToken(NewToken(util.Uint160{1, 2, 3}, "Rubl", "RUB", 2))
+
+	dbPath := filepath.Join(dir, "wallet.db")
+	bw, err := w.Convert(dbPath)
+	require.NoError(t, err)
+	bw.Close()
+	checkBoltWallet(t, dbPath, w)
+
+	_, err = w.Convert(dbPath)
+	require.Error(t, err)
+
+	bw, err = NewWalletFromFile(dbPath)
+	require.NoError(t, err)
+	defer bw.Close()
+	jsonPath := filepath.Join(dir, "wallet.json")
+	jw, err := bw.Convert(jsonPath)
+	require.NoError(t, err)
+	jw.Close()
+
+	jw, err = NewWalletFromFile(jsonPath)
+	require.NoError(t, err)
+	defer jw.Close()
+	require.Equal(t, w.Scrypt, jw.Scrypt)
+	require.Equal(t, w.Extra, jw.Extra)
+	require.Equal(t, len(w.Accounts), len(jw.Accounts))
+	for i := range w.Accounts {
+		require.Equal(t, w.Accounts[i].Address, jw.Accounts[i].Address)
+		require.Equal(t, w.Accounts[i].EncryptedWIF, jw.Accounts[i].EncryptedWIF)
+	}
+}
@@ -3,6 +3,7 @@ package wallet
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 
@@ -34,36 +35,46 @@ type Wallet struct {
 	// Path where the wallet file is located..
 	path string
 
-	// ReadWriter for reading and writing wallet data.
-	rw io.ReadWriter
+	// Store for reading and writing wallet data.
+	store Store
 }
 
-// Extra stores imported token contracts.
+// Extra stores imported token contracts and HD wallet key.
 type Extra struct {
 	// Tokens is a list of imported token contracts.
 	Tokens []*Token
+	// HD is a key used to derive accounts, it's nil for wallets not
+	// created from mnemonic.
+	HD *HDKey `json:",omitempty"`
 }
 
-// NewWallet creates a new NEO wallet at the given location.
+// NewWallet creates a new NEO wallet at the given location. Wallet format
+// depends on the location extension, see GetFormat.
 func NewWallet(location string) (*Wallet, error) {
-	file, err := os.Create(location)
+	store, err := openStore(location, true)
 	if err != nil {
 		return nil, err
 	}
-	return newWallet(file), nil
+	return newWalletWithStore(store, location), nil
 }
 
-// NewWalletFromFile creates a Wallet from the given wallet file path
+// NewWalletFromFile creates a Wallet from the given wallet file path. Wallet
+// format depends on the path extension, see GetFormat.
 func NewWalletFromFile(path string) (*Wallet, error) {
-	file, err := os.OpenFile(path, os.O_RDWR, os.ModeAppend)
+	store, err := openStore(path, false)
 	if err != nil {
 		return nil, err
 	}
 	wall := &Wallet{
-		rw:   file,
-		path: file.Name(),
+		store: store,
+		path:  path,
 	}
-	if err := json.NewDecoder(file).Decode(wall); err != nil {
+	if err := store.Load(wall); err != nil {
+		store.Close()
+		return nil, err
+	}
+	if err := validateScrypt(wall.Scrypt); err != nil {
+		store.Close()
 		return nil, err
 	}
 	return wall, nil
@@ -74,26 +85,69 @@ func newWallet(rw io.ReadWriter) *Wallet {
 	if f, ok := rw.(*os.File); ok {
 		path = f.Name()
 	}
+	return newWalletWithStore(&streamStore{rw: rw}, path)
+}
+
+func newWalletWithStore(store Store, path string) *Wallet {
 	return &Wallet{
 		Version:  walletVersion,
 		Accounts: []*Account{},
 		Scrypt:   keys.NEP2ScryptParams(),
-		rw:       rw,
+		store:    store,
 		path:     path,
 	}
 }
 
+// Convert creates a new wallet at the given path with the same data as w. The
+// new wallet format depends on the path extension (see GetFormat), so it can
+// be used to migrate wallets between formats. The path must not exist, w is
+// not changed.
+func (w *Wallet) Convert(path string) (*Wallet, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("file %s already exists", path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	// Accounts are copied via JSON to not share them between wallets.
+	data, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+	nw, err := NewWallet(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, nw); err != nil {
+		nw.Close()
+		return nil, err
+	}
+	if err := nw.Save(); err != nil {
+		nw.Close()
+		return nil, err
+	}
+	return nw, nil
+}
+
 // CreateAccount generates a new account for the end user and encrypts
-// the private key with the given passphrase.
+// the private key with the given passphrase. For HD wallets the next
+// account is derived from the wallet key.
 func (w *Wallet) CreateAccount(name, passphrase string) error {
-	acc, err := NewAccount()
+	var (
+		acc *Account
+		err error
+	)
+	if w.IsHD() {
+		acc, err = w.DeriveAccount(w.NextHDIndex(), passphrase)
+	} else {
+		acc, err = NewAccount()
+		if err == nil {
+			err = acc.EncryptWithParams(passphrase, w.Scrypt)
+		}
+	}
 	if err != nil {
 		return err
 	}
 	acc.Label = name
-	if err := acc.Encrypt(passphrase); err != nil {
-		return err
-	}
 	w.AddAccount(acc)
 	return w.Save()
 }
@@ -138,16 +192,11 @@ func (w *Wallet) Path() string {
 	return w.path
 }
 
-// Save saves the wallet data. It's the internal io.ReadWriter
+// Save saves the wallet data. It's the internal Store
 // that is responsible for saving the data. This can
-// be a buffer, file, etc..
+// be a JSON file, database, buffer, etc..
 func (w *Wallet) Save() error {
-	if s, ok := w.rw.(io.Seeker); ok {
-		if _, err := s.Seek(0, 0); err != nil {
-			return err
-		}
-	}
-	return json.NewEncoder(w.rw).Encode(w)
+	return w.store.Save(w)
 }
 
 // JSON outputs a pretty JSON representation of the wallet.
@@ -155,10 +204,10 @@ func (w *Wallet) JSON() ([]byte, error) {
 	return json.MarshalIndent(w, " ", "	")
 }
 
-// Close closes the internal rw if its an io.ReadCloser.
+// Close closes the internal Store releasing wallet file locks.
 func (w *Wallet) Close() {
-	if rc, ok := w.rw.(io.ReadCloser); ok {
-		rc.Close()
+	if w.store != nil {
+		w.store.Close()
 	}
 }
 
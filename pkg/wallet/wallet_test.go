@@ -92,6 +92,7 @@ func TestSave(t *testing.T) {
 	defer removeWallet(t, file.Name())
 	errForSave := wallet.Save()
 	require.NoError(t, errForSave)
+	wallet.Close()
 
 	openedWallet, err := NewWalletFromFile(wallet.path)
 	require.NoError(t, err)
@@ -100,9 +101,11 @@ func TestSave(t *testing.T) {
 	t.Run("change and rewrite", func(t *testing.T) {
 		err := openedWallet.CreateAccount("test", "pass")
 		require.NoError(t, err)
+		openedWallet.Close()
 
 		w2, err := NewWalletFromFile(openedWallet.path)
 		require.NoError(t, err)
+		defer w2.Close()
 		require.Equal(t, 2, len(w2.Accounts))
 		require.NoError(t, w2.Accounts[1].Decrypt("pass"))
 		require.Equal(t, openedWallet.Accounts, w2.Accounts)
@@ -179,6 +182,7 @@ func TestWallet_GetAccount(t *testing.T) {
 func TestWalletGetChangeAddress(t *testing.T) {
 	w1, err := NewWalletFromFile("testdata/wallet1.json")
 	require.NoError(t, err)
+	defer w1.Close()
 	sh := w1.GetChangeAddress()
 	// No default address, the first one is used.
 	expected, err := address.StringToUint160("AKkkumHbBipZ46UMZJoFynJMXzSRnBvKcs")
@@ -186,6 +190,7 @@ func TestWalletGetChangeAddress(t *testing.T) {
 	require.Equal(t, expected, sh)
 	w2, err := NewWalletFromFile("testdata/wallet2.json")
 	require.NoError(t, err)
+	defer w2.Close()
 	sh = w2.GetChangeAddress()
 	// Default address.
 	expected, err = address.StringToUint160("AWLYWXB8C9Lt1nHdDZJnC5cpYJjgRDLk17")